@@ -7,9 +7,14 @@ import (
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/api"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/app"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/clientip"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/purge"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/requestid"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/routes"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/upload"
 )
 
 func main() {
@@ -22,11 +27,31 @@ func main() {
 	log := app.Logger()
 	defer log.Sync()
 
+	// TRUSTED_PROXIES lists the proxies whose X-Forwarded-For is read to
+	// find the address of the clients
+	if err := clientip.SetTrustedProxies(os.Getenv("TRUSTED_PROXIES")); err != nil {
+		log.Fatal(err)
+	}
+
 	if err := models.Connect(app); err != nil {
 		log.Fatalf("db connection failed: %s", err)
 	}
 	defer models.DB.Close()
 
+	// deleted resources are purged once their restore window is over
+	purge.New(app).Start()
+
+	// resources added before versions were stored get their first version
+	go func() {
+		synced, err := upload.New(app).SyncMissingVersions()
+		if err != nil {
+			log.Error(err)
+		}
+		if synced > 0 {
+			log.Infof("stored the first version of %d resources", synced)
+		}
+	}()
+
 	router := mux.NewRouter()
 	// models.AddResourcesFromCatalog("tektoncd", "catalog")
 	routes.Register(router, app)
@@ -34,8 +59,10 @@ func main() {
 	cors := handlers.CORS(
 		handlers.AllowedOrigins([]string{"*"}),
 		handlers.AllowedHeaders([]string{
-			"X-Requested-With", "Content-Type", "Authorization",
+			"X-Requested-With", "Content-Type", "Authorization", requestid.Header,
 		}),
+		// legacy routes link to their successor with Deprecation and Link
+		handlers.ExposedHeaders([]string{requestid.Header, "Deprecation", "Link", api.TotalCountHeader}),
 		handlers.AllowedMethods([]string{
 			"GET", "POST", "PUT", "HEAD", "OPTIONS", "DELETE",
 		}),
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/client"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/diagnostic"
+)
+
+// kinds are the types of the resources, as given to get and install
+var kinds = map[string]bool{"task": true, "pipeline": true}
+
+func checkKind(kind string) error {
+	if kind != "" && !kinds[kind] {
+		return fmt.Errorf("invalid type %q, expected task or pipeline", kind)
+	}
+	return nil
+}
+
+// resolve finds a resource by its id or its name, kind restricts the names
+// to tasks or pipelines when it is set
+func (c *cli) resolve(ctx context.Context, ref, kind string) (*client.Resource, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		resource, err := c.client.GetResource(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if kind != "" && resource.Type != kind {
+			return nil, fmt.Errorf("resource %d is a %s, not a %s", id, resource.Type, kind)
+		}
+		return resource, nil
+	}
+	resources, err := c.client.AllResources(ctx, client.ListOptions{Type: kind})
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, resource := range resources {
+		if resource.Name == ref {
+			ids = append(ids, strconv.Itoa(resource.ID))
+		}
+	}
+	switch len(ids) {
+	case 0:
+		if kind == "" {
+			kind = "resource"
+		}
+		return nil, fmt.Errorf("no %s named %q", kind, ref)
+	case 1:
+		id, _ := strconv.Atoi(ids[0])
+		return c.client.GetResource(ctx, id)
+	}
+	return nil, fmt.Errorf("several resources are named %q, use one of the ids %s", ref, strings.Join(ids, ", "))
+}
+
+// manifest returns the YAML of a version of a resource, the latest one when
+// version is empty
+func (c *cli) manifest(ctx context.Context, resource *client.Resource, version string) (string, error) {
+	if version == "" {
+		versions, err := c.client.ResourceVersions(ctx, resource.ID)
+		if err != nil {
+			return "", err
+		}
+		if len(versions) == 0 {
+			// resources uploaded before versions were recorded
+			return c.client.ResourceYAML(ctx, resource.ID)
+		}
+		latest := versions[len(versions)-1]
+		version = latest.Version
+		if version == "" {
+			version = strconv.Itoa(latest.ID)
+		}
+	}
+	return c.client.VersionYAML(ctx, resource.ID, version)
+}
+
+// warn tells about a deprecated resource on stderr, so that the manifests
+// on stdout can still be applied
+func (c *cli) warn(resource *client.Resource, warning string) {
+	if warning == "" && resource.Deprecation != nil {
+		warning = fmt.Sprintf("%s is deprecated: %s", resource.Name, resource.Deprecation.Reason)
+	}
+	if warning != "" {
+		fmt.Fprintln(c.stderr, "Warning:", warning)
+	}
+}
+
+func searchCommand(fs *flag.FlagSet) command {
+	var params, workspaces, results, tags stringsFlag
+	kind := fs.String("type", "", "task or pipeline")
+	fs.Var(&tags, "tag", "tag of the resources, any of them matches")
+	fs.Var(&params, "param", "param the resources have")
+	fs.Var(&workspaces, "workspace", "workspace the resources have")
+	fs.Var(&results, "result", "result the resources have")
+	deprecated := fs.Bool("deprecated", false, "include the deprecated resources in an interface search")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if err := checkKind(*kind); err != nil {
+			return err
+		}
+		var (
+			resources []client.Resource
+			err       error
+		)
+		if len(params)+len(workspaces)+len(results) > 0 {
+			resources, err = c.client.SearchAllResources(ctx, client.SearchOptions{
+				Params: params, Workspaces: workspaces, Results: results, Type: *kind, Deprecated: *deprecated,
+			})
+		} else {
+			resources, err = c.client.AllResources(ctx, client.ListOptions{Type: *kind, Tags: tags})
+		}
+		if err != nil {
+			return err
+		}
+		// the text matches the names and descriptions
+		text := strings.ToLower(strings.Join(args, " "))
+		matches := []client.Resource{}
+		for _, r := range resources {
+			if strings.Contains(strings.ToLower(r.Name), text) || strings.Contains(strings.ToLower(r.Description), text) {
+				matches = append(matches, r)
+			}
+		}
+		return c.print(matches, func(w *tabwriter.Writer) {
+			row(w, "ID", "NAME", "TYPE", "RATING", "DOWNLOADS", "DESCRIPTION")
+			for _, r := range matches {
+				name := r.Name
+				if r.Deprecated {
+					name += " (deprecated)"
+				}
+				row(w, r.ID, name, r.Type, r.Rating, r.Downloads, truncate(r.Description, 60))
+			}
+		})
+	}
+}
+
+// info is a resource along with its versions
+type info struct {
+	*client.Resource
+	Versions []client.Version `json:"versions"`
+}
+
+func infoCommand(fs *flag.FlagSet) command {
+	kind := fs.String("type", "", "task or pipeline")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return errors.New("usage: hub info <name|id>")
+		}
+		if err := checkKind(*kind); err != nil {
+			return err
+		}
+		resource, err := c.resolve(ctx, args[0], *kind)
+		if err != nil {
+			return err
+		}
+		versions, err := c.client.ResourceVersions(ctx, resource.ID)
+		if err != nil {
+			return err
+		}
+		return c.print(info{resource, versions}, func(w *tabwriter.Writer) {
+			row(w, "Name:", resource.Name)
+			row(w, "ID:", resource.ID)
+			row(w, "Type:", resource.Type)
+			row(w, "Description:", truncate(resource.Description, 100))
+			row(w, "Tags:", strings.Join(resource.Tags, ", "))
+			row(w, "Rating:", resource.Rating)
+			row(w, "Downloads:", resource.Downloads)
+			row(w, "Verified:", resource.Verified)
+			row(w, "GitHub:", resource.Github)
+			if resource.Deprecation != nil {
+				row(w, "Deprecated:", resource.Deprecation.Reason)
+			}
+			names := []string{}
+			for _, v := range versions {
+				if v.Version != "" {
+					names = append(names, v.Version)
+				}
+			}
+			row(w, "Versions:", strings.Join(names, ", "))
+			if i := resource.Interface; i != nil {
+				for _, p := range i.Params {
+					row(w, "Param:", p.Name, truncate(p.Description, 60))
+				}
+				for _, ws := range i.Workspaces {
+					row(w, "Workspace:", ws.Name, truncate(ws.Description, 60))
+				}
+				for _, r := range i.Results {
+					row(w, "Result:", r.Name, truncate(r.Description, 60))
+				}
+			}
+		})
+	}
+}
+
+func getCommand(fs *flag.FlagSet) command {
+	version := fs.String("version", "", "version of the resource, the latest by default")
+	withDeps := fs.Bool("with-deps", false, "include the tasks the pipeline uses")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 2 || !kinds[args[0]] {
+			return errors.New("usage: hub get task|pipeline <name|id> [--version version] [--with-deps]")
+		}
+		resource, err := c.resolve(ctx, args[1], args[0])
+		if err != nil {
+			return err
+		}
+		if *withDeps {
+			if *version != "" {
+				return errors.New("the dependencies are only bundled with the latest version")
+			}
+			bundle, err := c.client.Bundle(ctx, resource.ID, "yaml")
+			if err != nil {
+				return err
+			}
+			c.warn(resource, bundle.Warning)
+			_, err = c.stdout.Write(bundle.Content)
+			return err
+		}
+		manifest, err := c.manifest(ctx, resource, *version)
+		if err != nil {
+			return err
+		}
+		c.warn(resource, "")
+		_, err = fmt.Fprint(c.stdout, manifest)
+		return err
+	}
+}
+
+func installCommand(fs *flag.FlagSet) command {
+	version := fs.String("version", "", "version of the resource, the latest by default")
+	dir := fs.String("dir", "", "directory the manifests are written to, stdout by default")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 2 || !kinds[args[0]] {
+			return errors.New("usage: hub install task|pipeline <name|id> [--version version] [--dir dir]")
+		}
+		resource, err := c.resolve(ctx, args[1], args[0])
+		if err != nil {
+			return err
+		}
+		// the version is bundled with the tasks it uses and counted as a
+		// download
+		bundle, err := c.client.Install(ctx, resource.ID, "yaml", *version)
+		if err != nil {
+			return err
+		}
+		c.warn(resource, bundle.Warning)
+		content := bundle.Content
+		if *dir == "" {
+			_, err = c.stdout.Write(content)
+			return err
+		}
+		if err := os.MkdirAll(*dir, 0755); err != nil {
+			return err
+		}
+		path := filepath.Join(*dir, resource.Name+".yaml")
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stdout, "Wrote %s, apply it with kubectl apply -f %s\n", path, path)
+		return nil
+	}
+}
+
+func rateCommand(fs *flag.FlagSet) command {
+	kind := fs.String("type", "", "task or pipeline")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 2 {
+			return errors.New("usage: hub rate <name|id> <stars>")
+		}
+		stars, err := strconv.Atoi(args[1])
+		if err != nil || stars < 1 || stars > 5 {
+			return fmt.Errorf("invalid stars %q, expected 1 to 5", args[1])
+		}
+		if err := checkKind(*kind); err != nil {
+			return err
+		}
+		userID, err := c.userID(ctx)
+		if err != nil {
+			return err
+		}
+		resource, err := c.resolve(ctx, args[0], *kind)
+		if err != nil {
+			return err
+		}
+		result, err := c.client.Rate(ctx, resource.ID, userID, stars)
+		if client.IsConflict(err) {
+			// the resource was already rated by the user
+			var previous *client.UserRating
+			if previous, err = c.client.UserRating(ctx, resource.ID, userID); err == nil {
+				result, err = c.client.UpdateRating(ctx, resource.ID, userID, stars, previous.Stars)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return c.print(result, func(w *tabwriter.Writer) {
+			row(w, "Rated:", fmt.Sprintf("%s with %d stars", resource.Name, stars))
+			row(w, "Average:", fmt.Sprintf("%.1f", result.Average))
+		})
+	}
+}
+
+func publishCommand(fs *flag.FlagSet) command {
+	var tags stringsFlag
+	upload := client.Upload{}
+	fs.StringVar(&upload.Name, "name", "", "name of the resource")
+	fs.StringVar(&upload.Type, "type", "", "task or pipeline")
+	fs.StringVar(&upload.Github, "github", "", "GitHub URL of the YAML file")
+	fs.StringVar(&upload.Description, "description", "", "description of the resource")
+	fs.Var(&tags, "tag", "tag of the resource")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 0 || upload.Name == "" || upload.Github == "" || !kinds[upload.Type] {
+			return errors.New("usage: hub publish --type task|pipeline --name name --github url [--description text] [--tag tag]...")
+		}
+		userID, err := c.userID(ctx)
+		if err != nil {
+			return err
+		}
+		upload.UserID, upload.Tags = userID, tags
+		result, err := c.client.Upload(ctx, upload)
+		var e *client.Error
+		if errors.As(err, &e) && len(e.Details) > 0 {
+			// the diagnostics of a failed validation
+			diagnostics := diagnostic.List{}
+			if json.Unmarshal(e.Details, &diagnostics) == nil {
+				for _, d := range diagnostics {
+					fmt.Fprintln(c.stderr, d)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return c.print(result, func(w *tabwriter.Writer) {
+			row(w, "Published:", fmt.Sprintf("%s as resource %d", upload.Name, result.ResourceID))
+			row(w, "Quality:", result.Quality)
+		})
+	}
+}
+
+func loginCommand(fs *flag.FlagSet) command {
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 0 {
+			return errors.New("usage: hub login [--url url] [--token token]")
+		}
+		if c.token == "" || c.saved {
+			fmt.Fprint(c.stderr, "Personal token: ")
+			line, err := bufio.NewReader(c.stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return errors.New("no token given")
+			}
+			c.token = strings.TrimSpace(line)
+			hub, err := client.New(c.url, client.WithToken(c.token))
+			if err != nil {
+				return err
+			}
+			c.client = hub
+		}
+		userID, err := tokenUser(ctx, c.client)
+		if err != nil {
+			return err
+		}
+		path, err := saveConfig(config{URL: c.url, Token: c.token, UserID: userID})
+		if err != nil {
+			return err
+		}
+		login := map[string]interface{}{"url": c.url, "user_id": userID, "config": path}
+		return c.print(login, func(w *tabwriter.Writer) {
+			row(w, "Logged in:", fmt.Sprintf("%s as user %d", c.url, userID))
+			row(w, "Config:", path)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Command hub searches, fetches and installs the tasks and pipelines of a
+// Tekton Hub from scripts and CI, e.g.
+//
+//	hub get task git-clone --version 0.2 | kubectl apply -f -
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/client"
+)
+
+const usage = `hub is a command-line client of the Tekton Hub
+
+Usage:
+  hub search [text] [--type task|pipeline] [--tag tag]... [--param name]... [--workspace name]... [--result name]...
+  hub info <name|id> [--type task|pipeline]
+  hub get task <name|id> [--version version]
+  hub get pipeline <name|id> [--version version] [--with-deps]
+  hub install task|pipeline <name|id> [--version version] [--dir dir]
+  hub rate <name|id> <stars> [--type task|pipeline]
+  hub publish --type task|pipeline --name name --github url [--description text] [--tag tag]...
+  hub login [--token token]
+
+Every command accepts:
+  --url URL             URL of the hub, $HUB_URL or the URL of the login by default
+  --token TOKEN         personal token, $HUB_TOKEN or the token of the login by default
+  -o, --output FORMAT   table, json or yaml, for search, info, rate, publish and login
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// command runs a subcommand with its arguments once the flags are parsed
+type command func(ctx context.Context, cli *cli, args []string) error
+
+// run runs the command line args
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+	commands := map[string]func(fs *flag.FlagSet) command{
+		"search":  searchCommand,
+		"info":    infoCommand,
+		"get":     getCommand,
+		"install": installCommand,
+		"rate":    rateCommand,
+		"publish": publishCommand,
+		"login":   loginCommand,
+	}
+	newCommand, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, run hub help for the usage", args[0])
+	}
+	fs := flag.NewFlagSet("hub "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cli := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	fs.StringVar(&cli.url, "url", "", "URL of the hub")
+	fs.StringVar(&cli.token, "token", "", "personal token")
+	fs.StringVar(&cli.output, "output", "table", "table, json or yaml")
+	fs.StringVar(&cli.output, "o", "table", "table, json or yaml")
+	cmd := newCommand(fs)
+	positional, err := parse(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if cli.output != "table" && cli.output != "json" && cli.output != "yaml" {
+		return fmt.Errorf("invalid output %q, expected table, json or yaml", cli.output)
+	}
+	if err := cli.configure(); err != nil {
+		return err
+	}
+	return cmd(context.Background(), cli, positional)
+}
+
+// parse parses the flags of a command wherever they are, e.g. both
+// get task --version 0.2 git-clone and get task git-clone --version 0.2
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// stringsFlag is a flag which can be repeated
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// config is what hub login remembers
+type config struct {
+	URL    string `json:"url"`
+	Token  string `json:"token"`
+	UserID int    `json:"user_id"`
+}
+
+// configPath returns the path of the config, $HUB_CONFIG or hub.json in
+// the config directory of the user
+func configPath() (string, error) {
+	if path := os.Getenv("HUB_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tekton-hub", "hub.json"), nil
+}
+
+func loadConfig() (config, error) {
+	conf := config{}
+	path, err := configPath()
+	if err != nil {
+		return conf, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return conf, nil
+	}
+	if err != nil {
+		return conf, err
+	}
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return conf, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	return conf, nil
+}
+
+func saveConfig(conf config) (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(conf, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// the token is a secret
+	return path, ioutil.WriteFile(path, data, 0600)
+}
+
+// cli holds the options shared by the commands
+type cli struct {
+	url    string
+	token  string
+	output string
+	conf   config
+	client *client.Client
+	// saved is set when the token is the one of the login
+	saved bool
+
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+// configure fills the URL and token from the environment or the login and
+// creates the client
+func (c *cli) configure() error {
+	conf, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	c.conf = conf
+	c.saved = c.token == "" && os.Getenv("HUB_TOKEN") == "" && conf.Token != ""
+	for _, option := range []struct {
+		value    *string
+		env, def string
+	}{
+		{&c.url, "HUB_URL", conf.URL},
+		{&c.token, "HUB_TOKEN", conf.Token},
+	} {
+		if *option.value == "" {
+			*option.value = os.Getenv(option.env)
+		}
+		if *option.value == "" {
+			*option.value = option.def
+		}
+	}
+	if c.url == "" {
+		return errors.New("the URL of the hub is not set, use --url, $HUB_URL or hub login")
+	}
+	c.client, err = client.New(c.url, client.WithToken(c.token))
+	return err
+}
+
+// userID returns the user of the token, which is needed for ratings and
+// uploads
+func (c *cli) userID(ctx context.Context) (int, error) {
+	if c.token == "" {
+		return 0, errors.New("a personal token is required, use --token, $HUB_TOKEN or hub login")
+	}
+	if c.saved && c.conf.UserID != 0 {
+		return c.conf.UserID, nil
+	}
+	return tokenUser(ctx, c.client)
+}
+
+// tokenUser returns the user the token of a client belongs to
+func tokenUser(ctx context.Context, hub *client.Client) (int, error) {
+	user, err := hub.CurrentUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/client"
+)
+
+// hub serves a fake v1 API with the task git-clone, which user 42 rated
+func hub(t *testing.T) *httptest.Server {
+	gitClone := client.Resource{ID: 1, Name: "git-clone", Type: "task", Description: "Clone a git repository"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/resources", func(w http.ResponseWriter, r *http.Request) {
+		resources := []client.Resource{}
+		if kind := r.FormValue("type"); kind == "" || kind == "task" {
+			resources = append(resources, gitClone, client.Resource{ID: 2, Name: "buildah", Type: "task"})
+		}
+		json.NewEncoder(w).Encode(resources)
+	})
+	mux.HandleFunc("/v1/resources/1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gitClone)
+	})
+	mux.HandleFunc("/v1/resources/1/versions/0.2", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("kind: Task\nmetadata:\n  name: git-clone\n"))
+	})
+	mux.HandleFunc("/v1/resources/1/ratings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			apierror.Write(w, r, apierror.New(apierror.Conflict, "rating already exists"))
+			return
+		}
+		rating := map[string]int{}
+		json.NewDecoder(r.Body).Decode(&rating)
+		if rating["user_id"] != 42 || rating["prev_stars"] != 3 {
+			t.Errorf("unexpected rating %v", rating)
+		}
+		json.NewEncoder(w).Encode(client.RatingResult{ResourceID: 1, Average: 4.5})
+	})
+	mux.HandleFunc("/v1/resources/1/ratings/42", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(client.UserRating{UserID: 42, ResourceID: 1, Stars: 3})
+	})
+	mux.HandleFunc("/v1/resources/1/install", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("version") != "0.2" {
+			apierror.Write(w, r, apierror.New(apierror.NotFound, "Version not found"))
+			return
+		}
+		w.Write([]byte("kind: Task\nmetadata:\n  name: git-clone\n"))
+	})
+	mux.HandleFunc("/v1/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer hub_secret" {
+			apierror.Write(w, r, apierror.New(apierror.Unauthorized, "Missing or invalid token"))
+			return
+		}
+		json.NewEncoder(w).Encode(client.User{ID: 42, UserName: "octocat"})
+	})
+	return httptest.NewServer(mux)
+}
+
+// useConfig points the config to a temporary directory until the returned
+// function is called
+func useConfig(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "hub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("HUB_CONFIG", filepath.Join(dir, "hub.json"))
+	return func() {
+		os.Unsetenv("HUB_CONFIG")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestGet(t *testing.T) {
+	server := hub(t)
+	defer server.Close()
+	defer useConfig(t)()
+
+	stdout := &bytes.Buffer{}
+	err := run([]string{"get", "task", "git-clone", "--version", "0.2", "--url", server.URL}, nil, stdout, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stdout.String(), "kind: Task") {
+		t.Errorf("unexpected manifest %q", stdout)
+	}
+
+	err = run([]string{"get", "pipeline", "git-clone", "--url", server.URL}, nil, stdout, ioutil.Discard)
+	if err == nil || err.Error() != `no pipeline named "git-clone"` {
+		t.Errorf("expected a missing pipeline, got %v", err)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	server := hub(t)
+	defer server.Close()
+	defer useConfig(t)()
+
+	// a version is installed through the endpoint counting downloads
+	stdout := &bytes.Buffer{}
+	err := run([]string{"install", "task", "git-clone", "--version", "0.2", "--url", server.URL}, nil, stdout, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stdout.String(), "kind: Task") {
+		t.Errorf("unexpected manifest %q", stdout)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	server := hub(t)
+	defer server.Close()
+	defer useConfig(t)()
+
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"search", "git", "-o", "json", "--url", server.URL}, nil, stdout, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	resources := []client.Resource{}
+	if err := json.Unmarshal(stdout.Bytes(), &resources); err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 1 || resources[0].Name != "git-clone" {
+		t.Errorf("unexpected resources %+v", resources)
+	}
+}
+
+func TestLoginAndRate(t *testing.T) {
+	server := hub(t)
+	defer server.Close()
+	defer useConfig(t)()
+
+	stdin := strings.NewReader("hub_secret\n")
+	if err := run([]string{"login", "--url", server.URL}, stdin, ioutil.Discard, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.URL != server.URL || conf.Token != "hub_secret" || conf.UserID != 42 {
+		t.Errorf("unexpected config %+v", conf)
+	}
+
+	// the login is used and the existing rating is updated
+	stdout := &bytes.Buffer{}
+	if err := run([]string{"rate", "git-clone", "5", "-o", "yaml"}, nil, stdout, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "average: 4.5") {
+		t.Errorf("unexpected output %q", stdout)
+	}
+}
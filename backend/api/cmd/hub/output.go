@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+)
+
+// print writes v in the output format, table writes the table format
+func (c *cli) print(v interface{}, table func(w *tabwriter.Writer)) error {
+	switch c.output {
+	case "json":
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stdout, string(data))
+	case "yaml":
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		c.stdout.Write(data)
+	default:
+		w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	}
+	return nil
+}
+
+// row writes the cells of a row of a table
+func row(w *tabwriter.Writer, cells ...interface{}) {
+	s := make([]string, len(cells))
+	for i, cell := range cells {
+		s[i] = fmt.Sprint(cell)
+	}
+	fmt.Fprintln(w, strings.Join(s, "\t"))
+}
+
+// truncate shortens a text to fit in a column of a table
+func truncate(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
@@ -0,0 +1,108 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+)
+
+// Supported intervals of a series
+const (
+	Day   = "day"
+	Week  = "week"
+	Month = "month"
+)
+
+// DateFormat is the format of dates in requests and responses
+const DateFormat = "2006-01-02"
+
+// Point represents the statistics of a resource for one interval
+type Point struct {
+	Date          string  `json:"date"`
+	Downloads     int     `json:"downloads"`
+	NewRatings    int     `json:"new_ratings"`
+	AverageRating float64 `json:"average_rating"`
+}
+
+// Series represents the statistics of a resource over time
+type Series struct {
+	ResourceID int            `json:"resource_id"`
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Interval   string         `json:"interval"`
+	Points     []Point        `json:"points"`
+	Versions   map[string]int `json:"versions"`
+}
+
+// IsValidInterval checks if the interval is supported
+func IsValidInterval(interval string) bool {
+	return interval == Day || interval == Week || interval == Month
+}
+
+// Truncate returns the first day of the interval the day belongs to, weeks
+// start on Monday
+func Truncate(day time.Time, interval string) time.Time {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case Week:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case Month:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+func next(day time.Time, interval string) time.Time {
+	switch interval {
+	case Week:
+		return day.AddDate(0, 0, 7)
+	case Month:
+		return day.AddDate(0, 1, 0)
+	}
+	return day.AddDate(0, 0, 1)
+}
+
+// NewSeries groups daily statistics into intervals between from and to. The
+// average rating of a point is the average of all ratings given until the end
+// of the interval, prior holds the ratings given before from
+func NewSeries(resourceID int, from, to time.Time, interval string, stats []models.DailyStat, prior models.DailyStat) *Series {
+	series := &Series{
+		ResourceID: resourceID,
+		From:       from.Format(DateFormat),
+		To:         to.Format(DateFormat),
+		Interval:   interval,
+		Points:     []Point{},
+		Versions:   map[string]int{},
+	}
+
+	index := map[string]int{}
+	for day := Truncate(from, interval); !day.After(to); day = next(day, interval) {
+		index[day.Format(DateFormat)] = len(series.Points)
+		series.Points = append(series.Points, Point{Date: day.Format(DateFormat)})
+	}
+
+	ratingSums := make([]int, len(series.Points))
+	for _, stat := range stats {
+		i, ok := index[Truncate(stat.Day, interval).Format(DateFormat)]
+		if !ok {
+			continue
+		}
+		series.Points[i].Downloads += stat.Downloads
+		series.Points[i].NewRatings += stat.Ratings
+		ratingSums[i] += stat.RatingSum
+		if stat.Downloads > 0 {
+			series.Versions[stat.Version] += stat.Downloads
+		}
+	}
+
+	ratings, sum := prior.Ratings, prior.RatingSum
+	for i := range series.Points {
+		ratings += series.Points[i].NewRatings
+		sum += ratingSums[i]
+		if ratings > 0 {
+			series.Points[i].AverageRating = float64(sum) / float64(ratings)
+		}
+	}
+	return series
+}
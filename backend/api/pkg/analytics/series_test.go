@@ -0,0 +1,62 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+)
+
+func date(value string) time.Time {
+	t, _ := time.Parse(DateFormat, value)
+	return t
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		day, interval, expected string
+	}{
+		{"2020-01-15", Day, "2020-01-15"},
+		{"2020-01-15", Week, "2020-01-13"},
+		{"2020-01-13", Week, "2020-01-13"},
+		{"2020-01-19", Week, "2020-01-13"},
+		{"2020-01-15", Month, "2020-01-01"},
+	}
+	for _, test := range tests {
+		if got := Truncate(date(test.day), test.interval).Format(DateFormat); got != test.expected {
+			t.Errorf("Truncate(%v, %v) Expected: %v , Got: %v", test.day, test.interval, test.expected, got)
+		}
+	}
+}
+
+func TestNewSeries(t *testing.T) {
+	stats := []models.DailyStat{
+		{ResourceID: 1, Version: "0.1", Day: date("2020-01-01"), Downloads: 2},
+		{ResourceID: 1, Version: "0.2", Day: date("2020-01-02"), Downloads: 3},
+		{ResourceID: 1, Version: "", Day: date("2020-01-02"), Ratings: 1, RatingSum: 5},
+	}
+	prior := models.DailyStat{Ratings: 1, RatingSum: 3}
+	series := NewSeries(1, date("2020-01-01"), date("2020-01-03"), Day, stats, prior)
+
+	if len(series.Points) != 3 {
+		t.Fatalf("Points Expected: %v , Got: %v", 3, len(series.Points))
+	}
+	expected := []Point{
+		{Date: "2020-01-01", Downloads: 2, AverageRating: 3},
+		{Date: "2020-01-02", Downloads: 3, NewRatings: 1, AverageRating: 4},
+		{Date: "2020-01-03", AverageRating: 4},
+	}
+	for i, point := range series.Points {
+		if point != expected[i] {
+			t.Errorf("Point Expected: %v , Got: %v", expected[i], point)
+		}
+	}
+	if series.Versions["0.1"] != 2 || series.Versions["0.2"] != 3 || len(series.Versions) != 2 {
+		t.Errorf("Unexpected versions: %v", series.Versions)
+	}
+
+	monthly := NewSeries(1, date("2020-01-01"), date("2020-01-03"), Month, stats, prior)
+	if len(monthly.Points) != 1 || monthly.Points[0].Downloads != 5 {
+		t.Errorf("Unexpected monthly series: %v", monthly.Points)
+	}
+}
@@ -0,0 +1,553 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/graphql"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/polling"
+)
+
+// GraphQL runs a GraphQL query, e.g. the resource of a detail page along
+// with its readme, versions, ratings and links. Errors of the query are
+// written in the "errors" of the GraphQL response
+func (api *Api) GraphQL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	req := graphql.Request{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid request body, expected a JSON object with a query"))
+		return
+	}
+	ctx := context.WithValue(r.Context(), loadersKey{}, newLoaders())
+	json.NewEncoder(w).Encode(api.schema.Execute(ctx, req))
+}
+
+// GetGraphQLSchema writes the GraphQL schema in the schema definition
+// language
+func (api *Api) GetGraphQLSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(api.schema.SDL()))
+}
+
+const (
+	// maxQueryDepth and maxQueryCost bound the number of nested fields and
+	// of values of a query
+	maxQueryDepth = 8
+	maxQueryCost  = 10000
+	// listSize is the number of items expected in a list, the lists of
+	// resources and tags hold all of them unless they are limited
+	listSize          = 20
+	resourcesListSize = 200
+	// readmeCost counts a README as a request to GitHub
+	readmeCost = 20
+	readmeTTL  = time.Hour
+)
+
+type loadersKey struct{}
+
+// loaders batch the queries of the fields of a GraphQL query, so that the
+// versions of a page of resources are queried at once rather than for each
+// resource
+type loaders struct {
+	resources     *graphql.Loader
+	versions      *graphql.Loader
+	ratings       *graphql.Loader
+	deprecations  *graphql.Loader
+	links         *graphql.Loader
+	github        *graphql.Loader
+	dependencies  *graphql.Loader
+	dependents    *graphql.Loader
+	users         *graphql.Loader
+	userResources *graphql.Loader
+	categories    *graphql.Loader
+	categoryTags  *graphql.Loader
+	// userRatings holds a loader of the stars given by each user
+	userRatings map[int]*graphql.Loader
+}
+
+// queryError converts an error for the response of a query, the code of an
+// API error is kept in the extensions and the other errors are internal
+func queryError(err error) error {
+	e, ok := err.(*apierror.Error)
+	if !ok {
+		e = apierror.New(apierror.Internal, "Internal error")
+	}
+	return &graphql.Error{Message: e.Message, Extensions: map[string]interface{}{"code": e.Code}}
+}
+
+// byID converts a map by id returned by the models for a loader
+func byID(m interface{}, err error) (map[int]interface{}, error) {
+	if err != nil {
+		return nil, queryError(err)
+	}
+	values := map[int]interface{}{}
+	iter := reflect.ValueOf(m).MapRange()
+	for iter.Next() {
+		values[int(iter.Key().Int())] = iter.Value().Interface()
+	}
+	return values, nil
+}
+
+// dependencyLoader loads the ids of the tasks used by pipelines, or of the
+// pipelines using tasks when dependents is set
+func dependencyLoader(dependents bool) *graphql.Loader {
+	return graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+		dependencies, err := models.GetAllDependencies()
+		if err != nil {
+			return nil, queryError(err)
+		}
+		wanted := map[int]bool{}
+		for _, id := range ids {
+			wanted[id] = true
+		}
+		related := map[int][]int{}
+		for _, d := range dependencies {
+			from, to := d.ResourceID, d.DependsOnID
+			if dependents {
+				from, to = to, from
+			}
+			if wanted[from] && to != 0 {
+				related[from] = append(related[from], to)
+			}
+		}
+		return byID(related, nil)
+	})
+}
+
+func newLoaders() *loaders {
+	return &loaders{
+		resources: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetResourcesByIDs(ids))
+		}),
+		versions: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetVersionsByResources(ids))
+		}),
+		ratings: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetRatingsByResources(ids))
+		}),
+		deprecations: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetDeprecationsByResources(ids))
+		}),
+		links: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetRawLinksByResources(ids))
+		}),
+		github: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetGithubDetailsByResources(ids))
+		}),
+		dependencies: dependencyLoader(false),
+		dependents:   dependencyLoader(true),
+		users: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetUsersByIDs(ids))
+		}),
+		userResources: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetResourceIDsByUsers(ids))
+		}),
+		// the categories are few, they are all loaded at once
+		categories: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			categories, err := models.GetCategories()
+			if err != nil {
+				return nil, queryError(err)
+			}
+			values := map[int]interface{}{}
+			for _, c := range categories {
+				values[c.ID] = c
+			}
+			return values, nil
+		}),
+		categoryTags: graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetTagsByCategories(ids))
+		}),
+		userRatings: map[int]*graphql.Loader{},
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// userRating returns the loader of the stars given by a user
+func (l *loaders) userRating(userID int) *graphql.Loader {
+	loader, ok := l.userRatings[userID]
+	if !ok {
+		loader = graphql.NewLoader(func(ids []int) (map[int]interface{}, error) {
+			return byID(models.GetUserRatings(userID, ids))
+		})
+		l.userRatings[userID] = loader
+	}
+	return loader
+}
+
+// resourceList loads resources by id, the deleted ones are left out
+func (l *loaders) resourceList(ids []int) graphql.Thunk {
+	return graphql.Then(l.resources.LoadMany(ids), func(v interface{}) (interface{}, error) {
+		resources := []models.Resource{}
+		for _, r := range v.([]interface{}) {
+			if resource, ok := r.(models.Resource); ok {
+				resources = append(resources, resource)
+			}
+		}
+		return resources, nil
+	})
+}
+
+// resourceVersions loads the versions of a resource, oldest first
+func (l *loaders) resourceVersions(resourceID int) graphql.Thunk {
+	return graphql.Then(l.versions.Load(resourceID), func(v interface{}) (interface{}, error) {
+		versions, _ := v.([]models.ResourceVersion)
+		return append([]models.ResourceVersion{}, versions...), nil
+	})
+}
+
+// resourceRatings is the rating of a resource along with its average
+type resourceRatings struct {
+	models.Rating
+	Average float64
+}
+
+// field returns a field whose value is computed from the value of its
+// object only
+func field(name, description string, t graphql.Type, value func(source interface{}) interface{}) *graphql.Field {
+	return &graphql.Field{Name: name, Description: description, Type: t,
+		Resolve: func(p graphql.Params) (interface{}, error) {
+			return value(p.Source), nil
+		}}
+}
+
+func stringList() graphql.Type {
+	return &graphql.List{Of: graphql.String}
+}
+
+// newSchema returns the GraphQL schema of the hub, it panics if the schema
+// is invalid as it does not depend on the input
+func (api *Api) newSchema() *graphql.Schema {
+	resourceType := &graphql.Object{Name: "Resource", Description: "A task or a pipeline"}
+	versionType := &graphql.Object{Name: "Version", Description: "A version of a resource as it was uploaded or synced",
+		Fields: []*graphql.Field{
+			field("id", "", graphql.Int, func(s interface{}) interface{} { return s.(models.ResourceVersion).ID }),
+			field("version", "Version label, the id of an unlabelled version", graphql.String, func(s interface{}) interface{} {
+				return versionName(s.(models.ResourceVersion))
+			}),
+			field("apiVersion", "Tekton apiVersion", graphql.String, func(s interface{}) interface{} { return s.(models.ResourceVersion).APIVersion }),
+			field("rawPath", "Raw GitHub link of the YAML file", graphql.String, func(s interface{}) interface{} { return s.(models.ResourceVersion).RawPath }),
+			field("createdAt", "Time of the upload or sync, in RFC 3339", graphql.String, func(s interface{}) interface{} {
+				return s.(models.ResourceVersion).CreatedAt.Format(time.RFC3339)
+			}),
+			field("yaml", "Content of the YAML file", graphql.String, func(s interface{}) interface{} { return s.(models.ResourceVersion).Content }),
+		}}
+	ratingsType := &graphql.Object{Name: "Ratings", Description: "Number of ratings of a resource by stars",
+		Fields: []*graphql.Field{
+			field("one", "", graphql.Int, func(s interface{}) interface{} { return s.(resourceRatings).OneStar }),
+			field("two", "", graphql.Int, func(s interface{}) interface{} { return s.(resourceRatings).TwoStar }),
+			field("three", "", graphql.Int, func(s interface{}) interface{} { return s.(resourceRatings).ThreeStar }),
+			field("four", "", graphql.Int, func(s interface{}) interface{} { return s.(resourceRatings).FourStar }),
+			field("five", "", graphql.Int, func(s interface{}) interface{} { return s.(resourceRatings).FiveStar }),
+			field("average", "", graphql.Float, func(s interface{}) interface{} { return s.(resourceRatings).Average }),
+		}}
+	linksType := &graphql.Object{Name: "Links", Description: "Raw GitHub links of the files of a resource",
+		Fields: []*graphql.Field{
+			field("tasks", "", stringList(), func(s interface{}) interface{} { return append([]string{}, s.(models.RawLinksResponse).Tasks...) }),
+			field("pipelines", "", stringList(), func(s interface{}) interface{} { return append([]string{}, s.(models.RawLinksResponse).Pipelines...) }),
+		}}
+	deprecationType := &graphql.Object{Name: "Deprecation", Description: "Why a resource is deprecated and what to use instead",
+		Fields: []*graphql.Field{
+			field("reason", "", graphql.String, func(s interface{}) interface{} { return s.(*models.ResourceDeprecation).Reason }),
+			{Name: "supersededBy", Type: resourceType, Resolve: func(p graphql.Params) (interface{}, error) {
+				d := p.Source.(*models.ResourceDeprecation)
+				if d.SupersededBy == nil {
+					return nil, nil
+				}
+				return loadersFrom(p.Context).resources.Load(*d.SupersededBy), nil
+			}},
+			field("supersededByVersion", "", graphql.String, func(s interface{}) interface{} {
+				return s.(*models.ResourceDeprecation).SupersededByVersion
+			}),
+			field("deprecatedAt", "Time of the deprecation, in RFC 3339", graphql.String, func(s interface{}) interface{} {
+				return s.(*models.ResourceDeprecation).DeprecatedAt.Format(time.RFC3339)
+			}),
+		}}
+	categoryType := &graphql.Object{Name: "Category", Description: "A category of tags"}
+	tagType := &graphql.Object{Name: "Tag", Fields: []*graphql.Field{
+		field("id", "", graphql.Int, func(s interface{}) interface{} { return s.(models.Tag).ID }),
+		field("name", "", graphql.String, func(s interface{}) interface{} { return s.(models.Tag).Name }),
+		{Name: "category", Type: categoryType, Resolve: func(p graphql.Params) (interface{}, error) {
+			return loadersFrom(p.Context).categories.Load(p.Source.(models.Tag).CategoryID), nil
+		}},
+	}}
+	categoryType.Fields = []*graphql.Field{
+		field("id", "", graphql.Int, func(s interface{}) interface{} { return s.(models.Category).ID }),
+		field("name", "", graphql.String, func(s interface{}) interface{} { return s.(models.Category).Name }),
+		{Name: "tags", Type: &graphql.List{Of: tagType}, Resolve: func(p graphql.Params) (interface{}, error) {
+			return graphql.Then(loadersFrom(p.Context).categoryTags.Load(p.Source.(models.Category).ID), func(v interface{}) (interface{}, error) {
+				tags, _ := v.([]models.Tag)
+				return append([]models.Tag{}, tags...), nil
+			}), nil
+		}},
+	}
+	userType := &graphql.Object{Name: "User", Fields: []*graphql.Field{
+		field("id", "", graphql.Int, func(s interface{}) interface{} { return s.(models.UserCredential).ID }),
+		field("username", "GitHub login", graphql.String, func(s interface{}) interface{} { return s.(models.UserCredential).UserName }),
+		field("firstName", "", graphql.String, func(s interface{}) interface{} { return s.(models.UserCredential).FirstName }),
+		field("lastName", "", graphql.String, func(s interface{}) interface{} { return s.(models.UserCredential).LastName }),
+		{Name: "resources", Description: "Resources uploaded by the user", Type: &graphql.List{Of: resourceType},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				l := loadersFrom(p.Context)
+				return graphql.Then(l.userResources.Load(p.Source.(models.UserCredential).ID), func(v interface{}) (interface{}, error) {
+					ids, _ := v.([]int)
+					return l.resourceList(ids), nil
+				}), nil
+			}},
+	}}
+
+	resourceType.Fields = []*graphql.Field{
+		field("id", "", graphql.Int, func(s interface{}) interface{} { return s.(models.Resource).ID }),
+		field("name", "", graphql.String, func(s interface{}) interface{} { return s.(models.Resource).Name }),
+		field("type", "task or pipeline", graphql.String, func(s interface{}) interface{} { return s.(models.Resource).Type }),
+		field("description", "", graphql.String, func(s interface{}) interface{} { return s.(models.Resource).Description }),
+		field("downloads", "", graphql.Int, func(s interface{}) interface{} { return s.(models.Resource).Downloads }),
+		field("rating", "Average rating", graphql.Float, func(s interface{}) interface{} { return s.(models.Resource).Rating }),
+		field("github", "", graphql.String, func(s interface{}) interface{} { return s.(models.Resource).Github }),
+		field("tags", "", stringList(), func(s interface{}) interface{} { return append([]string{}, s.(models.Resource).Tags...) }),
+		field("verified", "", graphql.Boolean, func(s interface{}) interface{} { return s.(models.Resource).Verified }),
+		field("apiVersion", "Tekton apiVersion", graphql.String, func(s interface{}) interface{} { return s.(models.Resource).APIVersion }),
+		field("quality", "", graphql.Int, func(s interface{}) interface{} { return s.(models.Resource).Quality }),
+		field("criticalVulnerabilities", "Whether an image of the resource has critical vulnerabilities", graphql.Boolean, func(s interface{}) interface{} {
+			return s.(models.Resource).Critical
+		}),
+		field("deprecated", "", graphql.Boolean, func(s interface{}) interface{} { return s.(models.Resource).Deprecated }),
+		{Name: "deprecation", Type: deprecationType, Resolve: func(p graphql.Params) (interface{}, error) {
+			resource := p.Source.(models.Resource)
+			if !resource.Deprecated {
+				return nil, nil
+			}
+			return loadersFrom(p.Context).deprecations.Load(resource.ID), nil
+		}},
+		{Name: "yaml", Description: "Content of the YAML file of the latest version", Type: graphql.String,
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				return graphql.Then(loadersFrom(p.Context).resourceVersions(p.Source.(models.Resource).ID), func(v interface{}) (interface{}, error) {
+					if versions := v.([]models.ResourceVersion); len(versions) > 0 {
+						return versions[len(versions)-1].Content, nil
+					}
+					return nil, nil
+				}), nil
+			}},
+		{Name: "readme", Description: "README of the resource, fetched from GitHub", Type: graphql.String, Cost: readmeCost,
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				return api.readme(p.Context, loadersFrom(p.Context), p.Source.(models.Resource).ID), nil
+			}},
+		{Name: "links", Type: linksType, Resolve: func(p graphql.Params) (interface{}, error) {
+			return graphql.Then(loadersFrom(p.Context).links.Load(p.Source.(models.Resource).ID), func(v interface{}) (interface{}, error) {
+				links, _ := v.(models.RawLinksResponse)
+				return links, nil
+			}), nil
+		}},
+		{Name: "versions", Description: "Versions of the resource, oldest first", Type: &graphql.List{Of: versionType},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				return loadersFrom(p.Context).resourceVersions(p.Source.(models.Resource).ID), nil
+			}},
+		{Name: "latestVersion", Type: versionType, Resolve: func(p graphql.Params) (interface{}, error) {
+			return graphql.Then(loadersFrom(p.Context).resourceVersions(p.Source.(models.Resource).ID), func(v interface{}) (interface{}, error) {
+				if versions := v.([]models.ResourceVersion); len(versions) > 0 {
+					return versions[len(versions)-1], nil
+				}
+				return nil, nil
+			}), nil
+		}},
+		{Name: "version", Description: "A version by its label, or its id if it is unlabelled", Type: versionType,
+			Args: []*graphql.Arg{{Name: "version", Type: &graphql.NonNull{Of: graphql.String}}},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				ref := p.Args["version"].(string)
+				return graphql.Then(loadersFrom(p.Context).resourceVersions(p.Source.(models.Resource).ID), func(v interface{}) (interface{}, error) {
+					versions := v.([]models.ResourceVersion)
+					for i := len(versions) - 1; i >= 0; i-- {
+						if versionName(versions[i]) == ref {
+							return versions[i], nil
+						}
+					}
+					return nil, nil
+				}), nil
+			}},
+		{Name: "ratings", Type: ratingsType, Resolve: func(p graphql.Params) (interface{}, error) {
+			resource := p.Source.(models.Resource)
+			return graphql.Then(loadersFrom(p.Context).ratings.Load(resource.ID), func(v interface{}) (interface{}, error) {
+				rating, _ := v.(models.Rating)
+				return resourceRatings{Rating: rating, Average: resource.Rating}, nil
+			}), nil
+		}},
+		{Name: "userRating", Description: "Stars given by a user, null if the user did not rate the resource", Type: graphql.Int,
+			Args: []*graphql.Arg{{Name: "userId", Type: &graphql.NonNull{Of: graphql.Int}}},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				return loadersFrom(p.Context).userRating(p.Args["userId"].(int)).Load(p.Source.(models.Resource).ID), nil
+			}},
+		{Name: "dependencies", Description: "Tasks of the hub used by a pipeline", Type: &graphql.List{Of: resourceType},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				return relatedResources(p, loadersFrom(p.Context).dependencies), nil
+			}},
+		{Name: "dependents", Description: "Pipelines of the hub using a task", Type: &graphql.List{Of: resourceType},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				return relatedResources(p, loadersFrom(p.Context).dependents), nil
+			}},
+	}
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{Name: "resource", Type: resourceType,
+			Args: []*graphql.Arg{{Name: "id", Type: &graphql.NonNull{Of: graphql.Int}}},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				return loadersFrom(p.Context).resources.Load(p.Args["id"].(int)), nil
+			}},
+		{Name: "resources", Description: "Resources matching the filters, like GET /v1/resources", Type: &graphql.List{Of: resourceType},
+			Args: []*graphql.Arg{
+				{Name: "type", Description: "task, pipeline or all", Type: graphql.String, Default: "all"},
+				{Name: "verified", Description: "true, false or all", Type: graphql.String, Default: "all"},
+				{Name: "tags", Description: "tags of the resources, any of them matches", Type: stringList()},
+				{Name: "apiVersion", Type: graphql.String},
+				{Name: "limit", Description: "number of resources, all of them by default", Type: graphql.Int},
+				{Name: "offset", Description: "number of resources skipped", Type: graphql.Int, Default: 0},
+			},
+			ListSize: resourcesListSize,
+			Resolve:  resolveResources},
+		{Name: "tags", Type: &graphql.List{Of: tagType}, ListSize: resourcesListSize, Resolve: func(p graphql.Params) (interface{}, error) {
+			return models.GetAllTags(), nil
+		}},
+		{Name: "categories", Type: &graphql.List{Of: categoryType}, Resolve: func(p graphql.Params) (interface{}, error) {
+			categories, err := models.GetCategories()
+			if err != nil {
+				return nil, queryError(err)
+			}
+			return categories, nil
+		}},
+		{Name: "user", Type: userType,
+			Args: []*graphql.Arg{{Name: "id", Type: &graphql.NonNull{Of: graphql.Int}}},
+			Resolve: func(p graphql.Params) (interface{}, error) {
+				return loadersFrom(p.Context).users.Load(p.Args["id"].(int)), nil
+			}},
+	}}
+	schema, err := graphql.NewSchema(query)
+	if err != nil {
+		panic(err)
+	}
+	schema.MaxDepth, schema.MaxCost, schema.ListSize = maxQueryDepth, maxQueryCost, listSize
+	return schema
+}
+
+func relatedResources(p graphql.Params, loader *graphql.Loader) graphql.Thunk {
+	l := loadersFrom(p.Context)
+	return graphql.Then(loader.Load(p.Source.(models.Resource).ID), func(v interface{}) (interface{}, error) {
+		ids, _ := v.([]int)
+		return l.resourceList(ids), nil
+	})
+}
+
+func resolveResources(p graphql.Params) (interface{}, error) {
+	resourceType, verified := p.Args["type"], p.Args["verified"]
+	if resourceType != "all" && resourceType != "task" && resourceType != "pipeline" {
+		return nil, queryError(apierror.New(apierror.BadRequest, "Invalid type %q, expected task, pipeline or all", resourceType))
+	}
+	if verified != "all" && verified != "true" && verified != "false" {
+		return nil, queryError(apierror.New(apierror.BadRequest, "Invalid verified %q, expected true, false or all", verified))
+	}
+	var tags []string
+	if list, ok := p.Args["tags"].([]interface{}); ok {
+		for _, tag := range list {
+			if tag != nil {
+				tags = append(tags, tag.(string))
+			}
+		}
+	}
+	resources := models.GetAllResourcesWithGivenTags(resourceType.(string), verified.(string), tags)
+	if apiVersion, ok := p.Args["apiVersion"].(string); ok && apiVersion != "" {
+		resources = models.FilterResourcesByAPIVersion(resources, apiVersion)
+	}
+	offset, _ := p.Args["offset"].(int)
+	limit, hasLimit := p.Args["limit"].(int)
+	if offset < 0 || hasLimit && limit < 0 {
+		return nil, queryError(apierror.New(apierror.BadRequest, "Invalid limit or offset, expected a positive number"))
+	}
+	if offset > len(resources) {
+		offset = len(resources)
+	}
+	resources = resources[offset:]
+	if hasLimit && limit < len(resources) {
+		resources = resources[:limit]
+	}
+	// the fields of the resources are loaded without querying them again
+	l := loadersFrom(p.Context)
+	for _, resource := range resources {
+		l.resources.Prime(resource.ID, resource)
+	}
+	return resources, nil
+}
+
+// readme returns the README of a resource, null if it has none. The READMEs
+// are fetched from GitHub once per readmeTTL, as a list of resources would
+// otherwise use up the rate limit of GitHub
+func (api *Api) readme(ctx context.Context, l *loaders, resourceID int) graphql.Thunk {
+	return graphql.Then(l.github.Load(resourceID), func(v interface{}) (interface{}, error) {
+		details, ok := v.(models.ResourceGithubResponse)
+		if !ok || details.ReadmePath == "" {
+			return nil, nil
+		}
+		key := strings.Join([]string{details.Owner, details.RepositoryName, details.ReadmePath}, "/")
+		if content, ok := api.readmes.get(key); ok {
+			return content, nil
+		}
+		desc, err := polling.GetFileContent(ctx, api.app.GitHub().Client, details.Owner, details.RepositoryName, details.ReadmePath, nil)
+		if err != nil {
+			api.Log.Error(err)
+			return nil, queryError(errGithubUnavailable)
+		}
+		content, err := desc.GetContent()
+		if err != nil {
+			api.Log.Error(err)
+			return nil, queryError(errGithubUnavailable)
+		}
+		api.readmes.set(key, content)
+		return content, nil
+	})
+}
+
+// fileCache keeps the files fetched from GitHub for a while
+type fileCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	files map[string]cachedFile
+}
+
+type cachedFile struct {
+	content string
+	expires time.Time
+}
+
+func newFileCache(ttl time.Duration) *fileCache {
+	return &fileCache{ttl: ttl, files: map[string]cachedFile{}}
+}
+
+func (c *fileCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, ok := c.files[key]
+	if !ok || time.Now().After(f.expires) {
+		return "", false
+	}
+	return f.content, true
+}
+
+// set caches the content of a file, the expired files are dropped so that
+// the cache only holds the files of the current resources
+func (c *fileCache) set(key, content string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, f := range c.files {
+		if now.After(f.expires) {
+			delete(c.files, k)
+		}
+	}
+	c.files[key] = cachedFile{content: content, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGraphQLLimits checks the queries above the limits are refused before
+// anything is loaded
+func TestGraphQLLimits(t *testing.T) {
+	api := &Api{}
+	api.schema = api.newSchema()
+	tests := []struct {
+		query, message string
+	}{
+		{`{ resource(id: 1) { deprecation { supersededBy { deprecation { supersededBy { deprecation { supersededBy { deprecation { supersededBy { name } } } } } } } } } }`,
+			"nested more than the maximum of 8 levels"},
+		{`{ resources { readme versions { version yaml rawPath } } }`, "costs more than the maximum of 10000"},
+		{`{ resources(limit: 10) { dependents { dependents { readme } } } }`, "costs more than the maximum of 10000"},
+	}
+	for _, tc := range tests {
+		body := `{"query": "` + strings.ReplaceAll(tc.query, `"`, `\"`) + `"}`
+		w := httptest.NewRecorder()
+		api.GraphQL(w, httptest.NewRequest("POST", "/v1/graphql", strings.NewReader(body)))
+		if !strings.Contains(w.Body.String(), tc.message) {
+			t.Errorf("%s: expected %q, got %s", tc.query, tc.message, w.Body)
+		}
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	c := newFileCache(time.Hour)
+	c.set("tektoncd/catalog/task/git-clone/README.md", "# git-clone")
+	if content, ok := c.get("tektoncd/catalog/task/git-clone/README.md"); !ok || content != "# git-clone" {
+		t.Errorf("expected the cached README, got %q %v", content, ok)
+	}
+	c = newFileCache(-time.Second)
+	c.set("tektoncd/catalog/task/git-clone/README.md", "# git-clone")
+	if _, ok := c.get("tektoncd/catalog/task/git-clone/README.md"); ok {
+		t.Errorf("expected the README to expire")
+	}
+}
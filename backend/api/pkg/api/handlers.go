@@ -2,39 +2,96 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/analytics"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/app"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/audit"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/authentication"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/bundle"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/diff"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/downloads"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/graphql"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/image"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/polling"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/purge"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/scan"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/upload"
 	"go.uber.org/zap"
 )
 
 type Api struct {
-	app app.Config
-	Log *zap.SugaredLogger
+	app       app.Config
+	Log       *zap.SugaredLogger
+	downloads *downloads.Tracker
+	schema    *graphql.Schema
+	readmes   *fileCache
+	bundles   *bundleCache
 }
 
 func New(app app.Config) *Api {
-	return &Api{
-		app: app,
-		Log: app.Logger().With("name", "api"),
+	api := &Api{
+		app:       app,
+		Log:       app.Logger().With("name", "api"),
+		downloads: downloads.New(app),
+		readmes:   newFileCache(readmeTTL),
+		bundles:   newBundleCache(),
 	}
+	api.schema = api.newSchema()
+	return api
+}
+
+var (
+	errInvalidResourceID = apierror.New(apierror.BadRequest, "Invalid Resource ID")
+	errInvalidBody       = apierror.New(apierror.BadRequest, "Invalid request body")
+	errResourceNotFound  = apierror.New(apierror.NotFound, "Resource doesn't exist")
+	errGithubUnavailable = apierror.New(apierror.Upstream, "Unable to fetch the file from GitHub")
+)
+
+// adminRequired rejects a request without the admin token, a signed in user
+// is forbidden and any other caller is unauthorized
+func adminRequired(r *http.Request) error {
+	if _, ok := authentication.UserID(r); ok {
+		return apierror.New(apierror.Forbidden, "Admin token required")
+	}
+	return apierror.New(apierror.Unauthorized, "Admin token required")
+}
+
+// resourceError reports a missing resource as not found, other errors are
+// internal
+func resourceError(err error) error {
+	if errors.Is(err, models.ErrResourceNotFound) {
+		return apierror.New(apierror.NotFound, "%s", err)
+	}
+	return err
+}
+
+// includeDeprecated tells if the deprecated resources are searched, they are
+// only found on request with ?deprecated=true
+func includeDeprecated(r *http.Request) bool {
+	return r.FormValue("deprecated") == "true"
 }
 
 // GetAllResources writes json encoded resources to ResponseWriter
 func (api *Api) GetAllResources(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.GetAllResources())
+	resources := models.GetAllResources()
+	if apiVersion := r.FormValue("apiVersion"); apiVersion != "" {
+		resources = models.FilterResourcesByAPIVersion(resources, apiVersion)
+	}
+	json.NewEncoder(w).Encode(resources)
 }
 
 // GetResourceByID writes json encoded resources to ResponseWriter
@@ -42,9 +99,25 @@ func (api *Api) GetResourceByID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		json.NewEncoder(w).Encode(map[string]interface{}{"status": false, "message": "Invalid User ID"})
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	resource := models.GetResourceByID(resourceID)
+	if resource.ID == 0 {
+		apierror.Write(w, r, errResourceNotFound)
+		return
+	}
+	resource.Interface, err = models.GetResourceInterface(resourceID)
+	if err != nil {
+		api.Log.Error(err)
+	}
+	if resource.Deprecated {
+		resource.Deprecation, err = models.GetResourceDeprecation(resourceID)
+		if err != nil {
+			api.Log.Error(err)
+		}
 	}
-	json.NewEncoder(w).Encode(models.GetResourceByID(resourceID))
+	json.NewEncoder(w).Encode(resource)
 }
 
 // GetAllTags writes json encoded list of tags to Responsewriter
@@ -66,51 +139,119 @@ func (api *Api) GetAllFilteredResourcesByTag(w http.ResponseWriter, r *http.Requ
 	if r.FormValue("tags") != "" {
 		tags = strings.Split(r.FormValue("tags"), "|")
 	}
-	json.NewEncoder(w).Encode(models.GetAllResourcesWithGivenTags(mux.Vars(r)["type"], mux.Vars(r)["verified"], tags))
+	resources := models.GetAllResourcesWithGivenTags(mux.Vars(r)["type"], mux.Vars(r)["verified"], tags)
+	if apiVersion := r.FormValue("apiVersion"); apiVersion != "" {
+		resources = models.FilterResourcesByAPIVersion(resources, apiVersion)
+	}
+	json.NewEncoder(w).Encode(resources)
 }
 
-// GetResourceYAMLFile returns a compressed zip with task files
-func (api *Api) GetResourceYAMLFile(w http.ResponseWriter, r *http.Request) {
-	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
-	if err != nil {
-		api.Log.Error(err)
+// ListResources returns the resources matching the filters of the query,
+// e.g. /v1/resources?type=task&verified=true&tag=build&tag=cli
+func (api *Api) ListResources(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+	resourceType, verified := query.Get("type"), query.Get("verified")
+	if resourceType == "" {
+		resourceType = "all"
 	}
-	githubDetails := models.GetResourceGithubDetails(resourceID)
-	gh := api.app.GitHub().Client
-	desc, err := polling.GetFileContent(context.Background(), gh, githubDetails.Owner, githubDetails.RepositoryName, githubDetails.Path, nil)
+	if verified == "" {
+		verified = "all"
+	}
+	if resourceType != "all" && resourceType != "task" && resourceType != "pipeline" {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid type %q, expected task, pipeline or all", resourceType))
+		return
+	}
+	if verified != "all" && verified != "true" && verified != "false" {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid verified %q, expected true, false or all", verified))
+		return
+	}
+	resources := models.GetAllResourcesWithGivenTags(resourceType, verified, query["tag"])
+	if apiVersion := query.Get("apiVersion"); apiVersion != "" {
+		resources = models.FilterResourcesByAPIVersion(resources, apiVersion)
+	}
+	resources, err := page(w, r, resources)
 	if err != nil {
-		api.Log.Error(err)
-		json.NewEncoder(w).Encode("noyaml")
+		apierror.Write(w, r, err)
 		return
 	}
-	content, err := desc.GetContent()
+	json.NewEncoder(w).Encode(resources)
+}
+
+// TotalCountHeader holds the number of items of a paginated list
+const TotalCountHeader = "X-Total-Count"
+
+// page returns the page of resources given by the limit and offset of the
+// query and sets their total in the TotalCountHeader. All the resources from
+// the offset are returned when there is no limit
+func page(w http.ResponseWriter, r *http.Request, resources []models.Resource) ([]models.Resource, error) {
+	limit, offset := 0, 0
+	for name, value := range map[string]*int{"limit": &limit, "offset": &offset} {
+		param := r.FormValue(name)
+		if param == "" {
+			continue
+		}
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 0 {
+			return nil, apierror.New(apierror.BadRequest, "Invalid %s %q, expected a positive number", name, param)
+		}
+		*value = n
+	}
+	w.Header().Set(TotalCountHeader, strconv.Itoa(len(resources)))
+	if offset > len(resources) {
+		offset = len(resources)
+	}
+	resources = resources[offset:]
+	if limit > 0 && limit < len(resources) {
+		resources = resources[:limit]
+	}
+	return resources, nil
+}
+
+// GetResourceYAMLFile writes the YAML file of a resource
+func (api *Api) GetResourceYAMLFile(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		api.Log.Error(err)
-		json.NewEncoder(w).Encode("noyaml")
+		apierror.Write(w, r, errInvalidResourceID)
 		return
 	}
-	w.Write([]byte(content))
+	githubDetails := models.GetResourceGithubDetails(resourceID)
+	if githubDetails.Path == "" {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Resource has no YAML file"))
+		return
+	}
+	api.writeGithubFile(w, r, githubDetails, githubDetails.Path)
 }
 
 // GetResourceReadmeFile will return  a README file
 func (api *Api) GetResourceReadmeFile(w http.ResponseWriter, r *http.Request) {
 	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		api.Log.Error(err)
+		apierror.Write(w, r, errInvalidResourceID)
+		return
 	}
 	githubDetails := models.GetResourceGithubDetails(resourceID)
 	if githubDetails.ReadmePath == "" {
-		json.NewEncoder(w).Encode("noreadme")
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Resource has no README file"))
 		return
 	}
+	api.writeGithubFile(w, r, githubDetails, githubDetails.ReadmePath)
+}
+
+// writeGithubFile writes a file of the repository of a resource as is
+func (api *Api) writeGithubFile(w http.ResponseWriter, r *http.Request, githubDetails models.ResourceGithubResponse, path string) {
 	gh := api.app.GitHub().Client
-	desc, err := polling.GetFileContent(context.Background(), gh, githubDetails.Owner, githubDetails.RepositoryName, githubDetails.ReadmePath, nil)
+	desc, err := polling.GetFileContent(context.Background(), gh, githubDetails.Owner, githubDetails.RepositoryName, path, nil)
 	if err != nil {
 		api.Log.Error(err)
+		apierror.Write(w, r, errGithubUnavailable)
+		return
 	}
 	content, err := desc.GetContent()
 	if err != nil {
 		api.Log.Error(err)
+		apierror.Write(w, r, errGithubUnavailable)
+		return
 	}
 	w.Write([]byte(content))
 }
@@ -118,12 +259,71 @@ func (api *Api) GetResourceReadmeFile(w http.ResponseWriter, r *http.Request) {
 // UpdateRating will add a new rating
 func (api *Api) UpdateRating(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	ratingRequestBody := AddRatingsRequest{}
-	err := json.NewDecoder(r.Body).Decode(&ratingRequestBody)
+	ratingRequestBody, err := decodeRating(r)
 	if err != nil {
-		api.Log.Error(err)
+		apierror.Write(w, r, err)
+		return
+	}
+	result, err := models.UpdateRating(ratingRequestBody.UserID, ratingRequestBody.ResourceID, ratingRequestBody.Stars, ratingRequestBody.PrevStars)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Use POST method to add a new rating"))
+		return
 	}
-	json.NewEncoder(w).Encode(models.UpdateRating(ratingRequestBody.UserID, ratingRequestBody.ResourceID, ratingRequestBody.Stars, ratingRequestBody.PrevStars))
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to update the rating"))
+		return
+	}
+	api.recordRating(r, models.AuditRatingUpdate, ratingRequestBody)
+	json.NewEncoder(w).Encode(result)
+}
+
+// actingUser returns the user of the token of a request made to do action.
+// The user_id of the body is optional, it must be the user of the token
+// when it is set
+func actingUser(r *http.Request, bodyUserID int, action string) (int, error) {
+	userID, ok := authentication.UserID(r)
+	if !ok {
+		return 0, apierror.New(apierror.Unauthorized, "Sign in to %s", action)
+	}
+	if bodyUserID != 0 && bodyUserID != userID {
+		return 0, apierror.New(apierror.Forbidden, "The user_id %d is not the signed in user", bodyUserID)
+	}
+	return userID, nil
+}
+
+// decodeRating reads the rating of a request by the user of its token, the
+// resource is taken from the path when it is in it and must not be deleted
+func decodeRating(r *http.Request) (AddRatingsRequest, error) {
+	rating := AddRatingsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&rating); err != nil {
+		return rating, errInvalidBody
+	}
+	userID, err := actingUser(r, rating.UserID, "rate a resource")
+	if err != nil {
+		return rating, err
+	}
+	rating.UserID = userID
+	if id, ok := mux.Vars(r)["id"]; ok {
+		resourceID, err := strconv.Atoi(id)
+		if err != nil {
+			return rating, errInvalidResourceID
+		}
+		rating.ResourceID = resourceID
+	}
+	if err := models.CheckResource(rating.ResourceID); err != nil {
+		return rating, resourceError(err)
+	}
+	return rating, nil
+}
+
+// recordRating records a rating change of a user in the audit log
+func (api *Api) recordRating(r *http.Request, action string, rating AddRatingsRequest) {
+	event := audit.Event(r, action, models.ResourceTarget, rating.ResourceID)
+	if action == models.AuditRatingUpdate {
+		event.Before = models.AuditSummary(map[string]interface{}{"user_id": rating.UserID, "stars": rating.PrevStars})
+	}
+	event.After = models.AuditSummary(map[string]interface{}{"user_id": rating.UserID, "stars": rating.Stars})
+	audit.Record(event)
 }
 
 // GetRatingDetails returns rating details of a task
@@ -131,7 +331,12 @@ func (api *Api) GetRatingDetails(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		api.Log.Error(err)
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	if err := models.CheckResource(resourceID); err != nil {
+		apierror.Write(w, r, resourceError(err))
+		return
 	}
 	json.NewEncoder(w).Encode(models.GetRatingDetialsByResourceID(resourceID))
 }
@@ -139,28 +344,97 @@ func (api *Api) GetRatingDetails(w http.ResponseWriter, r *http.Request) {
 // AddRating add's a new rating
 func (api *Api) AddRating(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	ratingRequestBody := AddRatingsRequest{}
-	err := json.NewDecoder(r.Body).Decode(&ratingRequestBody)
+	ratingRequestBody, err := decodeRating(r)
 	if err != nil {
-		api.Log.Error(err)
+		apierror.Write(w, r, err)
+		return
+	}
+	result, err := models.AddRating(ratingRequestBody.UserID, ratingRequestBody.ResourceID, ratingRequestBody.Stars, ratingRequestBody.PrevStars)
+	if err == models.ErrRatingExists {
+		apierror.Write(w, r, apierror.New(apierror.Conflict, "%s", err))
+		return
+	}
+	if err == models.ErrUnknownRater {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "%s", err))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to add the rating"))
+		return
 	}
-	json.NewEncoder(w).Encode(models.AddRating(ratingRequestBody.UserID, ratingRequestBody.ResourceID, ratingRequestBody.Stars, ratingRequestBody.PrevStars))
+	api.recordRating(r, models.AuditRatingAdd, ratingRequestBody)
+	json.NewEncoder(w).Encode(result)
 }
 
-// Upload a new task/pipeline
+// Upload a new task/pipeline for the user of the request
 func (api *Api) Upload(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	uploadRequestBody := upload.NewUploadRequestObject{}
 	err := json.NewDecoder(r.Body).Decode(&uploadRequestBody)
 	if err != nil {
-		api.Log.Error(err)
+		apierror.Write(w, r, errInvalidBody)
+		return
+	}
+	uploadRequestBody.UserID, err = actingUser(r, uploadRequestBody.UserID, "upload a resource")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
 	uploader := upload.New(api.app)
+	var result map[string]interface{}
 	if uploadRequestBody.Type == "task" {
-		json.NewEncoder(w).Encode(uploader.NewUpload(uploadRequestBody.Name, uploadRequestBody.Description, uploadRequestBody.Type, uploadRequestBody.Tags, uploadRequestBody.Github, uploadRequestBody.UserID))
+		result, err = uploader.NewUpload(uploadRequestBody.Name, uploadRequestBody.Description, uploadRequestBody.Type, uploadRequestBody.Tags, uploadRequestBody.Github, uploadRequestBody.UserID)
 	} else if uploadRequestBody.Type == "pipeline" {
-		json.NewEncoder(w).Encode(uploader.NewUploadPipeline(uploadRequestBody.Name, uploadRequestBody.Description, uploadRequestBody.Type, uploadRequestBody.Tags, uploadRequestBody.Github, uploadRequestBody.UserID))
+		result, err = uploader.NewUploadPipeline(uploadRequestBody.Name, uploadRequestBody.Description, uploadRequestBody.Type, uploadRequestBody.Tags, uploadRequestBody.Github, uploadRequestBody.UserID)
+	} else {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid resource type %q, expected task or pipeline", uploadRequestBody.Type))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	event := audit.Event(r, models.AuditUpload, models.ResourceTarget, result["resource_id"].(int))
+	event.After = models.AuditSummary(map[string]interface{}{
+		"name":    uploadRequestBody.Name,
+		"type":    uploadRequestBody.Type,
+		"github":  uploadRequestBody.Github,
+		"user_id": uploadRequestBody.UserID,
+	})
+	audit.Record(event)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ImportBundle registers the task or pipeline of a Tekton bundle pulled
+// from the configured registry, for the user of the request
+func (api *Api) ImportBundle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	userID, ok := authentication.UserID(r)
+	if !ok {
+		apierror.Write(w, r, apierror.New(apierror.Unauthorized, "Sign in to import a bundle"))
+		return
+	}
+	req := upload.BundleImportRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Reference == "" {
+		apierror.Write(w, r, errInvalidBody)
+		return
+	}
+	result, err := upload.New(api.app).ImportBundle(req, userID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
+	// a bundle imported again adds no version
+	if _, ok := result["diagnostics"]; ok {
+		event := audit.Event(r, models.AuditImport, models.ResourceTarget, result["resource_id"].(int))
+		event.After = models.AuditSummary(map[string]interface{}{
+			"reference":  req.Reference,
+			"digest":     result["digest"],
+			"version_id": result["version_id"],
+		})
+		audit.Record(event)
+	}
+	json.NewEncoder(w).Encode(result)
 }
 
 // GetPrevStars will return the previous rating
@@ -169,10 +443,37 @@ func (api *Api) GetPrevStars(w http.ResponseWriter, r *http.Request) {
 	previousStarRequestBody := models.PrevStarRequest{}
 	err := json.NewDecoder(r.Body).Decode(&previousStarRequestBody)
 	if err != nil {
-		api.Log.Error(err)
+		apierror.Write(w, r, errInvalidBody)
+		return
+	}
+	if err := models.CheckResource(previousStarRequestBody.ResourceID); err != nil {
+		apierror.Write(w, r, resourceError(err))
+		return
 	}
 	json.NewEncoder(w).Encode(models.GetUserRating(previousStarRequestBody.UserID, previousStarRequestBody.ResourceID))
+}
 
+// GetUserRating returns the rating of a resource by a user, a resource the
+// user did not rate has no stars
+func (api *Api) GetUserRating(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	userID, err := strconv.Atoi(mux.Vars(r)["user"])
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid User ID"))
+		return
+	}
+	if err := models.CheckResource(resourceID); err != nil {
+		apierror.Write(w, r, resourceError(err))
+		return
+	}
+	rating := models.GetUserRating(userID, resourceID)
+	rating.UserID, rating.ResourceID = userID, resourceID
+	json.NewEncoder(w).Encode(rating)
 }
 
 func ghOAuthURLForCode(code string) string {
@@ -188,7 +489,8 @@ func (api *Api) GithubAuth(w http.ResponseWriter, r *http.Request) {
 
 	token := Code{}
 	if err := json.NewDecoder(r.Body).Decode(&token); err != nil {
-		api.Log.Error(err)
+		apierror.Write(w, r, errInvalidBody)
+		return
 	}
 	api.Log.Info("Code", token.Token)
 
@@ -205,13 +507,22 @@ func (api *Api) GithubAuth(w http.ResponseWriter, r *http.Request) {
 	httpClient := http.Client{}
 	res, err := httpClient.Do(req)
 	if err != nil {
-		println(os.Stdout, "could not send HTTP request: %v", err)
+		api.Log.Errorf("could not send HTTP request: %v", err)
+		apierror.Write(w, r, apierror.New(apierror.Upstream, "Unable to reach GitHub"))
+		return
 	}
+	defer res.Body.Close()
 
 	// Parse the request body into the `OAuthAccessResponse` struct
 	var t OAuthAccessResponse
 	if err := json.NewDecoder(res.Body).Decode(&t); err != nil {
-		fmt.Fprintf(os.Stdout, "could not parse JSON response: %v", err)
+		api.Log.Errorf("could not parse JSON response: %v", err)
+		apierror.Write(w, r, apierror.New(apierror.Upstream, "Invalid response from GitHub"))
+		return
+	}
+	if t.AccessToken == "" {
+		apierror.Write(w, r, apierror.New(apierror.Unauthorized, "Invalid GitHub code"))
+		return
 	}
 	api.Log.Info("Access Token", t.AccessToken)
 	username, id := api.getUserDetails(t.AccessToken)
@@ -219,6 +530,8 @@ func (api *Api) GithubAuth(w http.ResponseWriter, r *http.Request) {
 	authToken, err := authentication.GenerateJWT(int(id))
 	if err != nil {
 		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to sign in"))
+		return
 	}
 
 	// Add user if doesn't exist
@@ -245,6 +558,11 @@ func (api *Api) GithubAuth(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	event := audit.Event(r, models.AuditLogin, models.UserTarget, int(id))
+	event.Actor = audit.User(int(id))
+	event.After = models.AuditSummary(map[string]interface{}{"login": username, "new_user": !exists})
+	audit.Record(event)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{"token": authToken, "user_id": int(id)})
 }
@@ -282,24 +600,250 @@ func (api *Api) GetAllResourcesByUserHandler(w http.ResponseWriter, r *http.Requ
 	w.Header().Set("Content-Type", "application/json")
 	userID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		json.NewEncoder(w).Encode(map[string]interface{}{"status": false, "message": "Invalid User ID"})
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid User ID"))
+		return
 	}
 	json.NewEncoder(w).Encode(models.GetAllResourcesByUser(userID))
 }
 
-// DeleteResourceHandler handles resource deletion
+// GetDeletedResourcesByUser lists the deleted resources of a user which can
+// still be restored, to the user or an admin
+func (api *Api) GetDeletedResourcesByUser(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid User ID"))
+		return
+	}
+	if !authentication.IsAdmin(r) {
+		caller, ok := authentication.UserID(r)
+		if !ok {
+			apierror.Write(w, r, apierror.New(apierror.Unauthorized, "Only the user or an admin can list the deleted resources"))
+			return
+		}
+		if caller != userID {
+			apierror.Write(w, r, apierror.New(apierror.Forbidden, "Only the user or an admin can list the deleted resources"))
+			return
+		}
+	}
+	window := purge.RestoreWindow()
+	resources, err := models.GetDeletedResourcesByUser(userID, time.Now().Add(-window))
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to list the deleted resources"))
+		return
+	}
+	response := []DeletedResource{}
+	for _, resource := range resources {
+		response = append(response, DeletedResource{DeletedResource: resource, RestoreUntil: resource.DeletedAt.Add(window)})
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// authorizeOwnerOrAdmin returns an error unless the request is from the admin
+// or from the user who uploaded the resource
+func authorizeOwnerOrAdmin(r *http.Request, resourceID int, action string) error {
+	if authentication.IsAdmin(r) {
+		return nil
+	}
+	userID, ok := authentication.UserID(r)
+	if !ok {
+		return apierror.New(apierror.Unauthorized, "Only the owner or an admin can %s a resource", action)
+	}
+	if !models.IsResourceOwner(userID, resourceID) {
+		return apierror.New(apierror.Forbidden, "Only the owner or an admin can %s a resource", action)
+	}
+	return nil
+}
+
+// DeleteResourceHandler hides a resource, it can be restored by its owner or
+// an admin until it is purged
 func (api *Api) DeleteResourceHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	if err := authorizeOwnerOrAdmin(r, resourceID, "delete"); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = models.DeleteResource(resourceID, audit.Event(r, models.AuditDelete, models.ResourceTarget, resourceID))
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, errResourceNotFound)
+		return
+	}
+	if err != nil {
+		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to delete the resource"))
+		return
+	}
+	restoreUntil := time.Now().Add(purge.RestoreWindow())
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": true, "message": "Successfully Deleted", "restore_until": restoreUntil})
+}
+
+// RestoreResource brings back a deleted resource within the restore window
+func (api *Api) RestoreResource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	if err := authorizeOwnerOrAdmin(r, resourceID, "restore"); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	event := audit.Event(r, models.AuditRestore, models.ResourceTarget, resourceID)
+	err = models.RestoreResource(resourceID, event, time.Now().Add(-purge.RestoreWindow()))
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "No deleted resource to restore"))
+		return
+	}
+	if err != nil {
+		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to restore the resource"))
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": true, "message": "Successfully Restored"})
+}
+
+// DeprecateResource marks a resource as deprecated with a reason and
+// optionally the resource or version superseding it
+func (api *Api) DeprecateResource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	if err := authorizeOwnerOrAdmin(r, resourceID, "deprecate"); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	request := DeprecationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		apierror.Write(w, r, errInvalidBody)
+		return
+	}
+	if strings.TrimSpace(request.Reason) == "" {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "A reason is required"))
+		return
+	}
+	if models.GetResourceByID(resourceID).ID == 0 {
+		apierror.Write(w, r, errResourceNotFound)
+		return
+	}
+	deprecation := models.ResourceDeprecation{
+		ResourceID:          resourceID,
+		Reason:              request.Reason,
+		SupersededByVersion: request.SupersededByVersion,
+	}
+	// the version superseding the resource belongs to the replacement
+	// resource if there is one, to the resource itself otherwise
+	versionOf := resourceID
+	if request.SupersededBy != 0 {
+		if request.SupersededBy == resourceID || models.GetResourceByID(request.SupersededBy).ID == 0 {
+			apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid superseding resource"))
+			return
+		}
+		deprecation.SupersededBy = &request.SupersededBy
+		versionOf = request.SupersededBy
+	}
+	if request.SupersededByVersion != "" {
+		if _, err := models.FindResourceVersion(versionOf, request.SupersededByVersion); err != nil {
+			apierror.Write(w, r, apierror.New(apierror.NotFound, "Superseding version not found"))
+			return
+		}
+	}
+	previous, err := models.GetResourceDeprecation(resourceID)
 	if err != nil {
 		api.Log.Error(err)
 	}
-	err = models.DeleteResource(resourceID)
+	if err := models.DeprecateResource(&deprecation); err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to deprecate the resource"))
+		return
+	}
+	event := audit.Event(r, models.AuditDeprecate, models.ResourceTarget, resourceID)
+	event.Before = deprecationSummary(previous)
+	event.After = deprecationSummary(&deprecation)
+	audit.Record(event)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": true, "message": "Resource deprecated", "deprecation": deprecation})
+}
+
+// deprecationSummary summarises a deprecation for the audit log, a resource
+// which is not deprecated has no summary
+func deprecationSummary(d *models.ResourceDeprecation) string {
+	if d == nil {
+		return ""
+	}
+	summary := map[string]interface{}{"reason": d.Reason}
+	if d.SupersededBy != nil {
+		summary["superseded_by"] = *d.SupersededBy
+	}
+	if d.SupersededByVersion != "" {
+		summary["superseded_by_version"] = d.SupersededByVersion
+	}
+	return models.AuditSummary(summary)
+}
+
+// UndeprecateResource removes the deprecation of a resource
+func (api *Api) UndeprecateResource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	if err := authorizeOwnerOrAdmin(r, resourceID, "undeprecate"); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	previous, err := models.GetResourceDeprecation(resourceID)
 	if err != nil {
 		api.Log.Error(err)
-		json.NewEncoder(w).Encode(map[string]interface{}{"status": true, "message": err})
 	}
-	json.NewEncoder(w).Encode(map[string]interface{}{"status": true, "message": "Successfully Deleted"})
+	if err := models.UndeprecateResource(resourceID); err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to undeprecate the resource"))
+		return
+	}
+	event := audit.Event(r, models.AuditUndeprecate, models.ResourceTarget, resourceID)
+	event.Before = deprecationSummary(previous)
+	audit.Record(event)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": true, "message": "Resource is no longer deprecated"})
+}
+
+// VerifyResource changes whether a resource is verified
+func (api *Api) VerifyResource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !authentication.IsAdmin(r) {
+		apierror.Write(w, r, adminRequired(r))
+		return
+	}
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	request := VerificationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		apierror.Write(w, r, errInvalidBody)
+		return
+	}
+	previous, err := models.SetResourceVerified(resourceID, request.Verified)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, errResourceNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to change the verification"))
+		return
+	}
+	event := audit.Event(r, models.AuditVerify, models.ResourceTarget, resourceID)
+	event.Before = models.AuditSummary(map[string]interface{}{"verified": previous})
+	event.After = models.AuditSummary(map[string]interface{}{"verified": request.Verified})
+	audit.Record(event)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": true, "message": "Verification updated", "verified": request.Verified})
 }
 
 // GetResourceLinksHandler will return raw github links
@@ -307,8 +851,669 @@ func (api *Api) GetResourceLinksHandler(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		api.Log.Error(err)
+		apierror.Write(w, r, errInvalidResourceID)
+		return
 	}
 	links := models.GetResourceRawLinks(resourceID)
 	json.NewEncoder(w).Encode(links)
 }
+
+// GetResourceDependencies returns the graph of tasks used by a pipeline
+func (api *Api) GetResourceDependencies(w http.ResponseWriter, r *http.Request) {
+	api.writeDependencyGraph(w, r, false)
+}
+
+// GetResourceDependents returns the graph of pipelines using a task
+func (api *Api) GetResourceDependents(w http.ResponseWriter, r *http.Request) {
+	api.writeDependencyGraph(w, r, true)
+}
+
+func (api *Api) writeDependencyGraph(w http.ResponseWriter, r *http.Request, dependents bool) {
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	transitive, _ := strconv.ParseBool(r.FormValue("transitive"))
+	g, err := models.GetDependencyGraph(resourceID, dependents, transitive)
+	if err != nil {
+		apierror.Write(w, r, resourceError(err))
+		return
+	}
+	switch r.FormValue("format") {
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		w.Write([]byte(g.DOT()))
+	case "mermaid":
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(g.Mermaid()))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(g)
+	}
+}
+
+// GetResourceBundle streams a resource along with the tasks it depends on
+// as a single archive
+func (api *Api) GetResourceBundle(w http.ResponseWriter, r *http.Request) {
+	api.writeBundle(w, r, bundle.Zip)
+}
+
+// InstallResource serves the YAML or bundle of a resource for installation
+// and counts it as a download once it is written
+func (api *Api) InstallResource(w http.ResponseWriter, r *http.Request) {
+	b, version := api.writeBundle(w, r, bundle.YAML)
+	if b == nil {
+		return
+	}
+	api.downloads.Track(downloads.Event{
+		ResourceID: b.Manifest.ResourceID,
+		Version:    version,
+		Client:     downloads.ClientType(r),
+		ClientID:   downloads.ClientID(r),
+	})
+}
+
+// writeBundle writes the bundle in the requested format and returns it along
+// with the name of its version, the stored version given by the version
+// query parameter is bundled if there is one. nil is returned if the bundle
+// could not be built or written
+func (api *Api) writeBundle(w http.ResponseWriter, r *http.Request, defaultFormat string) (*bundle.Bundle, string) {
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return nil, ""
+	}
+	format := r.FormValue("format")
+	if format == "" {
+		format = defaultFormat
+	}
+	if !bundle.IsValidFormat(format) {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid bundle format %s", format))
+		return nil, ""
+	}
+	var b *bundle.Bundle
+	var version string
+	if ref := r.FormValue("version"); ref != "" {
+		v, err := models.FindResourceVersion(resourceID, ref)
+		if err == sql.ErrNoRows {
+			apierror.Write(w, r, apierror.New(apierror.NotFound, "Version not found"))
+			return nil, ""
+		}
+		if err != nil {
+			apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch versions"))
+			return nil, ""
+		}
+		b, err = bundle.New(api.app).BuildVersion(resourceID, v)
+		version = versionName(v)
+	} else {
+		b, err = bundle.New(api.app).Build(resourceID)
+	}
+	if err != nil {
+		apierror.Write(w, r, resourceError(err))
+		return nil, ""
+	}
+	if version == "" {
+		version = b.Version()
+	}
+	api.warnDeprecated(w, resourceID, b.Manifest.Name)
+	w.Header().Set("Content-Type", bundle.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", b.FileName(format)))
+	if err := b.Write(w, format); err != nil {
+		api.Log.Error(err)
+		return nil, ""
+	}
+	return b, version
+}
+
+// warnDeprecated sets the Warning header of the responses serving a
+// deprecated resource
+func (api *Api) warnDeprecated(w http.ResponseWriter, resourceID int, name string) {
+	deprecation, err := models.GetResourceDeprecation(resourceID)
+	if err != nil {
+		api.Log.Error(err)
+		return
+	}
+	if deprecation != nil {
+		// 299 is the miscellaneous persistent warning of RFC 7234
+		w.Header().Set("Warning", fmt.Sprintf("299 - %q", deprecation.Warning(name)))
+	}
+}
+
+// maxStatsDays bounds the number of days of the statistics of a request
+const maxStatsDays = 366
+
+// GetResourceStats returns downloads and ratings of a resource over time
+func (api *Api) GetResourceStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	to := time.Now().UTC()
+	if r.FormValue("to") != "" {
+		if to, err = time.Parse(analytics.DateFormat, r.FormValue("to")); err != nil {
+			apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid to date, expected YYYY-MM-DD"))
+			return
+		}
+	}
+	from := to.AddDate(0, 0, -29)
+	if r.FormValue("from") != "" {
+		if from, err = time.Parse(analytics.DateFormat, r.FormValue("from")); err != nil {
+			apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid from date, expected YYYY-MM-DD"))
+			return
+		}
+	}
+	if from.After(to) {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "from date must not be after to date"))
+		return
+	}
+	if to.Sub(from) >= maxStatsDays*24*time.Hour {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "The range of the statistics must not exceed %d days", maxStatsDays))
+		return
+	}
+	interval := r.FormValue("interval")
+	if interval == "" {
+		interval = analytics.Day
+	}
+	if !analytics.IsValidInterval(interval) {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid interval %s", interval))
+		return
+	}
+	stats, prior, err := models.GetDailyStats(resourceID, from, to)
+	if err != nil {
+		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch statistics"))
+		return
+	}
+	json.NewEncoder(w).Encode(analytics.NewSeries(resourceID, from, to, interval, stats, prior))
+}
+
+// GetTrendingResources returns resources ranked by recent growth in downloads
+func (api *Api) GetTrendingResources(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	days, err := strconv.Atoi(r.FormValue("days"))
+	if err != nil || days <= 0 {
+		days = 7
+	}
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+	resources, err := models.GetTrendingResources(days, limit)
+	if err != nil {
+		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch trending resources"))
+		return
+	}
+	json.NewEncoder(w).Encode(resources)
+}
+
+// SearchResources returns the resources whose interface has all the given
+// params, workspaces, results and PipelineResources, each can be repeated
+// e.g. /resources/search?param=DOCKERFILE&workspace=source
+func (api *Api) SearchResources(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+	interfaceQuery := models.InterfaceQuery{
+		Params:            query["param"],
+		Workspaces:        query["workspace"],
+		Results:           query["result"],
+		Resources:         query["resource"],
+		IncludeDeprecated: includeDeprecated(r),
+	}
+	if interfaceQuery.IsEmpty() {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Provide at least one param, workspace, result or resource"))
+		return
+	}
+	resources, err := models.SearchResourcesByInterface(interfaceQuery)
+	if err != nil {
+		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to search resources"))
+		return
+	}
+	if resourceType := query.Get("type"); resourceType != "" {
+		filtered := []models.Resource{}
+		for _, resource := range resources {
+			if resource.Type == resourceType {
+				filtered = append(filtered, resource)
+			}
+		}
+		resources = filtered
+	}
+	resources, err = page(w, r, resources)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(resources)
+}
+
+// SyncResource fetches the YAML of a resource from GitHub and records it as
+// a new version when it changed
+func (api *Api) SyncResource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	if err := authorizeOwnerOrAdmin(r, resourceID, "sync"); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	uploader := upload.New(api.app)
+	result, err := uploader.Sync(resourceID)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	// a resource which is up to date has no new version
+	if versionID, ok := result["version_id"]; ok {
+		event := audit.Event(r, models.AuditSync, models.ResourceTarget, resourceID)
+		event.After = models.AuditSummary(map[string]interface{}{"version_id": versionID})
+		audit.Record(event)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// versionName names a version in a diff by its label, or its id if it has
+// no version label
+func versionName(v models.ResourceVersion) string {
+	if v.Version != "" {
+		return v.Version
+	}
+	return strconv.Itoa(v.ID)
+}
+
+// GetResourceDiff compares two versions of a resource, e.g.
+// /resource/1/diff?from=0.1&to=0.2. The latest version is used when to is
+// missing and the version before to when from is missing
+func (api *Api) GetResourceDiff(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	from, to := r.FormValue("from"), r.FormValue("to")
+
+	var toVersion models.ResourceVersion
+	if to == "" {
+		toVersion, err = models.GetLatestResourceVersion(resourceID)
+	} else {
+		toVersion, err = models.FindResourceVersion(resourceID, to)
+	}
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Version not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch versions"))
+		return
+	}
+
+	var fromVersion models.ResourceVersion
+	if from == "" {
+		fromVersion, err = models.GetPreviousResourceVersion(resourceID, toVersion.ID)
+	} else {
+		fromVersion, err = models.FindResourceVersion(resourceID, from)
+	}
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Version not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch versions"))
+		return
+	}
+
+	result, err := diff.Versions(versionName(fromVersion), fromVersion.Content, versionName(toVersion), toVersion.Content)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "%s", err))
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetResourceVersions returns the versions of a resource, oldest first
+func (api *Api) GetResourceVersions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	if resource := models.GetResourceByID(resourceID); resource.ID == 0 {
+		apierror.Write(w, r, errResourceNotFound)
+		return
+	}
+	versions, err := models.GetResourceVersions(resourceID)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch versions"))
+		return
+	}
+	json.NewEncoder(w).Encode(versions)
+}
+
+// GetResourceVersionYAML writes the YAML stored for a version of a
+// resource, given by its label or, for unlabelled versions, by its id
+func (api *Api) GetResourceVersionYAML(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	version, err := models.FindResourceVersion(resourceID, mux.Vars(r)["version"])
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Version not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch versions"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(version.Content))
+}
+
+// GetImages returns the container images used by the resources of the hub
+func (api *Api) GetImages(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	images, err := models.GetImages()
+	if err != nil {
+		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch images"))
+		return
+	}
+	json.NewEncoder(w).Encode(images)
+}
+
+// GetImageResources returns the resources using an image, e.g.
+// /images/alpine:3.9/resources. Older versions are included with ?all=true
+func (api *Api) GetImageResources(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ref, err := image.Parse(mux.Vars(r)["ref"])
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "%s", err))
+		return
+	}
+	allVersions, _ := strconv.ParseBool(r.FormValue("all"))
+	resources, err := models.GetResourcesUsingImage(ref, allVersions)
+	if err != nil {
+		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch resources"))
+		return
+	}
+	json.NewEncoder(w).Encode(resources)
+}
+
+// ImportScanReport imports a Trivy or Grype JSON report of an image. The
+// image is read from the report unless given with ?image=
+func (api *Api) ImportScanReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !authentication.IsAdmin(r) {
+		apierror.Write(w, r, adminRequired(r))
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		api.Log.Error(err)
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to read the report"))
+		return
+	}
+	report, err := scan.Parse(body)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "%s", err))
+		return
+	}
+	if ref := r.FormValue("image"); ref != "" {
+		report.Image = ref
+	}
+	scans := []models.ImageScan{}
+	for _, ref := range report.References() {
+		parsed, err := image.Parse(ref)
+		if err != nil {
+			api.Log.Error(err)
+			continue
+		}
+		scans = append(scans, models.ImageScan{
+			Reference:  parsed.String(),
+			Registry:   parsed.Registry,
+			Repository: parsed.Repository,
+			Tag:        parsed.Tag,
+			Digest:     parsed.Digest,
+			Scanner:    report.Scanner,
+			Critical:   report.Counts.Critical,
+			High:       report.Counts.High,
+			Medium:     report.Counts.Medium,
+			Low:        report.Counts.Low,
+			Unknown:    report.Counts.Unknown,
+		})
+	}
+	if len(scans) == 0 {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "The scanned image is unknown, set it with ?image="))
+		return
+	}
+	if err := models.AddImageScans(scans); err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to store the report"))
+		return
+	}
+	flagged, err := models.UpdateCriticalResources()
+	if err != nil {
+		api.Log.Error(err)
+	}
+	event := audit.Event(r, models.AuditScanImport, models.ImageTarget, 0)
+	event.After = models.AuditSummary(map[string]interface{}{"image": report.Image, "scanner": report.Scanner, "counts": report.Counts})
+	audit.Record(event)
+	json.NewEncoder(w).Encode(ScanImportResponse{Status: true, Message: "Report imported", Report: *report, CriticalResources: flagged})
+}
+
+// GetResourceVulnerabilities returns the vulnerabilities found in the images
+// of each version of a resource
+func (api *Api) GetResourceVulnerabilities(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, errInvalidResourceID)
+		return
+	}
+	vulnerabilities, err := models.GetResourceVulnerabilities(resourceID)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch vulnerabilities"))
+		return
+	}
+	json.NewEncoder(w).Encode(vulnerabilities)
+}
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditFilter reads the filters of the audit endpoints, e.g.
+// ?action=resource.delete&actor=user:42&since=2020-05-01T00:00:00Z
+func auditFilter(r *http.Request) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Action:     r.FormValue("action"),
+		Actor:      r.FormValue("actor"),
+		TargetType: r.FormValue("target_type"),
+		RequestID:  r.FormValue("request_id"),
+	}
+	ints := map[string]*int{"target_id": &filter.TargetID, "after_id": &filter.AfterID, "limit": &filter.Limit}
+	for name, value := range ints {
+		if v := r.FormValue(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return filter, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*value = n
+		}
+	}
+	times := map[string]*time.Time{"since": &filter.Since, "until": &filter.Until}
+	for name, value := range times {
+		if v := r.FormValue(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s %q, expected an RFC 3339 time", name, v)
+			}
+			*value = t
+		}
+	}
+	return filter, nil
+}
+
+// GetAuditEvents returns the events of the audit log matching the filters,
+// oldest first. Pages are fetched with after_id set to the last id
+func (api *Api) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !authentication.IsAdmin(r) {
+		apierror.Write(w, r, adminRequired(r))
+		return
+	}
+	filter, err := auditFilter(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "%s", err))
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	} else if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	events, err := models.GetAuditEvents(filter)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch audit events"))
+		return
+	}
+	json.NewEncoder(w).Encode(events)
+}
+
+// ExportAuditEvents streams the events of the audit log matching the
+// filters as JSON Lines, one event per line, for a SIEM to ingest
+func (api *Api) ExportAuditEvents(w http.ResponseWriter, r *http.Request) {
+	if !authentication.IsAdmin(r) {
+		apierror.Write(w, r, adminRequired(r))
+		return
+	}
+	filter, err := auditFilter(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "%s", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	encoder := json.NewEncoder(w)
+	err = models.EachAuditEvent(filter, func(e models.AuditEvent) error {
+		return encoder.Encode(e)
+	})
+	if err != nil {
+		// the response is already partly written
+		api.Log.Error(err)
+	}
+}
+
+// tokenUser returns the user a request is authorized for, personal tokens
+// are managed by their user only
+func tokenUser(r *http.Request) (int, error) {
+	userID, ok := authentication.UserID(r)
+	if !ok {
+		return 0, apierror.New(apierror.Unauthorized, "Sign in to manage personal tokens")
+	}
+	return userID, nil
+}
+
+// GetCurrentUser returns the user of the token of the request
+func (api *Api) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	userID, ok := authentication.UserID(r)
+	if !ok {
+		apierror.Write(w, r, apierror.New(apierror.Unauthorized, "Not signed in"))
+		return
+	}
+	users, err := models.GetUsersByIDs([]int{userID})
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch the user"))
+		return
+	}
+	user, ok := users[userID]
+	if !ok {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "User not found"))
+		return
+	}
+	json.NewEncoder(w).Encode(CurrentUser{ID: user.ID, UserName: user.UserName, FirstName: user.FirstName, LastName: user.LastName})
+}
+
+// CreateToken creates a personal token for the user of the request. The
+// token is only returned in this response, the hub keeps a hash of it
+func (api *Api) CreateToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	userID, err := tokenUser(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	request := TokenRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		apierror.Write(w, r, errInvalidBody)
+		return
+	}
+	if strings.TrimSpace(request.Name) == "" {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "A name is required for the token"))
+		return
+	}
+	token, secret, err := models.AddPersonalToken(userID, request.Name)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to create the token"))
+		return
+	}
+	event := audit.Event(r, models.AuditTokenCreate, models.TokenTarget, token.ID)
+	event.After = models.AuditSummary(map[string]interface{}{"user_id": userID, "name": token.Name})
+	audit.Record(event)
+	json.NewEncoder(w).Encode(TokenResponse{PersonalToken: token, Token: secret})
+}
+
+// GetTokens lists the personal tokens of the user of the request
+func (api *Api) GetTokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	userID, err := tokenUser(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	tokens, err := models.GetPersonalTokens(userID)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch the tokens"))
+		return
+	}
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// DeleteToken revokes a personal token of the user of the request
+func (api *Api) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	userID, err := tokenUser(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	tokenID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.BadRequest, "Invalid Token ID"))
+		return
+	}
+	err = models.DeletePersonalToken(userID, tokenID)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Token not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to revoke the token"))
+		return
+	}
+	event := audit.Event(r, models.AuditTokenRevoke, models.TokenTarget, tokenID)
+	event.Before = models.AuditSummary(map[string]interface{}{"user_id": userID})
+	audit.Record(event)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": true, "message": "Token revoked"})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/authentication"
+)
+
+// TestAuthorization checks the handlers acting for a user refuse requests
+// without a token, or for another user, before doing anything
+func TestAuthorization(t *testing.T) {
+	token, err := authentication.GenerateJWT(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &Api{}
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		vars    map[string]string
+		body    string
+		token   string
+		status  int
+	}{
+		{"upload", api.Upload, nil, `{"name":"buildah","type":"task"}`, "", http.StatusUnauthorized},
+		{"upload as another user", api.Upload, nil, `{"name":"buildah","type":"task","user_id":7}`, token, http.StatusForbidden},
+		{"rate", api.AddRating, map[string]string{"id": "1"}, `{"stars":5}`, "", http.StatusUnauthorized},
+		{"rate as another user", api.AddRating, map[string]string{"id": "1"}, `{"stars":5,"user_id":7}`, token, http.StatusForbidden},
+		{"change a rating as another user", api.UpdateRating, map[string]string{"id": "1"}, `{"stars":5,"user_id":7}`, token, http.StatusForbidden},
+		{"import a bundle", api.ImportBundle, nil, `{"reference":"registry.example.com/tekton/git-clone:0.2"}`, "", http.StatusUnauthorized},
+		{"sync", api.SyncResource, map[string]string{"id": "1"}, "", "", http.StatusUnauthorized},
+		{"deprecate", api.DeprecateResource, map[string]string{"id": "1"}, `{"reason":"old"}`, "", http.StatusUnauthorized},
+		{"list the deleted resources of another user", api.GetDeletedResourcesByUser, map[string]string{"id": "7"}, "", token, http.StatusForbidden},
+		{"whoami", api.GetCurrentUser, nil, "", "", http.StatusUnauthorized},
+		{"list the audit events", api.GetAuditEvents, nil, "", "", http.StatusUnauthorized},
+		{"list the audit events as a user", api.GetAuditEvents, nil, "", token, http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+		if tc.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		if tc.vars != nil {
+			r = mux.SetURLVars(r, tc.vars)
+		}
+		w := httptest.NewRecorder()
+		tc.handler(w, r)
+		if w.Code != tc.status {
+			t.Errorf("%s: Expected: %v , Got: %v %s", tc.name, tc.status, w.Code, w.Body)
+		}
+	}
+}
+
+func TestGetResourceStatsRange(t *testing.T) {
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/v1/resources/1/stats?from=1900-01-01&to=2020-01-01", nil), map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	(&Api{}).GetResourceStats(w, r)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "366 days") {
+		t.Errorf("Expected: %v , Got: %v %s", http.StatusBadRequest, w.Code, w.Body)
+	}
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/downloads"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/oci"
+)
+
+// defaultBundleAPIVersion is the Tekton API version of the versions stored
+// without one
+const defaultBundleAPIVersion = "v1beta1"
+
+// bundleSource serves the versions of the resources as Tekton bundles, the
+// repositories are named <catalog>/<name> or <catalog>/<kind>/<name>, e.g.
+// catalog/git-clone or tekton/task/git-clone
+type bundleSource struct {
+	api *Api
+}
+
+// Registry returns the OCI registry serving the resources as bundles, so
+// that clusters can reference them as hub.example.com/catalog/git-clone:0.2
+func (api *Api) Registry() *oci.Registry {
+	return oci.NewRegistry(bundleSource{api: api})
+}
+
+// bundleResource returns the id of the resource of a repository
+func bundleResource(repository string) (int, error) {
+	parts := strings.Split(repository, "/")
+	catalog, kind, name := parts[0], "", parts[len(parts)-1]
+	switch len(parts) {
+	case 2:
+	case 3:
+		kind = parts[1]
+	default:
+		return 0, oci.ErrNameUnknown
+	}
+	if strings.EqualFold(catalog, hubCatalog()) {
+		catalog = ""
+	}
+	resourceID, err := models.FindCatalogResource(catalog, kind, name)
+	if err == models.ErrResourceNotFound {
+		return 0, oci.ErrNameUnknown
+	}
+	return resourceID, err
+}
+
+func (s bundleSource) Tags(repository string) ([]oci.Tag, error) {
+	resourceID, err := bundleResource(repository)
+	if err != nil {
+		return nil, err
+	}
+	resource := models.GetResourceByID(resourceID)
+	versions, err := models.GetResourceVersions(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return bundleTags(resource, versions, s.api.bundles)
+}
+
+// bundleTags returns the tags of the versions of a resource, oldest first.
+// A version uploaded again moves its tag, the image of the previous upload
+// is kept as replaced so that its digest still resolves
+func bundleTags(resource models.Resource, versions []models.ResourceVersion, cache *bundleCache) ([]oci.Tag, error) {
+	tags := []oci.Tag{}
+	index := map[string]int{}
+	var latest *oci.Image
+	for _, v := range versions {
+		image, err := cache.bundle(bundleObject(resource, v))
+		if err != nil {
+			return nil, err
+		}
+		latest = image
+		tag := oci.Tag{Name: versionName(v), Image: image}
+		if !oci.ValidTag(tag.Name) {
+			continue
+		}
+		if i, ok := index[tag.Name]; ok {
+			tags[i].Replaced = true
+		}
+		index[tag.Name] = len(tags)
+		tags = append(tags, tag)
+	}
+	if latest != nil {
+		tags = append(tags, oci.Tag{Name: "latest", Image: latest})
+	}
+	return tags, nil
+}
+
+// bundleCacheSize bounds the number of bundles kept, it is above the number
+// of versions of the hub so that the cache is rarely cleared
+const bundleCacheSize = 5000
+
+// bundleCache keeps the bundles built for the versions by their content, as
+// a bundle is gzipped and the registry lists every version of a repository
+// for each of its requests
+type bundleCache struct {
+	mu      sync.Mutex
+	bundles map[string]*oci.Image
+}
+
+func newBundleCache() *bundleCache {
+	return &bundleCache{bundles: map[string]*oci.Image{}}
+}
+
+// bundle returns the bundle of an object, bundles being deterministic it is
+// only built the first time
+func (c *bundleCache) bundle(object oci.Object) (*oci.Image, error) {
+	key := oci.Digest([]byte(strings.Join([]string{object.APIVersion, object.Kind, object.Name, oci.Digest(object.Content)}, "\n")))
+	c.mu.Lock()
+	image, ok := c.bundles[key]
+	c.mu.Unlock()
+	if ok {
+		return image, nil
+	}
+	image, err := oci.NewBundle(object)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.bundles) >= bundleCacheSize {
+		c.bundles = map[string]*oci.Image{}
+	}
+	c.bundles[key] = image
+	return image, nil
+}
+
+// Pulled counts the pull of a tag as a download of its version and warns
+// about a deprecated resource
+func (s bundleSource) Pulled(w http.ResponseWriter, r *http.Request, repository string, tag oci.Tag) {
+	resourceID, err := bundleResource(repository)
+	if err != nil {
+		return
+	}
+	s.api.warnDeprecated(w, resourceID, repository[strings.LastIndex(repository, "/")+1:])
+	version := tag.Name
+	if version == "latest" {
+		latest, err := models.GetLatestResourceVersion(resourceID)
+		if err != nil {
+			return
+		}
+		version = versionName(latest)
+	}
+	s.api.downloads.Track(downloads.Event{
+		ResourceID: resourceID,
+		Version:    version,
+		Client:     downloads.ClientType(r),
+		ClientID:   downloads.ClientID(r),
+	})
+}
+
+// bundleObject returns the Tekton object of a version of a resource, the
+// API version is given without its group, e.g. v1beta1
+func bundleObject(resource models.Resource, v models.ResourceVersion) oci.Object {
+	apiVersion := v.APIVersion
+	if i := strings.LastIndex(apiVersion, "/"); i != -1 {
+		apiVersion = apiVersion[i+1:]
+	}
+	if apiVersion == "" {
+		apiVersion = defaultBundleAPIVersion
+	}
+	return oci.Object{
+		APIVersion: apiVersion,
+		Kind:       strings.ToLower(resource.Type),
+		Name:       resource.Name,
+		Content:    []byte(v.Content),
+	}
+}
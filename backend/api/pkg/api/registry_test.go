@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/oci"
+)
+
+// tagSource serves fixed tags
+type tagSource []oci.Tag
+
+func (s tagSource) Tags(string) ([]oci.Tag, error)                             { return s, nil }
+func (s tagSource) Pulled(http.ResponseWriter, *http.Request, string, oci.Tag) {}
+
+func TestBundleTags(t *testing.T) {
+	resource := models.Resource{ID: 1, Name: "git-clone", Type: "Task"}
+	versions := []models.ResourceVersion{
+		{ID: 1, Version: "0.1", Content: "kind: Task\n# 0.1\n"},
+		{ID: 2, Version: "0.2", APIVersion: "tekton.dev/v1beta1", Content: "kind: Task\n# 0.2\n"},
+		{ID: 3, Version: "0.2", APIVersion: "tekton.dev/v1beta1", Content: "kind: Task\n# 0.2 again\n"},
+	}
+	cache := newBundleCache()
+	tags, err := bundleTags(resource, versions, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := bundleTags(resource, versions, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the bundles are only built once
+	if len(again) != len(tags) || again[0].Image != tags[0].Image {
+		t.Errorf("expected the bundles to be cached")
+	}
+	old, current := tags[1].Image, tags[2].Image
+	if !tags[1].Replaced || tags[2].Replaced || tags[3].Name != "latest" || tags[3].Image != current {
+		t.Fatalf("unexpected tags %+v", tags)
+	}
+
+	r := mux.NewRouter()
+	oci.NewRegistry(tagSource(tags)).Register(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	base := server.URL + "/v2/catalog/git-clone"
+	for path, digest := range map[string]string{
+		"/manifests/0.2":                      current.Digest,
+		"/manifests/" + old.Digest:            old.Digest,
+		"/blobs/" + oci.Digest(old.Layers[0]): oci.Digest(old.Layers[0]),
+	} {
+		res, err := http.Head(base + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK || res.Header.Get("Docker-Content-Digest") != digest {
+			t.Errorf("%s: expected %s, got %d %s", path, digest, res.StatusCode, res.Header.Get("Docker-Content-Digest"))
+		}
+	}
+
+	res, err := http.Get(base + "/tags/list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	list := struct{ Tags []string }{}
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tags) != 3 || list.Tags[0] != "0.1" || list.Tags[1] != "0.2" || list.Tags[2] != "latest" {
+		t.Errorf("unexpected tags %v", list.Tags)
+	}
+}
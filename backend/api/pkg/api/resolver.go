@@ -0,0 +1,123 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/downloads"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+)
+
+// hubCatalog is the catalog name of the whole hub, "tekton" unless
+// HUB_CATALOG is set, so that the default catalog of the Tekton hub
+// resolver matches any resource
+func hubCatalog() string {
+	if catalog := os.Getenv("HUB_CATALOG"); catalog != "" {
+		return catalog
+	}
+	return "tekton"
+}
+
+// digest returns the digest of a YAML by algorithm
+func digest(content string) map[string]string {
+	sum := sha256.Sum256([]byte(content))
+	return map[string]string{"sha256": hex.EncodeToString(sum[:])}
+}
+
+// resolverResource returns the id of the resource given by the catalog, kind
+// and name of the path
+func resolverResource(r *http.Request) (int, error) {
+	vars := mux.Vars(r)
+	catalog, kind, name := vars["catalog"], strings.ToLower(vars["kind"]), vars["name"]
+	if kind != "task" && kind != "pipeline" {
+		return 0, apierror.New(apierror.BadRequest, "Invalid kind %q, expected task or pipeline", vars["kind"])
+	}
+	if strings.EqualFold(catalog, hubCatalog()) {
+		catalog = ""
+	}
+	resourceID, err := models.FindCatalogResource(catalog, kind, name)
+	if errors.Is(err, models.ErrResourceNotFound) {
+		return 0, apierror.New(apierror.NotFound, "No %s named %q in catalog %q", kind, name, vars["catalog"])
+	}
+	if err != nil {
+		return 0, apierror.New(apierror.Internal, "Unable to find the resource")
+	}
+	return resourceID, nil
+}
+
+// ResolveResource writes the YAML of a version of a resource and its
+// digest in the shape expected by the Tekton hub resolver, e.g.
+// /v1/resource/tekton/task/git-clone/0.2/yaml. The latest version is
+// served when the path has no version. It is counted as a download
+func (api *Api) ResolveResource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := resolverResource(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	vars := mux.Vars(r)
+	var version models.ResourceVersion
+	if ref, ok := vars["version"]; ok {
+		version, err = models.FindResourceVersion(resourceID, ref)
+	} else {
+		version, err = models.GetLatestResourceVersion(resourceID)
+	}
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Version not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch versions"))
+		return
+	}
+	api.warnDeprecated(w, resourceID, vars["name"])
+	api.downloads.Track(downloads.Event{
+		ResourceID: resourceID,
+		Version:    versionName(version),
+		Client:     downloads.ClientType(r),
+		ClientID:   downloads.ClientID(r),
+	})
+	json.NewEncoder(w).Encode(ResolverResponse{Data: ResolvedResource{
+		Catalog: vars["catalog"],
+		Kind:    strings.ToLower(vars["kind"]),
+		Name:    vars["name"],
+		Version: versionName(version),
+		YAML:    version.Content,
+		Digest:  digest(version.Content),
+	}})
+}
+
+// GetResolverVersions lists the versions of a resource given by its
+// catalog, kind and name, e.g. for the version constraints of a resolver
+func (api *Api) GetResolverVersions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	resourceID, err := resolverResource(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	versions, err := models.GetResourceVersions(resourceID)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(apierror.Internal, "Unable to fetch versions"))
+		return
+	}
+	vars := mux.Vars(r)
+	api.warnDeprecated(w, resourceID, vars["name"])
+	list := ResolverVersions{Catalog: vars["catalog"], Kind: strings.ToLower(vars["kind"]), Name: vars["name"], Versions: []ResolverVersion{}}
+	for _, v := range versions {
+		list.Versions = append(list.Versions, ResolverVersion{Version: versionName(v), Digest: digest(v.Content)})
+	}
+	if len(list.Versions) > 0 {
+		list.LatestVersion = &list.Versions[len(list.Versions)-1]
+	}
+	json.NewEncoder(w).Encode(ResolverVersionsResponse{Data: list})
+}
@@ -1,6 +1,14 @@
 package api
 
-// AddRatingsRequest represents request body for adding ratings
+import (
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/scan"
+)
+
+// AddRatingsRequest represents request body for adding ratings, the user is
+// the one of the token and UserID is optional
 type AddRatingsRequest struct {
 	UserID     int `json:"user_id"`
 	ResourceID int `json:"resource_id"`
@@ -17,3 +25,91 @@ type OAuthAccessResponse struct {
 type Code struct {
 	Token string `json:"token"`
 }
+
+// DeprecationRequest represents request body for deprecating a resource
+type DeprecationRequest struct {
+	Reason              string `json:"reason"`
+	SupersededBy        int    `json:"superseded_by"`
+	SupersededByVersion string `json:"superseded_by_version"`
+}
+
+// VerificationRequest represents request body for changing the verification
+// of a resource
+type VerificationRequest struct {
+	Verified bool `json:"verified"`
+}
+
+// TokenRequest represents request body for creating a personal token
+type TokenRequest struct {
+	Name string `json:"name"`
+}
+
+// TokenResponse is a created personal token along with its secret, which
+// is not shown again
+type TokenResponse struct {
+	models.PersonalToken
+	Token string `json:"token"`
+}
+
+// CurrentUser is the user a token was issued to
+type CurrentUser struct {
+	ID        int    `json:"id"`
+	UserName  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// ScanImportResponse is an imported scan report along with the number of
+// resources flagged as critical after the import
+type ScanImportResponse struct {
+	Status            bool        `json:"status"`
+	Message           string      `json:"message"`
+	Report            scan.Report `json:"report"`
+	CriticalResources int         `json:"critical_resources"`
+}
+
+// DeletedResource is a deleted resource along with the time it can be
+// restored until
+type DeletedResource struct {
+	models.DeletedResource
+	RestoreUntil time.Time `json:"restore_until"`
+}
+
+// ResolvedResource is the YAML of a version of a resource as fetched by the
+// Tekton hub resolver
+type ResolvedResource struct {
+	Catalog string `json:"catalog"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	YAML    string `json:"yaml"`
+	// Digest holds the digest of the YAML by algorithm, like the refSource
+	// of Tekton provenance
+	Digest map[string]string `json:"digest"`
+}
+
+// ResolverResponse represents response for the hub resolver
+type ResolverResponse struct {
+	Data ResolvedResource `json:"data"`
+}
+
+// ResolverVersion is a version of a resource listed for the hub resolver
+type ResolverVersion struct {
+	Version string            `json:"version"`
+	Digest  map[string]string `json:"digest"`
+}
+
+// ResolverVersions lists the versions of a resource, oldest first
+type ResolverVersions struct {
+	Catalog       string            `json:"catalog"`
+	Kind          string            `json:"kind"`
+	Name          string            `json:"name"`
+	LatestVersion *ResolverVersion  `json:"latestVersion"`
+	Versions      []ResolverVersion `json:"versions"`
+}
+
+// ResolverVersionsResponse represents response for listing versions for
+// the hub resolver
+type ResolverVersionsResponse struct {
+	Data ResolverVersions `json:"data"`
+}
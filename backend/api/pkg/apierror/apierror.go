@@ -0,0 +1,86 @@
+package apierror
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/requestid"
+)
+
+// Codes of the errors, clients branch on them rather than on the message
+const (
+	BadRequest       = "bad_request"
+	Unauthorized     = "unauthorized"
+	Forbidden        = "forbidden"
+	NotFound         = "not_found"
+	Conflict         = "conflict"
+	ValidationFailed = "validation_failed"
+	Upstream         = "upstream_error"
+	Internal         = "internal_error"
+)
+
+var statuses = map[string]int{
+	BadRequest:       http.StatusBadRequest,
+	Unauthorized:     http.StatusUnauthorized,
+	Forbidden:        http.StatusForbidden,
+	NotFound:         http.StatusNotFound,
+	Conflict:         http.StatusConflict,
+	ValidationFailed: http.StatusUnprocessableEntity,
+	Upstream:         http.StatusBadGateway,
+	Internal:         http.StatusInternalServerError,
+}
+
+// Error is the error of a failed request
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Details holds data to act on the error, e.g. validation diagnostics
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// Body is the JSON body of a failed request
+type Body struct {
+	Error *Error `json:"error"`
+}
+
+// New returns an error with a code and a message
+func New(code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// WithDetails adds details to the error
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// Status returns the HTTP status of the error
+func (e *Error) Status() int {
+	if status, ok := statuses[e.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Write writes an error with its status. Any other error than *Error is an
+// internal error which is logged but not exposed to the client
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	e, ok := err.(*Error)
+	if !ok {
+		log.Println(err)
+		e = New(Internal, "internal error")
+	}
+	// the error may be written for several requests
+	body := *e
+	body.RequestID = requestid.FromRequest(r)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Status())
+	json.NewEncoder(w).Encode(Body{&body})
+}
@@ -0,0 +1,45 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/requestid"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{New(NotFound, "resource %d doesn't exist", 1), http.StatusNotFound, NotFound},
+		{New(ValidationFailed, "invalid task").WithDetails([]string{"1:1 error"}), http.StatusUnprocessableEntity, ValidationFailed},
+		{errors.New("connection refused"), http.StatusInternalServerError, Internal},
+	}
+	for _, tc := range tests {
+		var w *httptest.ResponseRecorder
+		handler := requestid.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			Write(rw, r, tc.err)
+		}))
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != tc.status {
+			t.Errorf("%v: Status Expected: %v , Got: %v", tc.err, tc.status, w.Code)
+		}
+		var body Body
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Error.Code != tc.code || body.Error.RequestID != w.Header().Get(requestid.Header) {
+			t.Errorf("%v: unexpected body %+v", tc.err, body.Error)
+		}
+		if tc.code == Internal && body.Error.Message != "internal error" {
+			t.Errorf("Internal errors should not be exposed, got %q", body.Error.Message)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/google/go-github/github"
 	"github.com/joho/godotenv"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/policy"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/validator"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/oauth2"
@@ -18,6 +20,7 @@ type Config interface {
 	Database() *Database
 	GitHub() *GitHub
 	Logger() *zap.SugaredLogger
+	Validation() *Validation
 	Addr() string
 }
 
@@ -44,6 +47,14 @@ type GitHub struct {
 	Client        *github.Client
 }
 
+// Validation holds the checks of the uploaded resources, they are loaded
+// once at startup
+type Validation struct {
+	Validator *validator.Validator
+	// Semver is the level of the compatibility check of new versions
+	Semver policy.Level
+}
+
 func (db *Database) ConnectionString() string {
 	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -52,10 +63,11 @@ func (db *Database) ConnectionString() string {
 }
 
 type Env struct {
-	mode   EnvMode
-	logger *zap.SugaredLogger
-	db     *Database
-	gh     *GitHub
+	mode       EnvMode
+	logger     *zap.SugaredLogger
+	db         *Database
+	gh         *GitHub
+	validation *Validation
 }
 
 var _ Config = (*Env)(nil)
@@ -76,6 +88,10 @@ func (e *Env) GitHub() *GitHub {
 	return e.gh
 }
 
+func (e *Env) Validation() *Validation {
+	return e.validation
+}
+
 func (e *Env) Addr() string {
 	return ":5000"
 }
@@ -108,6 +124,9 @@ func FromEnv(deploy string) (*Env, error) {
 		if env.gh, err = initGithub(); err != nil {
 			return nil, err
 		}
+		if env.validation, err = initValidation(); err != nil {
+			return nil, err
+		}
 	}
 
 	return env, nil
@@ -180,6 +199,29 @@ func initGithub() (*GitHub, error) {
 	return gh, nil
 }
 
+// initValidation loads the configuration of the checks of the uploads, an
+// invalid configuration fails the startup as it does for the validation
+// service
+func initValidation() (*Validation, error) {
+	// LINT_CONFIG and POLICY_CONFIG point to the configuration of the
+	// validation checks, the hub defaults are used if they are not set
+	v, err := validator.FromFiles(os.Getenv("LINT_CONFIG"), os.Getenv("POLICY_CONFIG"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation config: %s", err)
+	}
+	// SEMVER_CHECK sets how a new version which does not follow semver for
+	// its changes is handled: error (default), warning or disable
+	semver := policy.Level(os.Getenv("SEMVER_CHECK"))
+	switch semver {
+	case policy.Error, policy.Warning, policy.Disable:
+	case "":
+		semver = policy.Error
+	default:
+		return nil, fmt.Errorf("invalid SEMVER_CHECK %q, expected error, warning or disable", semver)
+	}
+	return &Validation{Validator: v, Semver: semver}, nil
+}
+
 func initLogger(mode EnvMode) (*zap.SugaredLogger, error) {
 
 	var log *zap.Logger
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/authentication"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/clientip"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/requestid"
+)
+
+// Actors of requests which are not made by a known user
+const (
+	Admin     = "admin"
+	Anonymous = "anonymous"
+)
+
+// User returns the actor of a user
+func User(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+// Actor returns who made a request: the admin, the user of the token or
+// anonymous
+func Actor(r *http.Request) string {
+	if authentication.IsAdmin(r) {
+		return Admin
+	}
+	if userID, ok := authentication.UserID(r); ok {
+		return User(userID)
+	}
+	return Anonymous
+}
+
+// SourceIP returns the address of the client, X-Forwarded-For is only
+// trusted from the proxies in front of the hub
+func SourceIP(r *http.Request) string {
+	return clientip.Address(r)
+}
+
+// Event returns an event of a request on a target, the actor, request id
+// and source are taken from the request
+func Event(r *http.Request, action, targetType string, targetID int) models.AuditEvent {
+	return models.AuditEvent{
+		Action:     action,
+		Actor:      Actor(r),
+		TargetType: targetType,
+		TargetID:   targetID,
+		RequestID:  requestid.FromRequest(r),
+		SourceIP:   SourceIP(r),
+	}
+}
+
+// Record stores an event in the audit log. A failure is only logged, the
+// operation it records already happened
+func Record(event models.AuditEvent) {
+	if err := models.AddAuditEvent(&event); err != nil {
+		log.Println(err)
+	}
+}
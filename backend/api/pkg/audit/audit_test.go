@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/authentication"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/clientip"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+)
+
+func TestEvent(t *testing.T) {
+	r := httptest.NewRequest("DELETE", "/resource/1", nil)
+	r.RemoteAddr = "10.0.0.1:5000"
+	event := Event(r, models.AuditDelete, models.ResourceTarget, 1)
+	if event.Actor != Anonymous || event.SourceIP != "10.0.0.1" || event.TargetID != 1 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	token, err := authentication.GenerateJWT(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	r.Header.Set("X-Forwarded-For", "192.168.1.1, 10.0.0.1")
+	// the header is only read from a trusted proxy
+	event = Event(r, models.AuditDelete, models.ResourceTarget, 1)
+	if event.Actor != "user:42" || event.SourceIP != "10.0.0.1" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if err := clientip.SetTrustedProxies("10.0.0.0/8"); err != nil {
+		t.Fatal(err)
+	}
+	defer clientip.SetTrustedProxies("")
+	if ip := SourceIP(r); ip != "192.168.1.1" {
+		t.Errorf("SourceIP Expected: %v , Got: %v", "192.168.1.1", ip)
+	}
+
+	defer os.Unsetenv("ADMIN_TOKEN")
+	os.Setenv("ADMIN_TOKEN", "secret")
+	r.Header.Set("Authorization", "Bearer secret")
+	if actor := Actor(r); actor != Admin {
+		t.Errorf("Actor Expected: %v , Got: %v", Admin, actor)
+	}
+}
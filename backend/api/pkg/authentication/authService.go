@@ -1,10 +1,16 @@
 package authentication
 
 import (
+	"crypto/subtle"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
 )
 
 type jWTToken struct {
@@ -30,3 +36,47 @@ func GenerateJWT(userID int) (string, error) {
 	}
 	return tokenString, nil
 }
+
+// IsAdmin checks if the request is authorized with the admin token set in
+// the ADMIN_TOKEN environment variable, all requests are rejected if it is
+// not set
+func IsAdmin(r *http.Request) bool {
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+// UserID returns the id of the user a request is authorized for with a
+// token from GenerateJWT or a personal token, false is returned for a
+// missing, invalid, expired or revoked token
+func UserID(r *http.Request) (int, bool) {
+	tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if tokenString == "" {
+		return 0, false
+	}
+	if strings.HasPrefix(tokenString, models.PersonalTokenPrefix) {
+		return models.PersonalTokenUser(tokenString)
+	}
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return mySigningKey, nil
+	})
+	if err != nil || !token.Valid {
+		return 0, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	expiry, ok := claims["expiry"].(float64)
+	if !ok || time.Now().Unix() > int64(expiry) {
+		return 0, false
+	}
+	id, ok := claims["id"].(float64)
+	return int(id), ok
+}
@@ -0,0 +1,139 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+// Supported bundle formats
+const (
+	Zip   = "zip"
+	TarGz = "tar.gz"
+	YAML  = "yaml"
+)
+
+// manifestFile is kept outside of the resources directory so that
+// `kubectl apply -f <name>/resources` only picks up Tekton resources
+const manifestFile = "manifest.json"
+
+// IsValidFormat checks if the bundle can be written in the given format
+func IsValidFormat(format string) bool {
+	return format == Zip || format == TarGz || format == YAML
+}
+
+// ContentType returns the media type of a bundle format
+func ContentType(format string) string {
+	switch format {
+	case Zip:
+		return "application/zip"
+	case TarGz:
+		return "application/gzip"
+	default:
+		return "application/x-yaml"
+	}
+}
+
+// FileName returns the name of bundle file for a format
+func (b *Bundle) FileName(format string) string {
+	return b.Manifest.Name + "." + format
+}
+
+// Write streams the bundle in the requested format
+func (b *Bundle) Write(w io.Writer, format string) error {
+	switch format {
+	case Zip:
+		return b.writeZip(w)
+	case TarGz:
+		return b.writeTarGz(w)
+	case YAML:
+		return b.writeYAML(w)
+	}
+	return fmt.Errorf("unsupported bundle format %q", format)
+}
+
+func (b *Bundle) manifest() ([]byte, error) {
+	return json.MarshalIndent(b.Manifest, "", "  ")
+}
+
+func (b *Bundle) writeZip(w io.Writer) error {
+	manifest, err := b.manifest()
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	add := func(name string, content []byte) error {
+		f, err := zw.Create(path.Join(b.Manifest.Name, name))
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(content)
+		return err
+	}
+	if err := add(manifestFile, manifest); err != nil {
+		return err
+	}
+	for _, file := range b.Files() {
+		if err := add(path.Join("resources", file.Path), file.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (b *Bundle) writeTarGz(w io.Writer) error {
+	manifest, err := b.manifest()
+	if err != nil {
+		return err
+	}
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	add := func(name string, content []byte) error {
+		header := &tar.Header{
+			Name:    path.Join(b.Manifest.Name, name),
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: time.Unix(0, 0),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+	if err := add(manifestFile, manifest); err != nil {
+		return err
+	}
+	for _, file := range b.Files() {
+		if err := add(path.Join("resources", file.Path), file.Content); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// writeYAML writes all the documents as a single multi document YAML, the
+// manifest is added as a comment so the output can be piped to kubectl
+func (b *Bundle) writeYAML(w io.Writer) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Bundle of %s %s\n", b.Manifest.Type, b.Manifest.Name)
+	for _, file := range b.Files() {
+		fmt.Fprintf(&buf, "# %s/%s source=%s sha256=%s\n", file.Kind, file.Name, file.Source, file.SHA256)
+	}
+	for _, file := range b.Files() {
+		buf.WriteString("---\n")
+		buf.Write(bytes.TrimPrefix(bytes.TrimSpace(file.Content), []byte("---\n")))
+		buf.WriteString("\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
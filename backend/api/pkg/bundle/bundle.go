@@ -0,0 +1,211 @@
+package bundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-github/github"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/app"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/polling"
+)
+
+const rawGithubPrefix = "https://raw.githubusercontent.com/"
+
+// VersionLabel is the label holding the version of a Tekton resource
+const VersionLabel = "app.kubernetes.io/version"
+
+// File is a single YAML document of a bundle
+type File struct {
+	Path       string `json:"path"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	ResourceID int    `json:"resource_id,omitempty"`
+	Source     string `json:"source"`
+	Ref        string `json:"ref"`
+	GitSHA     string `json:"git_sha"`
+	SHA256     string `json:"sha256"`
+	Content    []byte `json:"-"`
+}
+
+// Manifest describes the content of a bundle
+type Manifest struct {
+	ResourceID int    `json:"resource_id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Files      []File `json:"files"`
+}
+
+// Bundle is a resource along with all the tasks it depends on
+type Bundle struct {
+	Manifest Manifest
+}
+
+// Files returns the YAML documents of the bundle
+func (b *Bundle) Files() []File {
+	return b.Manifest.Files
+}
+
+// Version returns the version of the bundled resource as found in its
+// version label
+func (b *Bundle) Version() string {
+	for _, file := range b.Files() {
+		if strings.ToLower(file.Kind) == b.Manifest.Type && file.Name == b.Manifest.Name {
+			return file.Version
+		}
+	}
+	return ""
+}
+
+type Bundler struct {
+	app app.Config
+	gh  *github.Client
+}
+
+func New(app app.Config) *Bundler {
+	return &Bundler{
+		app: app,
+		gh:  app.GitHub().Client,
+	}
+}
+
+// Build fetches the resource and its task dependencies at the refs they
+// were pinned to during upload
+func (b *Bundler) Build(resourceID int) (*Bundle, error) {
+	return b.build(resourceID, nil)
+}
+
+// BuildVersion bundles a stored version of a resource, its dependencies are
+// fetched at the refs they were pinned to
+func (b *Bundler) BuildVersion(resourceID int, version models.ResourceVersion) (*Bundle, error) {
+	file := NewFile([]byte(version.Content), path.Base(version.RawPath))
+	file.ResourceID = resourceID
+	file.Source = version.RawPath
+	if _, _, ref, _, err := ParseRawPath(version.RawPath); err == nil {
+		file.Ref = ref
+	}
+	return b.build(resourceID, file)
+}
+
+// build bundles a resource, the YAML files of the resource itself are
+// replaced by main when it is given
+func (b *Bundler) build(resourceID int, main *File) (*Bundle, error) {
+	resource := models.GetResourceByID(resourceID)
+	if resource.ID == 0 {
+		return nil, fmt.Errorf("%w: %d", models.ErrResourceNotFound, resourceID)
+	}
+	links := models.GetResourceRawLinks(resourceID)
+	if main == nil && len(links.Tasks) == 0 && len(links.Pipelines) == 0 {
+		return nil, fmt.Errorf("resource %d has no YAML files", resourceID)
+	}
+
+	dependencies, err := models.GetAllDependencies()
+	if err != nil {
+		return nil, err
+	}
+	usedBy := map[string]int{}
+	for _, d := range dependencies {
+		if d.ResourceID == resourceID {
+			usedBy[d.RawPath] = d.DependsOnID
+		}
+	}
+
+	bundle := &Bundle{Manifest: Manifest{ResourceID: resourceID, Name: resource.Name, Type: resource.Type}}
+	if main != nil {
+		bundle.Manifest.Files = append(bundle.Manifest.Files, *main)
+	}
+	seen := map[string]bool{}
+	for _, rawPath := range append(links.Pipelines, links.Tasks...) {
+		rawPath = strings.TrimSpace(rawPath)
+		if seen[rawPath] {
+			continue
+		}
+		seen[rawPath] = true
+		id, isDependency := usedBy[rawPath]
+		if main != nil && !isDependency {
+			continue
+		}
+
+		file, err := b.fetch(rawPath)
+		if err != nil {
+			return nil, err
+		}
+		file.ResourceID = resourceID
+		if isDependency {
+			file.ResourceID = id
+		}
+		bundle.Manifest.Files = append(bundle.Manifest.Files, *file)
+	}
+	return bundle, nil
+}
+
+// fetch retrieves the content of a raw GitHub link
+func (b *Bundler) fetch(rawPath string) (*File, error) {
+	owner, repositoryName, ref, filePath, err := ParseRawPath(rawPath)
+	if err != nil {
+		return nil, err
+	}
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+	desc, err := polling.GetFileContent(context.Background(), b.gh, owner, repositoryName, filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	content, err := desc.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	file := NewFile([]byte(content), path.Base(filePath))
+	file.Source = rawPath
+	file.Ref = ref
+	file.GitSHA = desc.GetSHA()
+	return file, nil
+}
+
+// NewFile creates a bundle file from the content of a YAML document, the
+// kind and name are read from the document falling back to the file name
+func NewFile(content []byte, fileName string) *File {
+	sum := sha256.Sum256(content)
+	file := &File{
+		Name:    strings.TrimSuffix(fileName, path.Ext(fileName)),
+		SHA256:  hex.EncodeToString(sum[:]),
+		Content: content,
+	}
+	var object struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name   string            `json:"name"`
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal(content, &object); err == nil {
+		file.Kind = object.Kind
+		file.Version = object.Metadata.Labels[VersionLabel]
+		if object.Metadata.Name != "" {
+			file.Name = object.Metadata.Name
+		}
+	}
+	prefix := strings.ToLower(file.Kind)
+	if prefix == "" {
+		prefix = "resource"
+	}
+	file.Path = fmt.Sprintf("%s-%s.yaml", prefix, file.Name)
+	return file
+}
+
+// ParseRawPath splits a raw GitHub link into owner, repository, ref and path
+func ParseRawPath(rawPath string) (string, string, string, string, error) {
+	if !strings.HasPrefix(rawPath, rawGithubPrefix) {
+		return "", "", "", "", fmt.Errorf("%q is not a raw GitHub link", rawPath)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(rawPath, rawGithubPrefix), "/", 4)
+	if len(parts) != 4 || parts[3] == "" {
+		return "", "", "", "", fmt.Errorf("%q is not a raw GitHub link", rawPath)
+	}
+	return parts[0], parts[1], parts[2], parts[3], nil
+}
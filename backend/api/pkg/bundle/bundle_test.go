@@ -0,0 +1,74 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const task = `apiVersion: tekton.dev/v1alpha1
+kind: Task
+metadata:
+  name: git-clone
+spec:
+  steps:
+  - name: clone
+    image: alpine/git
+`
+
+func TestParseRawPath(t *testing.T) {
+	owner, repo, ref, path, err := ParseRawPath("https://raw.githubusercontent.com/tektoncd/catalog/master/golang/build.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != "tektoncd" || repo != "catalog" || ref != "master" || path != "golang/build.yaml" {
+		t.Errorf("Unexpected result: %v %v %v %v", owner, repo, ref, path)
+	}
+	if _, _, _, _, err := ParseRawPath("https://github.com/tektoncd/catalog"); err == nil {
+		t.Errorf("Expected an error for non raw link")
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	file := NewFile([]byte(task), "clone.yaml")
+	if file.Kind != "Task" || file.Name != "git-clone" || file.Path != "task-git-clone.yaml" {
+		t.Errorf("Unexpected file: %v %v %v", file.Kind, file.Name, file.Path)
+	}
+	if len(file.SHA256) != 64 {
+		t.Errorf("SHA256 Expected length: %v , Got: %v", 64, len(file.SHA256))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	b := &Bundle{Manifest: Manifest{Name: "git-clone", Type: "task", Files: []File{*NewFile([]byte(task), "clone.yaml")}}}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf, YAML); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "---\napiVersion: tekton.dev/v1alpha1") {
+		t.Errorf("YAML bundle does not contain the task: %v", buf.String())
+	}
+
+	buf.Reset()
+	if err := b.Write(&buf, Zip); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	expected := "git-clone/manifest.json,git-clone/resources/task-git-clone.yaml"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("Zip Expected: %v , Got: %v", expected, names)
+	}
+
+	if err := b.Write(&buf, "rar"); err == nil {
+		t.Errorf("Expected an error for unsupported format")
+	}
+}
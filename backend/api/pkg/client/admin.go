@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/scan"
+)
+
+// DeprecationRequest deprecates a resource, SupersededBy and
+// SupersededByVersion name its replacement if any
+type DeprecationRequest struct {
+	Reason              string `json:"reason"`
+	SupersededBy        int    `json:"superseded_by,omitempty"`
+	SupersededByVersion string `json:"superseded_by_version,omitempty"`
+}
+
+// Deprecate deprecates a resource, by its owner or an admin
+func (c *Client) Deprecate(ctx context.Context, id int, request DeprecationRequest) (*Result, error) {
+	return c.result(ctx, http.MethodPost, resourcePath(id, "/deprecation"), nil, request)
+}
+
+// Undeprecate removes the deprecation of a resource
+func (c *Client) Undeprecate(ctx context.Context, id int) (*Result, error) {
+	return c.result(ctx, http.MethodDelete, resourcePath(id, "/deprecation"), nil, nil)
+}
+
+// SetVerified changes whether a resource is verified, for admins only
+func (c *Client) SetVerified(ctx context.Context, id int, verified bool) (*Result, error) {
+	body := map[string]bool{"verified": verified}
+	return c.result(ctx, http.MethodPut, resourcePath(id, "/verification"), nil, body)
+}
+
+// ScanResult is an imported scan report
+type ScanResult struct {
+	Status  bool        `json:"status"`
+	Message string      `json:"message"`
+	Report  scan.Report `json:"report"`
+	// CriticalResources is the number of resources flagged by the import
+	CriticalResources int `json:"critical_resources"`
+}
+
+// ImportScan imports a Trivy or Grype JSON report, image is the scanned
+// image when the report does not tell it. For admins only.
+func (c *Client) ImportScan(ctx context.Context, report io.Reader, image string) (*ScanResult, error) {
+	query := url.Values{}
+	set(query, "image", image)
+	result := &ScanResult{}
+	if _, err := c.do(ctx, http.MethodPost, "/scans", query, report, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AuditFilter selects the events of the audit log, zero values match any
+// event
+type AuditFilter struct {
+	Action     string
+	Actor      string
+	TargetType string
+	TargetID   int
+	RequestID  string
+	Since      time.Time
+	Until      time.Time
+	// AfterID is the id of the last event of the previous page, Limit is
+	// the default of the hub when zero
+	AfterID int
+	Limit   int
+}
+
+func (f AuditFilter) values() url.Values {
+	v := url.Values{}
+	set(v, "action", f.Action)
+	set(v, "actor", f.Actor)
+	set(v, "target_type", f.TargetType)
+	set(v, "request_id", f.RequestID)
+	for name, n := range map[string]int{"target_id": f.TargetID, "after_id": f.AfterID, "limit": f.Limit} {
+		if n > 0 {
+			v.Set(name, strconv.Itoa(n))
+		}
+	}
+	for name, t := range map[string]time.Time{"since": f.Since, "until": f.Until} {
+		if !t.IsZero() {
+			v.Set(name, t.Format(time.RFC3339))
+		}
+	}
+	return v
+}
+
+// AuditEvents returns a page of the events of the audit log, oldest first.
+// The next page is fetched with AfterID set to the id of the last event.
+func (c *Client) AuditEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	events := []AuditEvent{}
+	_, err := c.do(ctx, http.MethodGet, "/audit/events", filter.values(), nil, &events)
+	return events, err
+}
+
+// ExportAuditEvents returns all the events of the audit log matching the
+// filter as JSON Lines, the caller closes the reader
+func (c *Client) ExportAuditEvents(ctx context.Context, filter AuditFilter) (io.ReadCloser, error) {
+	res, err := c.send(ctx, http.MethodGet, "/audit/events/export", filter.values(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
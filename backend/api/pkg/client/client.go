@@ -0,0 +1,160 @@
+// Package client is a Go client of the v1 API of the hub
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultPageSize is the number of resources fetched per request when
+// walking all the pages of a list
+const DefaultPageSize = 100
+
+// Client calls the v1 API of a hub
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	pageSize   int
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken authenticates the requests with the JWT or a personal token of
+// a user, or with the admin token
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient sends the requests with an HTTP client other than
+// http.DefaultClient, e.g. to set a timeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithPageSize sets the number of resources fetched per request when
+// walking all the pages of a list
+func WithPageSize(size int) Option {
+	return func(c *Client) {
+		c.pageSize = size
+	}
+}
+
+// New returns a client of the hub at baseURL, e.g. https://hub.example.com.
+// The /v1 prefix of the routes is added by the client.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid hub URL %q, expected e.g. https://hub.example.com", baseURL)
+	}
+	c := &Client{baseURL: u, httpClient: http.DefaultClient, pageSize: DefaultPageSize}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.pageSize <= 0 {
+		c.pageSize = DefaultPageSize
+	}
+	return c, nil
+}
+
+// Token returns the token the requests are authenticated with
+func (c *Client) Token() string {
+	return c.token
+}
+
+// newRequest returns a request on a v1 path, the body is encoded as JSON
+// unless it is a reader
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	// the segments of path are escaped by the callers
+	u := c.baseURL.String() + "/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader, contentType = b, "application/octet-stream"
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		reader, contentType = bytes.NewReader(data), "application/json"
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// send sends a request and returns its response, the responses of failed
+// requests are returned as *Error
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res, nil
+	}
+	defer res.Body.Close()
+	return nil, decodeError(res)
+}
+
+// do sends a request and decodes its JSON response in out, unless out is nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) (http.Header, error) {
+	res, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if out == nil {
+		io.Copy(ioutil.Discard, res.Body)
+		return res.Header, nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("invalid response of %s %s: %v", method, path, err)
+	}
+	return res.Header, nil
+}
+
+// text sends a GET request and returns its response as is
+func (c *Client) text(ctx context.Context, path string, query url.Values) (string, error) {
+	res, err := c.send(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	return string(data), err
+}
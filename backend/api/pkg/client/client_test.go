@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
+)
+
+// hub serves a fake v1 API of 5 resources
+func hub(t *testing.T) *httptest.Server {
+	resources := []Resource{}
+	for i := 1; i <= 5; i++ {
+		resources = append(resources, Resource{ID: i, Name: "task-" + strconv.Itoa(i), Type: "task"})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/resources", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("type") != "task" || r.URL.Query()["tag"][1] != "cli" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+		page := resources[offset:]
+		if limit > 0 && limit < len(page) {
+			page = page[:limit]
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(resources)))
+		json.NewEncoder(w).Encode(page)
+	})
+	mux.HandleFunc("/v1/resources/1/versions/0.2", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("kind: Task\n"))
+	})
+	mux.HandleFunc("/v1/resources/1/bundle", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="task-1.zip"`)
+		w.Header().Set("Warning", `299 - "task-1 is deprecated"`)
+		w.Write([]byte("zip"))
+	})
+	mux.HandleFunc("/v1/resources/9", func(w http.ResponseWriter, r *http.Request) {
+		apierror.Write(w, r, apierror.New(apierror.NotFound, "Resource doesn't exist"))
+	})
+	mux.HandleFunc("/v1/tokens", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer jwt" {
+			apierror.Write(w, r, apierror.New(apierror.Unauthorized, "Sign in to manage personal tokens"))
+			return
+		}
+		request := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&request)
+		json.NewEncoder(w).Encode(Token{ID: 1, UserID: 42, Name: request["name"], Token: "hub_secret"})
+	})
+	mux.HandleFunc("/v1/images", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestResources(t *testing.T) {
+	server := hub(t)
+	defer server.Close()
+	c, err := New(server.URL+"/", WithPageSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	opts := ListOptions{Type: "task", Tags: []string{"build", "cli"}, Limit: 2, Offset: 1}
+
+	page, err := c.ListResources(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 5 || len(page.Resources) != 2 || page.Resources[0].ID != 2 {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	all, err := c.AllResources(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 5 || all[4].Name != "task-5" {
+		t.Errorf("expected all the resources, got %+v", all)
+	}
+
+	yaml, err := c.VersionYAML(ctx, 1, "0.2")
+	if err != nil || yaml != "kind: Task\n" {
+		t.Errorf("unexpected YAML %q, %v", yaml, err)
+	}
+
+	f, err := c.Bundle(ctx, 1, "zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "task-1.zip" || string(f.Content) != "zip" || f.Warning != "task-1 is deprecated" {
+		t.Errorf("unexpected bundle %+v", f)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := hub(t)
+	defer server.Close()
+	c, _ := New(server.URL)
+	ctx := context.Background()
+
+	_, err := c.GetResource(ctx, 9)
+	if !IsNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if e := err.(*Error); e.StatusCode != http.StatusNotFound || e.Message != "Resource doesn't exist" {
+		t.Errorf("unexpected error %+v", e)
+	}
+
+	if _, err := c.CreateToken(ctx, "ci"); !IsUnauthorized(err) {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+
+	// errors out of the envelope, e.g. from a proxy
+	_, err = c.Images(ctx)
+	if !HasCode(err, apierror.Upstream) || err.(*Error).Message != "bad gateway" {
+		t.Errorf("expected an upstream error, got %v", err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	server := hub(t)
+	defer server.Close()
+	c, _ := New(server.URL, WithToken("jwt"))
+	token, err := c.CreateToken(context.Background(), "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Name != "ci" || token.Token != "hub_secret" {
+		t.Errorf("unexpected token %+v", token)
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
+)
+
+// Error is the error of a request the hub failed, as described by the
+// error envelope of the API
+type Error struct {
+	StatusCode int `json:"-"`
+	// Code is one of the codes of the apierror package, e.g. not_found
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Details holds data to act on the error, e.g. the diagnostics of a
+	// failed validation
+	Details   json.RawMessage `json:"details,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("hub: %s (%d %s)", e.Message, e.StatusCode, e.Code)
+	if e.RequestID != "" {
+		msg += ", request " + e.RequestID
+	}
+	return msg
+}
+
+// codes are the codes of the statuses of responses which are not in the
+// error envelope, e.g. from a proxy in front of the hub
+var codes = map[int]string{
+	http.StatusBadRequest:          apierror.BadRequest,
+	http.StatusUnauthorized:        apierror.Unauthorized,
+	http.StatusForbidden:           apierror.Forbidden,
+	http.StatusNotFound:            apierror.NotFound,
+	http.StatusConflict:            apierror.Conflict,
+	http.StatusUnprocessableEntity: apierror.ValidationFailed,
+	http.StatusBadGateway:          apierror.Upstream,
+}
+
+// decodeError reads the error of a failed response
+func decodeError(res *http.Response) error {
+	e := &Error{StatusCode: res.StatusCode}
+	data, _ := ioutil.ReadAll(res.Body)
+	body := struct {
+		Error *Error `json:"error"`
+	}{e}
+	if err := json.Unmarshal(data, &body); err != nil || e.Code == "" {
+		e.Code = codes[res.StatusCode]
+		if e.Code == "" {
+			e.Code = apierror.Internal
+		}
+		e.Message = strings.TrimSpace(string(data))
+		if e.Message == "" {
+			e.Message = http.StatusText(res.StatusCode)
+		}
+	}
+	return e
+}
+
+// HasCode tells whether err is an *Error with the given code
+func HasCode(err error, code string) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == code
+}
+
+// IsNotFound tells whether err is a missing resource, version, token, etc.
+func IsNotFound(err error) bool {
+	return HasCode(err, apierror.NotFound)
+}
+
+// IsUnauthorized tells whether err is a request without a valid token
+func IsUnauthorized(err error) bool {
+	return HasCode(err, apierror.Unauthorized)
+}
+
+// IsForbidden tells whether err is a request whose token does not allow it
+func IsForbidden(err error) bool {
+	return HasCode(err, apierror.Forbidden)
+}
+
+// IsConflict tells whether err is a request conflicting with the state of
+// the hub, e.g. rating a resource twice
+func IsConflict(err error) bool {
+	return HasCode(err, apierror.Conflict)
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type ratingRequest struct {
+	UserID    int `json:"user_id,omitempty"`
+	Stars     int `json:"stars"`
+	PrevStars int `json:"prev_stars,omitempty"`
+}
+
+// Ratings returns the ratings of a resource
+func (c *Client) Ratings(ctx context.Context, id int) (*Ratings, error) {
+	ratings := &Ratings{}
+	if _, err := c.do(ctx, http.MethodGet, resourcePath(id, "/ratings"), nil, nil, ratings); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// Rate rates a resource as the user of the token, userID is optional and
+// must be that user when it is set. A user who already rated the resource
+// gets a conflict error and changes the rating with UpdateRating
+func (c *Client) Rate(ctx context.Context, id, userID, stars int) (*RatingResult, error) {
+	return c.rating(ctx, http.MethodPost, id, ratingRequest{UserID: userID, Stars: stars})
+}
+
+// UpdateRating changes the rating of a resource by the user of the token
+// from prevStars to stars
+func (c *Client) UpdateRating(ctx context.Context, id, userID, stars, prevStars int) (*RatingResult, error) {
+	return c.rating(ctx, http.MethodPut, id, ratingRequest{UserID: userID, Stars: stars, PrevStars: prevStars})
+}
+
+func (c *Client) rating(ctx context.Context, method string, id int, rating ratingRequest) (*RatingResult, error) {
+	result := &RatingResult{}
+	if _, err := c.do(ctx, method, resourcePath(id, "/ratings"), nil, rating, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UserRating returns the rating of a resource by a user
+func (c *Client) UserRating(ctx context.Context, id, userID int) (*UserRating, error) {
+	rating := &UserRating{}
+	if _, err := c.do(ctx, http.MethodGet, resourcePath(id, fmt.Sprintf("/ratings/%d", userID)), nil, nil, rating); err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
@@ -0,0 +1,371 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/diff"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/graph"
+)
+
+// totalCountHeader holds the number of resources of a paginated list
+const totalCountHeader = "X-Total-Count"
+
+// ListOptions filters the resources of ListResources, Type and Verified
+// are all by default
+type ListOptions struct {
+	Type       string
+	Verified   string
+	Tags       []string
+	APIVersion string
+	// Limit and Offset select a page, all the resources are returned
+	// without a limit
+	Limit  int
+	Offset int
+}
+
+func (o ListOptions) values() url.Values {
+	v := url.Values{}
+	set(v, "type", o.Type)
+	set(v, "verified", o.Verified)
+	set(v, "apiVersion", o.APIVersion)
+	for _, tag := range o.Tags {
+		v.Add("tag", tag)
+	}
+	return v
+}
+
+// SearchOptions searches the resources by their interface, each of Params,
+// Workspaces, Results and Resources must all be in a resource
+type SearchOptions struct {
+	Params     []string
+	Workspaces []string
+	Results    []string
+	Resources  []string
+	Type       string
+	Deprecated bool
+	// Limit and Offset select a page, all the resources are returned
+	// without a limit
+	Limit  int
+	Offset int
+}
+
+func (o SearchOptions) values() url.Values {
+	v := url.Values{}
+	for name, values := range map[string][]string{"param": o.Params, "workspace": o.Workspaces, "result": o.Results, "resource": o.Resources} {
+		for _, value := range values {
+			v.Add(name, value)
+		}
+	}
+	set(v, "type", o.Type)
+	if o.Deprecated {
+		v.Set("deprecated", "true")
+	}
+	return v
+}
+
+// set sets a query parameter unless its value is empty
+func set(v url.Values, name, value string) {
+	if value != "" {
+		v.Set(name, value)
+	}
+}
+
+// ResourcePage is a page of a list of resources
+type ResourcePage struct {
+	Resources []Resource
+	// Total is the number of resources of all the pages
+	Total int
+}
+
+// page fetches a page of resources of a list
+func (c *Client) page(ctx context.Context, path string, query url.Values, limit, offset int) (*ResourcePage, error) {
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	p := &ResourcePage{}
+	header, err := c.do(ctx, http.MethodGet, path, query, nil, &p.Resources)
+	if err != nil {
+		return nil, err
+	}
+	p.Total = len(p.Resources)
+	if total, err := strconv.Atoi(header.Get(totalCountHeader)); err == nil {
+		p.Total = total
+	}
+	return p, nil
+}
+
+// walk fetches all the resources of a list, a page at a time
+func (c *Client) walk(ctx context.Context, path string, query url.Values) ([]Resource, error) {
+	resources := []Resource{}
+	for {
+		p, err := c.page(ctx, path, query, c.pageSize, len(resources))
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, p.Resources...)
+		if len(p.Resources) == 0 || len(resources) >= p.Total {
+			return resources, nil
+		}
+	}
+}
+
+// ListResources returns a page of the resources matching the options
+func (c *Client) ListResources(ctx context.Context, opts ListOptions) (*ResourcePage, error) {
+	return c.page(ctx, "/resources", opts.values(), opts.Limit, opts.Offset)
+}
+
+// AllResources returns all the resources matching the options, fetching
+// them a page at a time. Limit and Offset are ignored.
+func (c *Client) AllResources(ctx context.Context, opts ListOptions) ([]Resource, error) {
+	return c.walk(ctx, "/resources", opts.values())
+}
+
+// SearchResources returns a page of the resources matching the search
+func (c *Client) SearchResources(ctx context.Context, opts SearchOptions) (*ResourcePage, error) {
+	return c.page(ctx, "/resources/search", opts.values(), opts.Limit, opts.Offset)
+}
+
+// SearchAllResources returns all the resources matching the search,
+// fetching them a page at a time. Limit and Offset are ignored.
+func (c *Client) SearchAllResources(ctx context.Context, opts SearchOptions) ([]Resource, error) {
+	return c.walk(ctx, "/resources/search", opts.values())
+}
+
+// TrendingResources returns the resources ranked by the growth of their
+// downloads over the last days, zero values are the defaults of the hub
+func (c *Client) TrendingResources(ctx context.Context, days, limit int) ([]TrendingResource, error) {
+	query := url.Values{}
+	if days > 0 {
+		query.Set("days", strconv.Itoa(days))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	resources := []TrendingResource{}
+	_, err := c.do(ctx, http.MethodGet, "/resources/trending", query, nil, &resources)
+	return resources, err
+}
+
+func resourcePath(id int, sub string) string {
+	return fmt.Sprintf("/resources/%d%s", id, sub)
+}
+
+// GetResource returns a resource with the interface of its latest version
+func (c *Client) GetResource(ctx context.Context, id int) (*Resource, error) {
+	resource := &Resource{}
+	if _, err := c.do(ctx, http.MethodGet, resourcePath(id, ""), nil, nil, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// Upload adds a task or pipeline from GitHub, the diagnostics of a failed
+// validation are in the Details of the error
+func (c *Client) Upload(ctx context.Context, upload Upload) (*Result, error) {
+	return c.result(ctx, http.MethodPost, "/resources", nil, upload)
+}
+
+// DeleteResource deletes a resource, it can be restored until it is purged
+func (c *Client) DeleteResource(ctx context.Context, id int) (*Result, error) {
+	return c.result(ctx, http.MethodDelete, resourcePath(id, ""), nil, nil)
+}
+
+// RestoreResource restores a deleted resource
+func (c *Client) RestoreResource(ctx context.Context, id int) (*Result, error) {
+	return c.result(ctx, http.MethodPost, resourcePath(id, "/restore"), nil, nil)
+}
+
+// SyncResource fetches the YAML of a resource from GitHub again and
+// records it as a new version when it changed
+func (c *Client) SyncResource(ctx context.Context, id int) (*Result, error) {
+	return c.result(ctx, http.MethodPost, resourcePath(id, "/sync"), nil, nil)
+}
+
+func (c *Client) result(ctx context.Context, method, path string, query url.Values, body interface{}) (*Result, error) {
+	result := &Result{}
+	if _, err := c.do(ctx, method, path, query, body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ResourceYAML returns the YAML file of a resource as found on GitHub
+func (c *Client) ResourceYAML(ctx context.Context, id int) (string, error) {
+	return c.text(ctx, resourcePath(id, "/yaml"), nil)
+}
+
+// ResourceReadme returns the README file of a resource
+func (c *Client) ResourceReadme(ctx context.Context, id int) (string, error) {
+	return c.text(ctx, resourcePath(id, "/readme"), nil)
+}
+
+// ResourceVersions returns the versions of a resource, oldest first
+func (c *Client) ResourceVersions(ctx context.Context, id int) ([]Version, error) {
+	versions := []Version{}
+	_, err := c.do(ctx, http.MethodGet, resourcePath(id, "/versions"), nil, nil, &versions)
+	return versions, err
+}
+
+// VersionYAML returns the YAML of a version of a resource, given by its
+// label or, for unlabelled versions, by its id
+func (c *Client) VersionYAML(ctx context.Context, id int, version string) (string, error) {
+	return c.text(ctx, resourcePath(id, "/versions/"+url.PathEscape(version)), nil)
+}
+
+// ResourceLinks returns the raw GitHub links of the files of a resource
+func (c *Client) ResourceLinks(ctx context.Context, id int) (*Links, error) {
+	links := &Links{}
+	if _, err := c.do(ctx, http.MethodGet, resourcePath(id, "/links"), nil, nil, links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// Dependencies returns the graph of the tasks used by a pipeline
+func (c *Client) Dependencies(ctx context.Context, id int, transitive bool) (*graph.Graph, error) {
+	return c.graph(ctx, resourcePath(id, "/dependencies"), transitive)
+}
+
+// Dependents returns the graph of the pipelines using a task
+func (c *Client) Dependents(ctx context.Context, id int, transitive bool) (*graph.Graph, error) {
+	return c.graph(ctx, resourcePath(id, "/dependents"), transitive)
+}
+
+func (c *Client) graph(ctx context.Context, path string, transitive bool) (*graph.Graph, error) {
+	query := url.Values{"format": {"json"}}
+	if transitive {
+		query.Set("transitive", "true")
+	}
+	g := &graph.Graph{}
+	if _, err := c.do(ctx, http.MethodGet, path, query, nil, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Bundle returns a resource with the tasks it depends on, format is yaml,
+// zip or tar.gz and the default of the hub when empty
+func (c *Client) Bundle(ctx context.Context, id int, format string) (*File, error) {
+	query := url.Values{}
+	set(query, "format", format)
+	return c.file(ctx, resourcePath(id, "/bundle"), query)
+}
+
+// Install returns the bundle of a resource like Bundle and counts it as a
+// download of a version
+func (c *Client) Install(ctx context.Context, id int, format, version string) (*File, error) {
+	query := url.Values{}
+	set(query, "format", format)
+	set(query, "version", version)
+	return c.file(ctx, resourcePath(id, "/install"), query)
+}
+
+func (c *Client) file(ctx context.Context, path string, query url.Values) (*File, error) {
+	res, err := c.send(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	f := &File{ContentType: res.Header.Get("Content-Type")}
+	// the warning is quoted after its code and agent, e.g. 299 - "..."
+	if warning := res.Header.Get("Warning"); warning != "" {
+		if i := strings.Index(warning, `"`); i >= 0 {
+			if text, err := strconv.Unquote(warning[i:]); err == nil {
+				warning = text
+			}
+		}
+		f.Warning = warning
+	}
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Disposition")); err == nil {
+		f.Name = params["filename"]
+	}
+	if f.Content, err = ioutil.ReadAll(res.Body); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// StatsOptions selects the period of the stats of a resource, From and To
+// are days as YYYY-MM-DD and Interval is day, week or month
+type StatsOptions struct {
+	From     string
+	To       string
+	Interval string
+}
+
+// ResourceStats returns the downloads and ratings of a resource over time
+func (c *Client) ResourceStats(ctx context.Context, id int, opts StatsOptions) (*Stats, error) {
+	query := url.Values{}
+	set(query, "from", opts.From)
+	set(query, "to", opts.To)
+	set(query, "interval", opts.Interval)
+	stats := &Stats{}
+	if _, err := c.do(ctx, http.MethodGet, resourcePath(id, "/stats"), query, nil, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Diff compares two versions of a resource, the latest version is used
+// when to is empty and the version before to when from is empty
+func (c *Client) Diff(ctx context.Context, id int, from, to string) (*diff.Diff, error) {
+	query := url.Values{}
+	set(query, "from", from)
+	set(query, "to", to)
+	d := &diff.Diff{}
+	if _, err := c.do(ctx, http.MethodGet, resourcePath(id, "/diff"), query, nil, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Vulnerabilities returns the vulnerabilities of the images of each version
+// of a resource
+func (c *Client) Vulnerabilities(ctx context.Context, id int) ([]VersionVulnerabilities, error) {
+	vulnerabilities := []VersionVulnerabilities{}
+	_, err := c.do(ctx, http.MethodGet, resourcePath(id, "/vulnerabilities"), nil, nil, &vulnerabilities)
+	return vulnerabilities, err
+}
+
+// Tags returns the tags of the resources
+func (c *Client) Tags(ctx context.Context) ([]Tag, error) {
+	tags := []Tag{}
+	_, err := c.do(ctx, http.MethodGet, "/tags", nil, nil, &tags)
+	return tags, err
+}
+
+// Categories returns the names of the tags of each category
+func (c *Client) Categories(ctx context.Context) (map[string][]string, error) {
+	categories := map[string][]string{}
+	_, err := c.do(ctx, http.MethodGet, "/categories", nil, nil, &categories)
+	return categories, err
+}
+
+// Images returns the container images used by the resources
+func (c *Client) Images(ctx context.Context) ([]Image, error) {
+	images := []Image{}
+	_, err := c.do(ctx, http.MethodGet, "/images", nil, nil, &images)
+	return images, err
+}
+
+// ImageResources returns the latest versions of the resources using an
+// image, or all their versions with allVersions
+func (c *Client) ImageResources(ctx context.Context, ref string, allVersions bool) ([]ImageResource, error) {
+	query := url.Values{}
+	if allVersions {
+		query.Set("all", "true")
+	}
+	resources := []ImageResource{}
+	_, err := c.do(ctx, http.MethodGet, "/images/"+ref+"/resources", query, nil, &resources)
+	return resources, err
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/openapi"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/routes"
+)
+
+// operations are the operations of the API along with the types the client
+// sends and decodes, a nil body is not checked. The tokens are listed as
+// Token values without their secret, only POST /tokens documents it
+var operations = []struct {
+	method, path   string
+	body, response interface{}
+}{
+	{"GET", "/resources", nil, []Resource{}},
+	{"POST", "/resources", Upload{}, Result{}},
+	{"GET", "/resources/search", nil, []Resource{}},
+	{"GET", "/resources/trending", nil, []TrendingResource{}},
+	{"GET", "/resources/{id}", nil, Resource{}},
+	{"DELETE", "/resources/{id}", nil, Result{}},
+	{"POST", "/resources/{id}/restore", nil, Result{}},
+	{"POST", "/resources/{id}/sync", nil, Result{}},
+	{"GET", "/resources/{id}/versions", nil, []Version{}},
+	{"GET", "/resources/{id}/links", nil, Links{}},
+	{"GET", "/resources/{id}/stats", nil, Stats{}},
+	{"GET", "/resources/{id}/vulnerabilities", nil, []VersionVulnerabilities{}},
+	{"POST", "/resources/{id}/deprecation", DeprecationRequest{}, Result{}},
+	{"GET", "/resources/{id}/ratings", nil, Ratings{}},
+	{"POST", "/resources/{id}/ratings", ratingRequest{}, RatingResult{}},
+	{"PUT", "/resources/{id}/ratings", ratingRequest{}, RatingResult{}},
+	{"GET", "/resources/{id}/ratings/{user}", nil, UserRating{}},
+	{"GET", "/user", nil, User{}},
+	{"GET", "/users/{id}/resources", nil, []UserResource{}},
+	{"GET", "/users/{id}/resources/deleted", nil, []DeletedResource{}},
+	{"GET", "/tags", nil, []Tag{}},
+	{"GET", "/categories", nil, map[string][]string{}},
+	{"GET", "/images", nil, []Image{}},
+	{"GET", "/images/{ref}/resources", nil, []ImageResource{}},
+	{"POST", "/scans", nil, ScanResult{}},
+	{"POST", "/auth/github", nil, Session{}},
+	{"POST", "/tokens", nil, Token{}},
+	{"GET", "/audit/events", nil, []AuditEvent{}},
+}
+
+// TestSpec checks the types of the client against the OpenAPI document of
+// the API, each field of a client type must be documented with its type
+func TestSpec(t *testing.T) {
+	doc := routes.Spec()
+	for _, o := range operations {
+		where := o.method + " " + o.path
+		op := doc.Paths[routes.V1+o.path][strings.ToLower(o.method)]
+		if op == nil {
+			t.Errorf("%s is not in the spec", where)
+			continue
+		}
+		response := op.Responses["200"].Content["application/json"]
+		if response == nil {
+			t.Errorf("%s has no JSON response", where)
+			continue
+		}
+		checkSchema(t, doc, where, reflect.TypeOf(o.response), response.Schema)
+		if o.body != nil {
+			if op.RequestBody == nil {
+				t.Errorf("%s has no request body", where)
+				continue
+			}
+			checkSchema(t, doc, where+" body", reflect.TypeOf(o.body), op.RequestBody.Content["application/json"].Schema)
+		}
+	}
+}
+
+func checkSchema(t *testing.T, doc *openapi.Document, where string, typ reflect.Type, schema *openapi.Schema) {
+	t.Helper()
+	if schema.Ref != "" {
+		schema = doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	// values of any type, and objects of any properties, accept any field
+	if schema.Type == "" || schema.Type == "object" && schema.Properties == nil && typ.Kind() == reflect.Struct {
+		return
+	}
+	expected := ""
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		expected = "string"
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		// raw JSON may be any value
+		return
+	default:
+		switch typ.Kind() {
+		case reflect.Struct, reflect.Map:
+			expected = "object"
+		case reflect.Slice:
+			expected = "array"
+		case reflect.String:
+			expected = "string"
+		case reflect.Bool:
+			expected = "boolean"
+		case reflect.Int, reflect.Int64:
+			expected = "integer"
+		case reflect.Float64:
+			expected = "number"
+		default:
+			return
+		}
+	}
+	if schema.Type != expected {
+		t.Errorf("%s: %s is a %s in the spec", where, typ, schema.Type)
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Slice:
+		checkSchema(t, doc, where+"[]", typ.Elem(), schema.Items)
+	case reflect.Map:
+		if schema.AdditionalProperties != nil {
+			checkSchema(t, doc, where+"{}", typ.Elem(), schema.AdditionalProperties)
+		}
+	case reflect.Struct:
+		if typ == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			property, ok := schema.Properties[name]
+			if !ok {
+				t.Errorf("%s: %s.%s is not in the spec", where, typ.Name(), name)
+				continue
+			}
+			checkSchema(t, doc, where+"."+name, f.Type, property)
+		}
+	}
+}
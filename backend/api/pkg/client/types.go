@@ -0,0 +1,252 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/scan"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/spec"
+)
+
+// Resource is a task or pipeline of the hub
+type Resource struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Downloads   int      `json:"downloads"`
+	Rating      float64  `json:"rating"`
+	Github      string   `json:"github"`
+	Tags        []string `json:"tags"`
+	Verified    bool     `json:"verified"`
+	APIVersion  string   `json:"api_version"`
+	Quality     int      `json:"quality"`
+	// Critical flags resources using an image with critical vulnerabilities
+	Critical   bool       `json:"critical_vulnerabilities"`
+	Deprecated bool       `json:"deprecated"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	// Deprecation and Interface are only set by GetResource
+	Deprecation *Deprecation    `json:"deprecation,omitempty"`
+	Interface   *spec.Interface `json:"interface,omitempty"`
+}
+
+// Deprecation tells why a resource is deprecated and what to use instead
+type Deprecation struct {
+	ResourceID          int       `json:"resource_id"`
+	Reason              string    `json:"reason"`
+	SupersededBy        *int      `json:"superseded_by,omitempty"`
+	SupersededByVersion string    `json:"superseded_by_version,omitempty"`
+	DeprecatedAt        time.Time `json:"deprecated_at"`
+}
+
+// Version is a version of the YAML of a resource
+type Version struct {
+	ID         int       `json:"id"`
+	ResourceID int       `json:"resource_id"`
+	Version    string    `json:"version"`
+	APIVersion string    `json:"api_version"`
+	RawPath    string    `json:"raw_path"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TrendingResource is a resource ranked by the growth of its downloads
+type TrendingResource struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Rating    float64 `json:"rating"`
+	Downloads int     `json:"downloads"`
+	Recent    int     `json:"recent_downloads"`
+	Previous  int     `json:"previous_downloads"`
+	Growth    int     `json:"growth"`
+}
+
+// Links are the raw GitHub links of the files of a resource
+type Links struct {
+	Tasks     []string `json:"tasks"`
+	Pipelines []string `json:"pipelines"`
+}
+
+// Stats are the downloads and ratings of a resource over time
+type Stats struct {
+	ResourceID int            `json:"resource_id"`
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Interval   string         `json:"interval"`
+	Points     []StatsPoint   `json:"points"`
+	Versions   map[string]int `json:"versions"`
+}
+
+// StatsPoint is the activity of a resource in an interval
+type StatsPoint struct {
+	Date          string  `json:"date"`
+	Downloads     int     `json:"downloads"`
+	NewRatings    int     `json:"new_ratings"`
+	AverageRating float64 `json:"average_rating"`
+}
+
+// VersionVulnerabilities sums the vulnerabilities of the images of a version
+type VersionVulnerabilities struct {
+	VersionID int                    `json:"version_id"`
+	Version   string                 `json:"version"`
+	Counts    scan.Counts            `json:"counts"`
+	Images    []ImageVulnerabilities `json:"images"`
+}
+
+// ImageVulnerabilities are the vulnerabilities of the last scan of an image
+type ImageVulnerabilities struct {
+	Reference  string      `json:"reference"`
+	Scanner    string      `json:"scanner"`
+	Counts     scan.Counts `json:"counts"`
+	ImportedAt time.Time   `json:"imported_at"`
+}
+
+// Ratings counts the ratings of a resource by stars
+type Ratings struct {
+	ID         int `json:"id"`
+	ResourceID int `json:"resource_id"`
+	One        int `json:"one"`
+	Two        int `json:"two"`
+	Three      int `json:"three"`
+	Four       int `json:"four"`
+	Five       int `json:"five"`
+}
+
+// RatingResult is the rating of a resource after it was rated
+type RatingResult struct {
+	ResourceID int     `json:"resource_id"`
+	OneStar    int     `json:"one_star"`
+	TwoStar    int     `json:"two_star"`
+	ThreeStar  int     `json:"three_star"`
+	FourStar   int     `json:"four_star"`
+	FiveStar   int     `json:"five_star"`
+	Average    float64 `json:"average"`
+}
+
+// UserRating is the rating of a resource by a user, a resource the user
+// did not rate has no stars
+type UserRating struct {
+	UserID     int `json:"user_id"`
+	ResourceID int `json:"resource_id"`
+	Stars      int `json:"stars"`
+}
+
+// UserResource is a resource uploaded by a user
+type UserResource struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Rating    float64 `json:"rating"`
+	Downloads int     `json:"downloads"`
+}
+
+// DeletedResource is a deleted resource which can be restored until
+// RestoreUntil
+type DeletedResource struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	RestoreUntil time.Time `json:"restore_until"`
+}
+
+// Tag is a tag of the resources
+type Tag struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	CategoryID int    `json:"category_id"`
+}
+
+// Image is a container image used by the resources
+type Image struct {
+	Reference  string `json:"reference"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Digest     string `json:"digest"`
+	Resources  int    `json:"resources"`
+}
+
+// ImageResource is a resource version using an image
+type ImageResource struct {
+	ResourceID int    `json:"resource_id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Version    string `json:"version"`
+	Kind       string `json:"kind"`
+	Container  string `json:"container"`
+	Image      string `json:"image"`
+	Reference  string `json:"reference"`
+}
+
+// Upload is a task or pipeline to add from GitHub
+type Upload struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Type        string   `json:"type"`
+	Github      string   `json:"github"`
+	Tags        []string `json:"tags"`
+	// UserID is optional, resources are uploaded for the user of the token
+	UserID int `json:"user_id,omitempty"`
+}
+
+// Result is the outcome of a write operation. ResourceID is set by uploads
+// and VersionID by the syncs which recorded a new version.
+type Result struct {
+	Status      bool            `json:"status"`
+	Message     string          `json:"message"`
+	ResourceID  int             `json:"resource_id,omitempty"`
+	VersionID   int             `json:"version_id,omitempty"`
+	Quality     int             `json:"quality,omitempty"`
+	Diagnostics json.RawMessage `json:"diagnostics,omitempty"`
+	// RestoreUntil is set by deletes, Deprecation by deprecations
+	RestoreUntil *time.Time   `json:"restore_until,omitempty"`
+	Deprecation  *Deprecation `json:"deprecation,omitempty"`
+}
+
+// User is a user of the hub
+type User struct {
+	ID        int    `json:"id"`
+	UserName  string `json:"username"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// Session is a user signed in with GitHub
+type Session struct {
+	Token  string `json:"token"`
+	UserID int    `json:"user_id"`
+}
+
+// Token is a personal token of a user, its secret is only set when it is
+// created
+type Token struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Token      string     `json:"token,omitempty"`
+}
+
+// AuditEvent is a write operation recorded in the audit log
+type AuditEvent struct {
+	ID         int       `json:"id"`
+	Action     string    `json:"action"`
+	Actor      string    `json:"actor"`
+	TargetType string    `json:"target_type"`
+	TargetID   int       `json:"target_id"`
+	Before     string    `json:"before,omitempty"`
+	After      string    `json:"after,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	SourceIP   string    `json:"source_ip,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// File is a file served by the hub, such as a bundle
+type File struct {
+	Name        string
+	ContentType string
+	Content     []byte
+	// Warning is set for the bundles of deprecated resources
+	Warning string
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Login signs in with the code of a GitHub OAuth authorization. The
+// returned token expires, scripts rather use a personal token.
+func (c *Client) Login(ctx context.Context, code string) (*Session, error) {
+	session := &Session{}
+	body := map[string]string{"token": code}
+	if _, err := c.do(ctx, http.MethodPost, "/auth/github", nil, body, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// CurrentUser returns the user of the token of the client
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	user := &User{}
+	if _, err := c.do(ctx, http.MethodGet, "/user", nil, nil, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UserResources returns the resources uploaded by a user
+func (c *Client) UserResources(ctx context.Context, userID int) ([]UserResource, error) {
+	resources := []UserResource{}
+	_, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/users/%d/resources", userID), nil, nil, &resources)
+	return resources, err
+}
+
+// DeletedResources returns the deleted resources of a user which can still
+// be restored, only the user and admins can list them
+func (c *Client) DeletedResources(ctx context.Context, userID int) ([]DeletedResource, error) {
+	resources := []DeletedResource{}
+	_, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/users/%d/resources/deleted", userID), nil, nil, &resources)
+	return resources, err
+}
+
+// Tokens returns the personal tokens of the user of the client, without
+// their secret
+func (c *Client) Tokens(ctx context.Context) ([]Token, error) {
+	tokens := []Token{}
+	_, err := c.do(ctx, http.MethodGet, "/tokens", nil, nil, &tokens)
+	return tokens, err
+}
+
+// CreateToken creates a personal token for the user of the client, its
+// secret is only returned here
+func (c *Client) CreateToken(ctx context.Context, name string) (*Token, error) {
+	token := &Token{}
+	body := map[string]string{"name": name}
+	if _, err := c.do(ctx, http.MethodPost, "/tokens", nil, body, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// DeleteToken revokes a personal token of the user of the client
+func (c *Client) DeleteToken(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/tokens/%d", id), nil, nil, nil)
+	return err
+}
@@ -0,0 +1,91 @@
+// Package clientip finds the address of the client of a request, the
+// X-Forwarded-For header is only trusted from the proxies in front of the hub
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	mu      sync.RWMutex
+	trusted []*net.IPNet
+)
+
+// SetTrustedProxies sets the proxies whose X-Forwarded-For header is
+// trusted, given as a comma separated list of addresses or CIDR ranges,
+// e.g. 10.0.0.0/8,192.168.1.1. No proxy is trusted when it is empty
+func SetTrustedProxies(list string) error {
+	nets := []*net.IPNet{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy %q", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q", item)
+		}
+		nets = append(nets, ipNet)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	trusted = nets
+	return nil
+}
+
+func isTrusted(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, ipNet := range trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Address returns the address of the client of a request. When the request
+// comes from a trusted proxy, X-Forwarded-For is walked from the right and
+// the first address which is not a trusted proxy is the client, as the
+// addresses on its left are set by the client
+func Address(r *http.Request) string {
+	address := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	if !isTrusted(address) {
+		return address
+	}
+	forwarded := strings.Split(strings.Join(r.Header["X-Forwarded-For"], ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+		address = hop
+		if !isTrusted(hop) {
+			break
+		}
+	}
+	return address
+}
@@ -0,0 +1,37 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAddress(t *testing.T) {
+	if err := SetTrustedProxies("10.0.0.0/8, 192.168.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetTrustedProxies("")
+	tests := []struct {
+		remote, forwarded, expected string
+	}{
+		// the header of an untrusted client is ignored
+		{"203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"10.0.0.1:5000", "", "10.0.0.1"},
+		{"10.0.0.1:5000", "198.51.100.1", "198.51.100.1"},
+		// a client cannot prepend a forged address
+		{"10.0.0.1:5000", "1.2.3.4, 198.51.100.1, 192.168.1.1", "198.51.100.1"},
+		{"192.168.1.1:5000", "10.0.0.2", "10.0.0.2"},
+	}
+	for _, tc := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tc.remote
+		if tc.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tc.forwarded)
+		}
+		if got := Address(r); got != tc.expected {
+			t.Errorf("%s %q Expected: %v , Got: %v", tc.remote, tc.forwarded, tc.expected, got)
+		}
+	}
+	if err := SetTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Errorf("expected an invalid range to fail")
+	}
+}
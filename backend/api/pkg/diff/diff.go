@@ -0,0 +1,254 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/spec"
+)
+
+// Parts of an interface a change applies to
+const (
+	Param     = "param"
+	Workspace = "workspace"
+	Result    = "result"
+	Resource  = "resource"
+	Step      = "step"
+	Sidecar   = "sidecar"
+	Image     = "image"
+)
+
+// Actions of a change
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is a single difference between the interfaces of two versions. A
+// breaking change can fail the runs of existing consumers
+type Change struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Action      string `json:"action"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	Description string `json:"description"`
+	Breaking    bool   `json:"breaking"`
+}
+
+// Changes lists the differences between two versions
+type Changes []Change
+
+// Breaking checks if any of the changes is breaking
+func (c Changes) Breaking() bool {
+	for _, change := range c {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff is the difference between two versions of a resource
+type Diff struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Changes  Changes `json:"changes"`
+	Breaking bool    `json:"breaking"`
+	Unified  string  `json:"unified"`
+}
+
+// Versions compares the YAML content of two named versions of a resource
+func Versions(fromName, from, toName, to string) (*Diff, error) {
+	fromInterface, err := spec.Parse([]byte(from))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version %s: %v", fromName, err)
+	}
+	toInterface, err := spec.Parse([]byte(to))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse version %s: %v", toName, err)
+	}
+	changes := Compare(fromInterface, toInterface)
+	return &Diff{
+		From:     fromName,
+		To:       toName,
+		Changes:  changes,
+		Breaking: changes.Breaking(),
+		Unified:  Unified(fromName, toName, from, to),
+	}, nil
+}
+
+type differ struct {
+	changes Changes
+}
+
+func (d *differ) add(kind, name, action string, breaking bool, from, to string, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Kind:        kind,
+		Name:        name,
+		Action:      action,
+		From:        from,
+		To:          to,
+		Description: fmt.Sprintf(format, args...),
+		Breaking:    breaking,
+	})
+}
+
+// Compare returns the changes of the interface from one version to another
+func Compare(from, to *spec.Interface) Changes {
+	d := &differ{changes: Changes{}}
+	d.params(from.Params, to.Params)
+	d.workspaces(from.Workspaces, to.Workspaces)
+	d.results(from.Results, to.Results)
+	d.resources(from.Resources, to.Resources)
+	d.containers(Step, from.Steps, to.Steps)
+	d.containers(Sidecar, from.Sidecars, to.Sidecars)
+	return d.changes
+}
+
+func (d *differ) params(from, to []spec.Param) {
+	old := map[string]spec.Param{}
+	for _, p := range from {
+		old[p.Name] = p
+	}
+	current := map[string]bool{}
+	for _, p := range to {
+		current[p.Name] = true
+		o, ok := old[p.Name]
+		if !ok {
+			if p.Required() {
+				d.add(Param, p.Name, Added, true, "", p.Type, "required param %q added", p.Name)
+			} else {
+				d.add(Param, p.Name, Added, false, "", p.Default, "param %q added with default %q", p.Name, p.Default)
+			}
+			continue
+		}
+		if o.Type != p.Type {
+			d.add(Param, p.Name, Changed, true, o.Type, p.Type, "type of param %q changed from %s to %s", p.Name, o.Type, p.Type)
+		}
+		switch {
+		case !o.Required() && p.Required():
+			d.add(Param, p.Name, Changed, true, o.Default, "", "param %q became required", p.Name)
+		case o.Required() && !p.Required():
+			d.add(Param, p.Name, Changed, false, "", p.Default, "param %q became optional with default %q", p.Name, p.Default)
+		case o.Default != p.Default:
+			d.add(Param, p.Name, Changed, false, o.Default, p.Default, "default of param %q changed from %q to %q", p.Name, o.Default, p.Default)
+		}
+	}
+	for _, p := range from {
+		if !current[p.Name] {
+			d.add(Param, p.Name, Removed, true, p.Type, "", "param %q removed", p.Name)
+		}
+	}
+}
+
+func (d *differ) workspaces(from, to []spec.Workspace) {
+	old := map[string]spec.Workspace{}
+	for _, w := range from {
+		old[w.Name] = w
+	}
+	current := map[string]bool{}
+	for _, w := range to {
+		current[w.Name] = true
+		o, ok := old[w.Name]
+		if !ok {
+			if w.Optional {
+				d.add(Workspace, w.Name, Added, false, "", "", "optional workspace %q added", w.Name)
+			} else {
+				d.add(Workspace, w.Name, Added, true, "", "", "required workspace %q added", w.Name)
+			}
+			continue
+		}
+		if o.Optional && !w.Optional {
+			d.add(Workspace, w.Name, Changed, true, "optional", "required", "workspace %q became required", w.Name)
+		} else if !o.Optional && w.Optional {
+			d.add(Workspace, w.Name, Changed, false, "required", "optional", "workspace %q became optional", w.Name)
+		}
+		if o.MountPath != w.MountPath {
+			d.add(Workspace, w.Name, Changed, false, o.MountPath, w.MountPath, "mount path of workspace %q changed from %q to %q", w.Name, o.MountPath, w.MountPath)
+		}
+	}
+	for _, w := range from {
+		if !current[w.Name] {
+			// runs binding an undeclared workspace are rejected
+			d.add(Workspace, w.Name, Removed, true, "", "", "workspace %q removed", w.Name)
+		}
+	}
+}
+
+func (d *differ) results(from, to []spec.Result) {
+	old := map[string]bool{}
+	for _, r := range from {
+		old[r.Name] = true
+	}
+	current := map[string]bool{}
+	for _, r := range to {
+		current[r.Name] = true
+		if !old[r.Name] {
+			d.add(Result, r.Name, Added, false, "", "", "result %q added", r.Name)
+		}
+	}
+	for _, r := range from {
+		if !current[r.Name] {
+			d.add(Result, r.Name, Removed, true, "", "", "result %q removed", r.Name)
+		}
+	}
+}
+
+func (d *differ) resources(from, to []spec.PipelineResource) {
+	key := func(r spec.PipelineResource) string { return r.Direction + "/" + r.Name }
+	old := map[string]spec.PipelineResource{}
+	for _, r := range from {
+		old[key(r)] = r
+	}
+	current := map[string]bool{}
+	for _, r := range to {
+		current[key(r)] = true
+		o, ok := old[key(r)]
+		if !ok {
+			d.add(Resource, r.Name, Added, !r.Optional, "", r.Type, "%s resource %q of type %s added", r.Direction, r.Name, r.Type)
+			continue
+		}
+		if o.Type != r.Type {
+			d.add(Resource, r.Name, Changed, true, o.Type, r.Type, "type of %s resource %q changed from %s to %s", r.Direction, r.Name, o.Type, r.Type)
+		}
+	}
+	for _, r := range from {
+		if !current[key(r)] {
+			d.add(Resource, r.Name, Removed, true, r.Type, "", "%s resource %q removed", r.Direction, r.Name)
+		}
+	}
+}
+
+// containers compares steps or sidecars by name, unnamed ones by position
+func (d *differ) containers(kind string, from, to []spec.Container) {
+	name := func(c spec.Container, i int) string {
+		if c.Name != "" {
+			return c.Name
+		}
+		return fmt.Sprintf("#%d", i)
+	}
+	old := map[string]spec.Container{}
+	for i, c := range from {
+		old[name(c, i)] = c
+	}
+	current := map[string]bool{}
+	for i, c := range to {
+		n := name(c, i)
+		current[n] = true
+		o, ok := old[n]
+		if !ok {
+			d.add(kind, n, Added, false, "", c.Image, "%s %q added", kind, n)
+			continue
+		}
+		if o.Image != c.Image {
+			d.add(Image, n, Changed, false, o.Image, c.Image, "image of %s %q changed from %s to %s", kind, n, o.Image, c.Image)
+		}
+	}
+	for i, c := range from {
+		n := name(c, i)
+		if !current[n] {
+			d.add(kind, n, Removed, false, c.Image, "", "%s %q removed", kind, n)
+		}
+	}
+}
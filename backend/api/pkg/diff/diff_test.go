@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/spec"
+)
+
+const before = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: git-clone
+spec:
+  params:
+    - name: url
+    - name: revision
+      default: master
+    - name: depth
+      default: "1"
+  workspaces:
+    - name: output
+  results:
+    - name: commit
+  steps:
+    - name: clone
+      image: alpine/git:v2.24.3
+`
+
+const after = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: git-clone
+spec:
+  params:
+    - name: url
+    - name: revision
+      default: main
+    - name: subdirectory
+      default: ""
+  workspaces:
+    - name: output
+    - name: ssh
+      optional: true
+  results:
+    - name: commit
+    - name: url
+  steps:
+    - name: clone
+      image: alpine/git:v2.26.2
+    - name: report
+      image: alpine:3.12
+`
+
+func TestCompare(t *testing.T) {
+	from, err := spec.Parse([]byte(before))
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := spec.Parse([]byte(after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := Compare(from, to)
+	expected := map[string]bool{
+		"param/revision/changed":   false,
+		"param/subdirectory/added": false,
+		"param/depth/removed":      true,
+		"workspace/ssh/added":      false,
+		"result/url/added":         false,
+		"image/clone/changed":      false,
+		"step/report/added":        false,
+	}
+	if len(changes) != len(expected) {
+		t.Errorf("expected %d changes, got %+v", len(expected), changes)
+	}
+	for _, c := range changes {
+		breaking, ok := expected[c.Kind+"/"+c.Name+"/"+c.Action]
+		if !ok {
+			t.Errorf("unexpected change %+v", c)
+		} else if c.Breaking != breaking {
+			t.Errorf("expected breaking %v for %+v", breaking, c)
+		}
+	}
+	if !changes.Breaking() {
+		t.Error("expected the changes to be breaking")
+	}
+	if Compare(to, to).Breaking() || len(Compare(to, to)) != 0 {
+		t.Error("expected no changes between the same versions")
+	}
+}
+
+func TestUnified(t *testing.T) {
+	if d := Unified("a", "b", before, before); d != "" {
+		t.Errorf("expected no diff, got %q", d)
+	}
+	d := Unified("a", "b", "a\nb\nc\n", "a\nx\nc\nd\n")
+	expected := `--- a
++++ b
+@@ -1,3 +1,4 @@
+ a
+-b
++x
+ c
++d
+`
+	if d != expected {
+		t.Errorf("unexpected diff:\n%s", d)
+	}
+	d = Unified("a", "b", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", "1\n2\n3\n4\n5\n6\n7\n8\n9\nten\n")
+	expected = `--- a
++++ b
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++ten
+`
+	if d != expected {
+		t.Errorf("unexpected diff:\n%s", d)
+	}
+}
@@ -0,0 +1,197 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/diagnostic"
+)
+
+// Rules of the compatibility diagnostics
+const (
+	// SemverRule is reported when a version bump does not match its changes
+	SemverRule = "semver"
+	// BreakingRule lists each breaking change of a new version
+	BreakingRule = "breaking-change"
+)
+
+// Bumps of a version, in increasing order
+const (
+	NoBump = iota
+	PatchBump
+	MinorBump
+	MajorBump
+)
+
+var bumpNames = []string{"no", "patch", "minor", "major"}
+
+// Version is a semantic version, "0.2" is read as 0.2.0
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+// ParseVersion reads a version such as 1.2.3, v0.2 or 1.0.0-rc.1
+func ParseVersion(s string) (Version, error) {
+	v := Version{}
+	core := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(core, "+"); i >= 0 {
+		core = core[:i]
+	}
+	if i := strings.Index(core, "-"); i >= 0 {
+		core, v.Prerelease = core[:i], core[i+1:]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("%q is not a semantic version", s)
+	}
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("%q is not a semantic version", s)
+		}
+		*numbers[i] = n
+	}
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is lower, equal or greater than o. A
+// prerelease is lower than its release
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	case v.Prerelease < o.Prerelease:
+		return -1
+	}
+	return 1
+}
+
+// bump returns how much a version was bumped, to is greater than from
+func bump(from, to Version) int {
+	switch {
+	case to.Major != from.Major:
+		return MajorBump
+	case to.Minor != from.Minor:
+		return MinorBump
+	case to.Patch != from.Patch:
+		return PatchBump
+	}
+	return NoBump
+}
+
+// RequiredBump returns the least bump the changes need: major for breaking
+// changes, minor for additions to the interface and patch otherwise. Before
+// 1.0.0 a minor bump is enough for breaking changes.
+func RequiredBump(from Version, changes Changes) int {
+	required := PatchBump
+	for _, c := range changes {
+		switch {
+		case c.Breaking:
+			if from.Major == 0 {
+				return MinorBump
+			}
+			return MajorBump
+		case c.Action == Added && c.Kind != Step && c.Kind != Sidecar:
+			required = MinorBump
+		}
+	}
+	return required
+}
+
+// changePath returns the path of the field of a change in the new content
+func changePath(c Change) string {
+	switch c.Kind {
+	case Param:
+		return fmt.Sprintf("spec.params[%s]", c.Name)
+	case Workspace:
+		return fmt.Sprintf("spec.workspaces[%s]", c.Name)
+	case Result:
+		return fmt.Sprintf("spec.results[%s]", c.Name)
+	case Resource:
+		return "spec.resources"
+	}
+	return "spec"
+}
+
+// CheckVersion reports the breaking changes of a new version and whether
+// its version follows semver for them. A version which is not bumped enough
+// for its breaking changes, or which goes back, is an error; other
+// mismatches are warnings. The check is skipped when either version has no
+// version label.
+func CheckVersion(fromVersion, toVersion string, changes Changes, content []byte) diagnostic.List {
+	index := diagnostic.NewIndex(content)
+	diagnostics := diagnostic.List{}
+	report := func(rule string, severity diagnostic.Severity, path, format string, args ...interface{}) {
+		line, column := index.Locate(path)
+		diagnostics = append(diagnostics, diagnostic.Diagnostic{
+			Rule:     rule,
+			Severity: severity,
+			Path:     path,
+			Line:     line,
+			Column:   column,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+	for _, c := range changes {
+		if c.Breaking {
+			report(BreakingRule, diagnostic.Warning, changePath(c), "%s", c.Description)
+		}
+	}
+
+	if fromVersion == "" || toVersion == "" {
+		return diagnostics
+	}
+	const labelPath = "metadata.labels"
+	from, err := ParseVersion(fromVersion)
+	if err != nil {
+		// the previous version can not be compared to
+		return diagnostics
+	}
+	to, err := ParseVersion(toVersion)
+	if err != nil {
+		report(SemverRule, diagnostic.Warning, labelPath, "%s", err)
+		return diagnostics
+	}
+	if to.Compare(from) < 0 {
+		report(SemverRule, diagnostic.Error, labelPath, "version %s is lower than the previous version %s", toVersion, fromVersion)
+		return diagnostics
+	}
+
+	required, actual := RequiredBump(from, changes), bump(from, to)
+	if actual >= required {
+		return diagnostics
+	}
+	severity := diagnostic.Warning
+	if changes.Breaking() {
+		severity = diagnostic.Error
+	}
+	if actual == NoBump {
+		report(SemverRule, severity, labelPath, "the resource changed but its version %s was not bumped, expected a %s bump", toVersion, bumpNames[required])
+	} else {
+		report(SemverRule, severity, labelPath, "version %s is a %s bump of %s, the changes need a %s bump", toVersion, bumpNames[actual], fromVersion, bumpNames[required])
+	}
+	return diagnostics
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/diagnostic"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected Version
+	}{
+		{"0.2", Version{Minor: 2}},
+		{"v1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+		{"1.0.0-rc.1+build", Version{Major: 1, Prerelease: "rc.1"}},
+	}
+	for _, tc := range tests {
+		v, err := ParseVersion(tc.version)
+		if err != nil || v != tc.expected {
+			t.Errorf("%s: unexpected version %+v, %v", tc.version, v, err)
+		}
+	}
+	for _, invalid := range []string{"", "latest", "1.2.3.4", "1.x"} {
+		if _, err := ParseVersion(invalid); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+	rc, _ := ParseVersion("1.0.0-rc.1")
+	release, _ := ParseVersion("1.0.0")
+	if rc.Compare(release) != -1 || release.Compare(rc) != 1 {
+		t.Error("expected a prerelease to be lower than its release")
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	breaking := Changes{{Kind: Param, Name: "depth", Action: Removed, Description: `param "depth" removed`, Breaking: true}}
+	feature := Changes{{Kind: Param, Name: "subdirectory", Action: Added, Description: `param "subdirectory" added`}}
+	fix := Changes{{Kind: Image, Name: "clone", Action: Changed, Description: "image changed"}}
+	tests := []struct {
+		name     string
+		from, to string
+		changes  Changes
+		semver   diagnostic.Severity
+	}{
+		{"major bump", "1.2.0", "2.0.0", breaking, ""},
+		{"breaking minor bump", "1.2.0", "1.3.0", breaking, diagnostic.Error},
+		{"breaking minor bump before 1.0", "0.1", "0.2", breaking, ""},
+		{"breaking patch bump before 1.0", "0.1", "0.1.1", breaking, diagnostic.Error},
+		{"feature minor bump", "1.2.0", "1.3.0", feature, ""},
+		{"feature patch bump", "1.2.0", "1.2.1", feature, diagnostic.Warning},
+		{"fix patch bump", "1.2.0", "1.2.1", fix, ""},
+		{"fix without bump", "1.2.0", "1.2.0", fix, diagnostic.Warning},
+		{"downgrade", "1.2.0", "1.1.0", fix, diagnostic.Error},
+		{"no version label", "", "1.0.0", breaking, ""},
+	}
+	for _, tc := range tests {
+		var semver diagnostic.Severity
+		breakingChanges := 0
+		for _, d := range CheckVersion(tc.from, tc.to, tc.changes, nil) {
+			switch d.Rule {
+			case SemverRule:
+				semver = d.Severity
+			case BreakingRule:
+				breakingChanges++
+			}
+		}
+		if semver != tc.semver {
+			t.Errorf("%s: expected semver diagnostic %q, got %q", tc.name, tc.semver, semver)
+		}
+		if tc.changes.Breaking() && breakingChanges != 1 {
+			t.Errorf("%s: expected the breaking change to be reported", tc.name)
+		}
+	}
+}
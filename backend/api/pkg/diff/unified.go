@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines around each hunk
+const context = 3
+
+type operation struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// lines splits a text in lines, a trailing newline does not add a line
+func lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// edits returns the line edits turning a into b using their longest common
+// subsequence. Resources are small so the quadratic table is fine.
+func edits(a, b []string) []operation {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	ops := []operation{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, operation{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, operation{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, operation{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// Unified returns the unified diff of two texts, empty if they are equal
+func Unified(fromName, toName, from, to string) string {
+	ops := edits(lines(from), lines(to))
+
+	var out strings.Builder
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		// extend the hunk while changes are closer than twice the context
+		first := start - context
+		if first < 0 {
+			first = 0
+		}
+		end := start
+		for {
+			for end < len(ops) && ops[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*context {
+				break
+			}
+			end = next
+		}
+		last := end + context
+		if last > len(ops) {
+			last = len(ops)
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		// line numbers of the hunk in both texts
+		fromLine, toLine := 1, 1
+		for _, op := range ops[:first] {
+			if op.kind != '+' {
+				fromLine++
+			}
+			if op.kind != '-' {
+				toLine++
+			}
+		}
+		fromCount, toCount := 0, 0
+		for _, op := range ops[first:last] {
+			if op.kind != '+' {
+				fromCount++
+			}
+			if op.kind != '-' {
+				toCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount))
+		for _, op := range ops[first:last] {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.line)
+		}
+		start = last
+	}
+	return out.String()
+}
+
+// hunkRange formats the start and length of a hunk, an empty range starts
+// at the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
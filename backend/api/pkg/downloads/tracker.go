@@ -0,0 +1,146 @@
+package downloads
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/app"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/clientip"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"go.uber.org/zap"
+)
+
+// Client types a resource can be downloaded with
+const (
+	UI      = "ui"
+	CLI     = "cli"
+	Kubectl = "kubectl"
+)
+
+const (
+	// window in which repeated downloads by the same client are counted once
+	dedupWindow = 5 * time.Minute
+	queueSize   = 1000
+)
+
+// Event represents a download of a resource
+type Event struct {
+	ResourceID int
+	Version    string
+	Client     string
+	ClientID   string
+	Time       time.Time
+}
+
+type key struct {
+	resourceID int
+	version    string
+	clientID   string
+}
+
+// Tracker deduplicates download events and records them in the background
+type Tracker struct {
+	log    *zap.SugaredLogger
+	mu     sync.Mutex
+	seen   map[key]time.Time
+	events chan Event
+	record func(Event) error
+}
+
+func New(app app.Config) *Tracker {
+	t := newTracker(app.Logger().With("name", "downloads"), record)
+	go t.run()
+	return t
+}
+
+func newTracker(log *zap.SugaredLogger, record func(Event) error) *Tracker {
+	return &Tracker{
+		log:    log,
+		seen:   make(map[key]time.Time),
+		events: make(chan Event, queueSize),
+		record: record,
+	}
+}
+
+// Track queues the event unless the same client downloaded the same
+// version of the resource recently. It returns true if the event is counted
+func (t *Tracker) Track(e Event) bool {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if t.isDuplicate(e) {
+		return false
+	}
+	select {
+	case t.events <- e:
+		return true
+	default:
+		t.log.Warnf("download queue is full, dropping event for resource %d", e.ResourceID)
+		return false
+	}
+}
+
+func (t *Tracker) isDuplicate(e Event) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for k, seenAt := range t.seen {
+		if e.Time.Sub(seenAt) > dedupWindow {
+			delete(t.seen, k)
+		}
+	}
+	k := key{e.ResourceID, e.Version, e.ClientID}
+	if _, ok := t.seen[k]; ok {
+		return true
+	}
+	t.seen[k] = e.Time
+	return false
+}
+
+func (t *Tracker) run() {
+	for e := range t.events {
+		if err := t.record(e); err != nil {
+			t.log.Error(err)
+		}
+	}
+}
+
+// record stores the event and updates the download count and daily
+// statistics of the resource in a single transaction
+func record(e Event) error {
+	download := models.Download{
+		ResourceID: e.ResourceID,
+		Version:    e.Version,
+		Client:     e.Client,
+		ClientID:   e.ClientID,
+		CreatedAt:  e.Time,
+	}
+	return models.RecordDownload(&download)
+}
+
+// ClientType returns the type of client which made the request, an explicit
+// client query parameter takes precedence over the User-Agent
+func ClientType(r *http.Request) string {
+	switch client := strings.ToLower(r.FormValue("client")); client {
+	case UI, CLI, Kubectl:
+		return client
+	}
+	agent := r.UserAgent()
+	switch {
+	case strings.HasPrefix(agent, "Mozilla"):
+		return UI
+	case strings.HasPrefix(agent, "hub/"), strings.HasPrefix(agent, "tkn"):
+		return CLI
+	}
+	return Kubectl
+}
+
+// ClientID returns an anonymous identifier of the client which made the request
+func ClientID(r *http.Request) string {
+	sum := sha256.Sum256([]byte(clientip.Address(r) + "|" + r.UserAgent()))
+	return hex.EncodeToString(sum[:])
+}
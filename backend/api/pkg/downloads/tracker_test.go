@@ -0,0 +1,56 @@
+package downloads
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestTrackDeduplicatesBursts(t *testing.T) {
+	tracker := newTracker(zap.NewNop().Sugar(), func(Event) error { return nil })
+	now := time.Now()
+
+	event := Event{ResourceID: 1, Version: "0.1", ClientID: "a", Time: now}
+	if !tracker.Track(event) {
+		t.Errorf("First download should be counted")
+	}
+	event.Time = now.Add(time.Minute)
+	if tracker.Track(event) {
+		t.Errorf("Repeated download within window should not be counted")
+	}
+	event.ClientID = "b"
+	if !tracker.Track(event) {
+		t.Errorf("Download by another client should be counted")
+	}
+	event.ClientID = "a"
+	event.Time = now.Add(dedupWindow + time.Second)
+	if !tracker.Track(event) {
+		t.Errorf("Download after window should be counted")
+	}
+	if len(tracker.events) != 3 {
+		t.Errorf("Queued events Expected: %v , Got: %v", 3, len(tracker.events))
+	}
+}
+
+func TestClientType(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (X11; Linux x86_64)": UI,
+		"hub/0.1":                         CLI,
+		"Go-http-client/1.1":              Kubectl,
+	}
+	for agent, expected := range tests {
+		r := httptest.NewRequest("GET", "/resource/1/install", nil)
+		r.Header.Set("User-Agent", agent)
+		if got := ClientType(r); got != expected {
+			t.Errorf("ClientType(%q) Expected: %v , Got: %v", agent, expected, got)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/resource/1/install?client=cli", nil)
+	r.Header.Set("User-Agent", "Mozilla/5.0")
+	if got := ClientType(r); got != CLI {
+		t.Errorf("ClientType Expected: %v , Got: %v", CLI, got)
+	}
+}
@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Node represents a resource in the dependency graph
+type Node struct {
+	ID         string `json:"id"`
+	ResourceID int    `json:"resource_id,omitempty"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	RawPath    string `json:"raw_path,omitempty"`
+}
+
+// Edge represents a "uses" relation between two nodes
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Graph is a directed graph of resources
+type Graph struct {
+	Root  string `json:"root"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// New returns an empty graph rooted at the given node
+func New(root Node) *Graph {
+	g := &Graph{Root: root.ID}
+	g.AddNode(root)
+	return g
+}
+
+// AddNode adds a node unless a node with the same ID already exists
+func (g *Graph) AddNode(n Node) {
+	if g.HasNode(n.ID) {
+		return
+	}
+	g.Nodes = append(g.Nodes, n)
+}
+
+// HasNode checks if a node with given ID is part of the graph
+func (g *Graph) HasNode(id string) bool {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// AddEdge adds an edge unless it already exists
+func (g *Graph) AddEdge(from, to string) {
+	for _, e := range g.Edges {
+		if e.From == from && e.To == to {
+			return
+		}
+	}
+	g.Edges = append(g.Edges, Edge{From: from, To: to})
+}
+
+// DOT renders the graph in Graphviz DOT format
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.sortedNodes() {
+		shape := "box"
+		if n.Type == "pipeline" {
+			shape = "box3d"
+		}
+		style := ""
+		if n.ResourceID == 0 {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %q [label=%q, shape=%s%s];\n", n.ID, n.label(), shape, style)
+	}
+	for _, e := range g.sortedEdges() {
+		fmt.Fprintf(&b, "  %q -> %q;\n", e.From, e.To)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, n := range g.sortedNodes() {
+		label := strings.Replace(n.label(), `"`, "#quot;", -1)
+		if n.Type == "pipeline" {
+			fmt.Fprintf(&b, "  %s[[\"%s\"]]\n", mermaidID(n.ID), label)
+		} else {
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", mermaidID(n.ID), label)
+		}
+	}
+	for _, e := range g.sortedEdges() {
+		fmt.Fprintf(&b, "  %s --> %s\n", mermaidID(e.From), mermaidID(e.To))
+	}
+	return b.String()
+}
+
+func (n Node) label() string {
+	if n.Type == "" {
+		return n.Name
+	}
+	return fmt.Sprintf("%s (%s)", n.Name, n.Type)
+}
+
+func (g *Graph) sortedNodes() []Node {
+	nodes := append([]Node(nil), g.Nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+func (g *Graph) sortedEdges() []Edge {
+	edges := append([]Edge(nil), g.Edges...)
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+// mermaidID converts a node ID into an identifier accepted by Mermaid
+func mermaidID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, id)
+}
@@ -0,0 +1,46 @@
+package graph
+
+import "testing"
+
+func testGraph() *Graph {
+	g := New(Node{ID: "resource-1", ResourceID: 1, Name: "build", Type: "pipeline"})
+	g.AddNode(Node{ID: "resource-2", ResourceID: 2, Name: "git-clone", Type: "task"})
+	g.AddNode(Node{ID: "resource-2", ResourceID: 2, Name: "git-clone", Type: "task"})
+	g.AddEdge("resource-1", "resource-2")
+	g.AddEdge("resource-1", "resource-2")
+	return g
+}
+
+func TestAddNodeAndEdgeAreIdempotent(t *testing.T) {
+	g := testGraph()
+	if len(g.Nodes) != 2 {
+		t.Errorf("Nodes Expected: %v , Got: %v", 2, len(g.Nodes))
+	}
+	if len(g.Edges) != 1 {
+		t.Errorf("Edges Expected: %v , Got: %v", 1, len(g.Edges))
+	}
+}
+
+func TestDOT(t *testing.T) {
+	expected := `digraph dependencies {
+  rankdir=LR;
+  "resource-1" [label="build (pipeline)", shape=box3d];
+  "resource-2" [label="git-clone (task)", shape=box];
+  "resource-1" -> "resource-2";
+}
+`
+	if got := testGraph().DOT(); got != expected {
+		t.Errorf("DOT Expected: %v , Got: %v", expected, got)
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	expected := `graph LR
+  resource_1[["build (pipeline)"]]
+  resource_2["git-clone (task)"]
+  resource_1 --> resource_2
+`
+	if got := testGraph().Mermaid(); got != expected {
+		t.Errorf("Mermaid Expected: %v , Got: %v", expected, got)
+	}
+}
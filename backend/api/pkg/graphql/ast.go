@@ -0,0 +1,138 @@
+package graphql
+
+// Location is a position in a query, lines and columns start at 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Document is a parsed query
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is a query, mutation or subscription of a document
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []*VariableDefinition
+	Directives []*Directive
+	Selections []Selection
+	Location   Location
+}
+
+// VariableDefinition declares a variable of an operation
+type VariableDefinition struct {
+	Name     string
+	Type     *TypeRef
+	Default  Value
+	Location Location
+}
+
+// TypeRef is a type as written in a query, e.g. [String!]!
+type TypeRef struct {
+	Name    string
+	Elem    *TypeRef
+	NonNull bool
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Fragment is a named selection set on a type
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Location      Location
+}
+
+// Selection is a *FieldSelection, a *FragmentSpread or an *InlineFragment
+type Selection interface {
+	location() Location
+}
+
+// FieldSelection selects a field, its alias is the key of its value in
+// the result
+type FieldSelection struct {
+	Alias      string
+	Name       string
+	Arguments  []*Argument
+	Directives []*Directive
+	Selections []Selection
+	Location   Location
+}
+
+// FragmentSpread includes a named fragment
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Location   Location
+}
+
+// InlineFragment includes selections, possibly for a type only
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	Selections    []Selection
+	Location      Location
+}
+
+func (f *FieldSelection) location() Location { return f.Location }
+func (f *FragmentSpread) location() Location { return f.Location }
+func (f *InlineFragment) location() Location { return f.Location }
+
+// Key returns the key of the value of the field in the result
+func (f *FieldSelection) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Directive is e.g. @include(if: $withReadme)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Location  Location
+}
+
+// Argument is a named value given to a field or a directive
+type Argument struct {
+	Name     string
+	Value    Value
+	Location Location
+}
+
+// Kinds of the values of a query
+const (
+	VariableValue = "Variable"
+	IntValue      = "Int"
+	FloatValue    = "Float"
+	StringValue   = "String"
+	BooleanValue  = "Boolean"
+	NullValue     = "Null"
+	EnumValue     = "Enum"
+	ListValue     = "List"
+	ObjectValue   = "Object"
+)
+
+// Value is a literal or a variable of a query. Raw holds the name of a
+// variable or an enum value and the text of the other scalars
+type Value struct {
+	Kind     string
+	Raw      string
+	List     []Value
+	Fields   []*Argument
+	Location Location
+}
@@ -0,0 +1,463 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Request is a query along with its variables, as posted to an endpoint
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Response holds the data of a query and the errors which occurred. Data
+// is nil if the query could not be executed
+type Response struct {
+	Data   *Map     `json:"data,omitempty"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is an error of a query, the path leads to the field which failed.
+// Resolvers may return an *Error to set its extensions, the message of
+// other errors is used as is
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Locations[0].Line, e.Locations[0].Column)
+}
+
+// Map is a JSON object keeping the order of its keys, which is the order
+// of the fields of the query
+type Map struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newMap() *Map {
+	return &Map{values: map[string]interface{}{}}
+}
+
+// Set sets the value of a key
+func (m *Map) Set(key string, v interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+// Get returns the value of a key
+func (m *Map) Get(key string) interface{} {
+	return m.values[key]
+}
+
+// Keys returns the keys in order
+func (m *Map) Keys() []string {
+	return m.keys
+}
+
+// MarshalJSON encodes the map as a JSON object
+func (m *Map) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		b.Write(k)
+		b.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Execute runs the query of a request. Only queries are supported, the
+// resolvers are called from the calling goroutine
+func (s *Schema) Execute(ctx context.Context, req Request) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err)}}
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{toError(err)}}
+	}
+	if op.Type != "query" {
+		return &Response{Errors: []*Error{{Message: fmt.Sprintf("%s operations are not supported", op.Type), Locations: []Location{op.Location}}}}
+	}
+	v := &validator{schema: s, doc: doc, vars: map[string]*VariableDefinition{}, validated: map[string]bool{}}
+	if errs := v.validate(op); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	vars, errs := s.coerceVariables(op, req.Variables)
+	if len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+
+	e := &executor{ctx: ctx, schema: s, doc: doc, vars: vars}
+	if errs := e.limit(op); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	data := e.selectionSet(s.Query, nil, op.Selections, nil)
+	// each round calls the thunks returned so far, the loads they queue
+	// are batched in the next round
+	for len(e.pending) > 0 {
+		pending := e.pending
+		e.pending = nil
+		for _, fn := range pending {
+			fn()
+		}
+	}
+	return &Response{Data: data, Errors: e.errors}
+}
+
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Message: err.Error()}
+}
+
+func (d *Document) operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) > 1 {
+			return nil, &Error{Message: "operationName is required for a document with several operations"}
+		}
+		return d.Operations[0], nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("unknown operation %q", name)}
+}
+
+func (s *Schema) coerceVariables(op *Operation, values map[string]interface{}) (map[string]interface{}, []*Error) {
+	vars := map[string]interface{}{}
+	errs := []*Error{}
+	for _, def := range op.Variables {
+		fail := func(format string, args ...interface{}) {
+			errs = append(errs, &Error{Message: fmt.Sprintf("Variable $%s: ", def.Name) + fmt.Sprintf(format, args...), Locations: []Location{def.Location}})
+		}
+		t, err := s.inputType(def.Type)
+		if err != nil {
+			fail("%s", err)
+			continue
+		}
+		value, given := values[def.Name]
+		if !given && def.Default.Kind != "" {
+			v, err := coerceLiteral(t, def.Default, nil)
+			if err != nil {
+				fail("%s", err)
+				continue
+			}
+			vars[def.Name] = v
+			continue
+		}
+		if !given {
+			if _, ok := t.(*NonNull); ok {
+				fail("a value of type %s is required", t)
+			}
+			continue
+		}
+		v, err := coerceValue(t, value)
+		if err != nil {
+			fail("%s", err)
+			continue
+		}
+		vars[def.Name] = v
+	}
+	return vars, errs
+}
+
+// coerceValue coerces a value decoded from JSON
+func coerceValue(t Type, v interface{}) (interface{}, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected a value of type %s, got null", t)
+		}
+		return coerceValue(nonNull.Of, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := v.([]interface{})
+		if !ok {
+			item, err := coerceValue(t.Of, v)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			c, err := coerceValue(t.Of, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = c
+		}
+		return list, nil
+	case *Scalar:
+		return t.ParseValue(v)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// coerceLiteral coerces a value written in a query, the variables are
+// already coerced
+func coerceLiteral(t Type, v Value, vars map[string]interface{}) (interface{}, error) {
+	if v.Kind == VariableValue {
+		return vars[v.Raw], nil
+	}
+	if nonNull, ok := t.(*NonNull); ok {
+		if v.Kind == NullValue {
+			return nil, fmt.Errorf("expected a value of type %s, got null", t)
+		}
+		return coerceLiteral(nonNull.Of, v, vars)
+	}
+	if v.Kind == NullValue {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		if v.Kind != ListValue {
+			item, err := coerceLiteral(t.Of, v, vars)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		list := make([]interface{}, len(v.List))
+		for i, item := range v.List {
+			c, err := coerceLiteral(t.Of, item, vars)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = c
+		}
+		return list, nil
+	case *Scalar:
+		return t.ParseLiteral(v)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// executor resolves the fields of an operation
+type executor struct {
+	ctx     context.Context
+	schema  *Schema
+	doc     *Document
+	vars    map[string]interface{}
+	errors  []*Error
+	pending []func()
+}
+
+func (e *executor) fail(err error, f *FieldSelection, path []interface{}) {
+	failure := &Error{Message: err.Error(), Locations: []Location{f.Location}, Path: path}
+	if resolverError, ok := err.(*Error); ok {
+		failure.Message, failure.Extensions = resolverError.Message, resolverError.Extensions
+	}
+	e.errors = append(e.errors, failure)
+}
+
+// selectionSet resolves the fields selected on an object, the values of
+// deferred fields are set once their thunks are called
+func (e *executor) selectionSet(object *Object, source interface{}, selections []Selection, path []interface{}) *Map {
+	result := newMap()
+	for _, group := range e.collectFields(object, selections, nil, map[string]bool{}) {
+		f := group[0]
+		key := f.Key()
+		fieldPath := append(append([]interface{}{}, path...), key)
+		if f.Name == "__typename" {
+			result.Set(key, object.Name)
+			continue
+		}
+		result.Set(key, nil)
+		field := object.Field(f.Name)
+		args, err := e.arguments(field.Args, f.Arguments)
+		if err != nil {
+			e.fail(err, f, fieldPath)
+			continue
+		}
+		v, err := field.Resolve(Params{Context: e.ctx, Source: source, Args: args})
+		if err != nil {
+			e.fail(err, f, fieldPath)
+			continue
+		}
+		e.complete(field.Type, group, v, fieldPath, func(v interface{}) { result.Set(key, v) })
+	}
+	return result
+}
+
+// complete converts a resolved value to the type of its field
+func (e *executor) complete(t Type, fields []*FieldSelection, v interface{}, path []interface{}, set func(interface{})) {
+	if thunk, ok := v.(Thunk); ok {
+		e.pending = append(e.pending, func() {
+			v, err := thunk()
+			if err != nil {
+				e.fail(err, fields[0], path)
+				return
+			}
+			e.complete(t, fields, v, path, set)
+		})
+		return
+	}
+	if isNull(v) {
+		set(nil)
+		return
+	}
+	switch t := t.(type) {
+	case *Scalar:
+		s, err := t.Serialize(v)
+		if err != nil {
+			e.fail(err, fields[0], path)
+			return
+		}
+		set(s)
+	case *Object:
+		selections := []Selection{}
+		for _, f := range fields {
+			selections = append(selections, f.Selections...)
+		}
+		set(e.selectionSet(t, v, selections, path))
+	case *List:
+		value := reflect.ValueOf(v)
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			e.fail(fmt.Errorf("expected a list, got %T", v), fields[0], path)
+			return
+		}
+		items := make([]interface{}, value.Len())
+		set(items)
+		for i := range items {
+			i := i
+			itemPath := append(append([]interface{}{}, path...), i)
+			e.complete(t.Of, fields, value.Index(i).Interface(), itemPath, func(v interface{}) { items[i] = v })
+		}
+	}
+}
+
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch value := reflect.ValueOf(v); value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
+
+// collectFields groups the fields of a selection set by their key, the
+// fragments are expanded and the skipped fields are left out
+func (e *executor) collectFields(object *Object, selections []Selection, groups [][]*FieldSelection, visited map[string]bool) [][]*FieldSelection {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldSelection:
+			if !e.included(s.Directives) {
+				continue
+			}
+			found := false
+			for i, group := range groups {
+				if group[0].Key() == s.Key() {
+					groups[i] = append(group, s)
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, []*FieldSelection{s})
+			}
+		case *InlineFragment:
+			if e.included(s.Directives) && (s.TypeCondition == "" || s.TypeCondition == object.Name) {
+				groups = e.collectFields(object, s.Selections, groups, visited)
+			}
+		case *FragmentSpread:
+			if visited[s.Name] || !e.included(s.Directives) {
+				continue
+			}
+			visited[s.Name] = true
+			f := e.doc.Fragments[s.Name]
+			if f.TypeCondition == object.Name {
+				groups = e.collectFields(object, f.Selections, groups, visited)
+			}
+		}
+	}
+	return groups
+}
+
+// included evaluates the @skip and @include directives
+func (e *executor) included(directives []*Directive) bool {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		args, err := e.arguments(conditionArgs, d.Arguments)
+		if err != nil {
+			continue
+		}
+		if args["if"] == (d.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+var conditionArgs = []*Arg{{Name: "if", Type: &NonNull{Of: Boolean}}}
+
+// arguments returns the values of the given arguments and the defaults of
+// the others
+func (e *executor) arguments(defs []*Arg, given []*Argument) (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	for _, def := range defs {
+		var arg *Argument
+		for _, a := range given {
+			if a.Name == def.Name {
+				arg = a
+			}
+		}
+		if arg != nil && arg.Value.Kind == VariableValue {
+			if _, ok := e.vars[arg.Value.Raw]; !ok {
+				arg = nil
+			}
+		}
+		if arg == nil {
+			if def.Default != nil {
+				args[def.Name] = def.Default
+			} else if _, ok := def.Type.(*NonNull); ok {
+				return nil, fmt.Errorf("argument %q of type %s is required", def.Name, def.Type)
+			}
+			continue
+		}
+		v, err := coerceLiteral(def.Type, arg.Value, e.vars)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %s", def.Name, err)
+		}
+		if _, ok := def.Type.(*NonNull); ok && v == nil {
+			return nil, fmt.Errorf("argument %q of type %s is required", def.Name, def.Type)
+		}
+		args[def.Name] = v
+	}
+	return args, nil
+}
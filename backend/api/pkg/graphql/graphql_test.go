@@ -0,0 +1,203 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type book struct {
+	ID       int
+	Title    string
+	AuthorID int
+}
+
+type author struct {
+	ID   int
+	Name string
+}
+
+var (
+	books   = []book{{1, "Dune", 1}, {2, "Emma", 2}, {3, "Children of Dune", 1}}
+	authors = map[int]author{1: {1, "Frank Herbert"}, 2: {2, "Jane Austen"}}
+)
+
+// testSchema returns a schema of books whose authors are loaded in batches,
+// the keys of each batch are appended to batches
+func testSchema(t *testing.T, batches *[][]int) *Schema {
+	authorType := &Object{Name: "Author", Fields: []*Field{
+		{Name: "name", Type: String, Resolve: func(p Params) (interface{}, error) {
+			return p.Source.(author).Name, nil
+		}},
+	}}
+	var loader *Loader
+	bookType := &Object{Name: "Book", Description: "A book", Fields: []*Field{
+		{Name: "id", Type: Int, Resolve: func(p Params) (interface{}, error) {
+			return p.Source.(book).ID, nil
+		}},
+		{Name: "title", Type: String, Args: []*Arg{{Name: "upper", Type: Boolean, Default: false}},
+			Resolve: func(p Params) (interface{}, error) {
+				if p.Args["upper"].(bool) {
+					return strings.ToUpper(p.Source.(book).Title), nil
+				}
+				return p.Source.(book).Title, nil
+			}},
+		{Name: "author", Type: authorType, Resolve: func(p Params) (interface{}, error) {
+			return loader.Load(p.Source.(book).AuthorID), nil
+		}},
+	}}
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "books", Type: &List{Of: bookType}, Args: []*Arg{{Name: "ids", Type: &List{Of: &NonNull{Of: Int}}}},
+			Resolve: func(p Params) (interface{}, error) {
+				loader = NewLoader(func(keys []int) (map[int]interface{}, error) {
+					*batches = append(*batches, keys)
+					values := map[int]interface{}{}
+					for _, key := range keys {
+						values[key] = authors[key]
+					}
+					return values, nil
+				})
+				ids, ok := p.Args["ids"].([]interface{})
+				if !ok {
+					return books, nil
+				}
+				selected := []book{}
+				for _, id := range ids {
+					selected = append(selected, books[id.(int)-1])
+				}
+				return selected, nil
+			}},
+		{Name: "book", Type: bookType, Args: []*Arg{{Name: "id", Type: &NonNull{Of: Int}}},
+			Resolve: func(p Params) (interface{}, error) {
+				return books[p.Args["id"].(int)-1], nil
+			}},
+	}}
+	s, err := NewSchema(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func execute(t *testing.T, s *Schema, req Request) string {
+	data, err := json.Marshal(s.Execute(context.Background(), req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestExecute(t *testing.T) {
+	batches := [][]int{}
+	s := testSchema(t, &batches)
+	query := `
+	query Books($ids: [Int!], $upper: Boolean = true) {
+		books(ids: $ids) { ...card author { name } }
+		first: book(id: 1) { title(upper: $upper) __typename }
+	}
+	fragment card on Book { id title author @skip(if: false) { name } }`
+	got := execute(t, s, Request{Query: query, Variables: map[string]interface{}{"ids": []interface{}{3.0, 2.0, 1.0}}})
+	expected := `{"data":{"books":[` +
+		`{"id":3,"title":"Children of Dune","author":{"name":"Frank Herbert"}},` +
+		`{"id":2,"title":"Emma","author":{"name":"Jane Austen"}},` +
+		`{"id":1,"title":"Dune","author":{"name":"Frank Herbert"}}],` +
+		`"first":{"title":"DUNE","__typename":"Book"}}}`
+	if got != expected {
+		t.Errorf("Expected: %s , Got: %s", expected, got)
+	}
+	// the authors of all the books are loaded at once
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Errorf("expected a single batch of 2 authors, got %v", batches)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := testSchema(t, &[][]int{})
+	tests := []struct {
+		query, expected string
+	}{
+		{`{ books { id`, `{"errors":[{"message":"Syntax error: unexpected end of the query","locations":[{"line":1,"column":13}]}]}`},
+		{`{ books { isbn } }`, `{"errors":[{"message":"Cannot query field \"isbn\" on type \"Book\"","locations":[{"line":1,"column":11}]}]}`},
+		{`{ book { id } }`, `{"errors":[{"message":"Argument \"id\" of type Int! is required on Query.book","locations":[{"line":1,"column":3}]}]}`},
+		{`{ book(id: "1") { author } }`, `{"errors":[{"message":"Invalid value: Int cannot represent a string value","locations":[{"line":1,"column":12}]},` +
+			`{"message":"Field \"author\" of type \"Author\" must have a selection of subfields","locations":[{"line":1,"column":19}]}]}`},
+		{`query($id: Int) { book(id: $id) { id } }`, `{"errors":[{"message":"Variable $id of type Int used in position expecting type Int!","locations":[{"line":1,"column":28}]}]}`},
+		{`mutation { books { id } }`, `{"errors":[{"message":"mutation operations are not supported","locations":[{"line":1,"column":1}]}]}`},
+	}
+	for _, tc := range tests {
+		if got := execute(t, s, Request{Query: tc.query}); got != tc.expected {
+			t.Errorf("%s Expected: %s , Got: %s", tc.query, tc.expected, got)
+		}
+	}
+}
+
+func TestSDL(t *testing.T) {
+	expected := `type Query {
+  books(ids: [Int!]): [Book]
+  book(id: Int!): Book
+}
+
+"A book"
+type Book {
+  id: Int
+  title(upper: Boolean = false): String
+  author: Author
+}
+
+type Author {
+  name: String
+}
+`
+	if got := testSchema(t, &[][]int{}).SDL(); got != expected {
+		t.Errorf("Expected: %s , Got: %s", expected, got)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	node := &Object{Name: "Node"}
+	node.Fields = []*Field{
+		{Name: "id", Type: Int, Resolve: func(p Params) (interface{}, error) { return 1, nil }},
+		{Name: "remote", Type: String, Cost: 10, Resolve: func(p Params) (interface{}, error) { return "r", nil }},
+		{Name: "children", Type: &List{Of: node}, Args: []*Arg{{Name: "limit", Type: Int}},
+			Resolve: func(p Params) (interface{}, error) { return []int{1}, nil }},
+	}
+	s, err := NewSchema(&Object{Name: "Query", Fields: []*Field{
+		{Name: "root", Type: node, Resolve: func(p Params) (interface{}, error) { return 1, nil }},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.MaxDepth, s.MaxCost, s.ListSize = 3, 50, 4
+	tests := []struct {
+		query, expected string
+	}{
+		{`{ root { children { id } } }`, `{"data":{"root":{"children":[{"id":1}]}}}`},
+		// 1 + 1 + 4 * 11
+		{`{ root { children { remote } } }`, `{"data":{"root":{"children":[{"remote":"r"}]}}}`},
+		{`{ root { children(limit: 5) { remote } } }`, `{"errors":[{"message":"Query costs more than the maximum of 50","locations":[{"line":1,"column":1}]}]}`},
+		{`{ root { children { children { id } } } }`, `{"errors":[{"message":"Query is nested more than the maximum of 3 levels","locations":[{"line":1,"column":1}]}]}`},
+		{`query($n: Int) { root { children(limit: $n) { id } } }`, `{"data":{"root":{"children":[{"id":1}]}}}`},
+		// the skipped fields are not counted
+		{`{ root { children { children @skip(if: true) { id } id } } }`, `{"data":{"root":{"children":[{"id":1}]}}}`},
+		{`{ root { ...a } } fragment a on Node { a: remote b: remote c: remote d: remote e: remote }`,
+			`{"errors":[{"message":"Query costs more than the maximum of 50","locations":[{"line":1,"column":1}]}]}`},
+	}
+	for _, tc := range tests {
+		if got := execute(t, s, Request{Query: tc.query}); got != tc.expected {
+			t.Errorf("%s Expected: %s , Got: %s", tc.query, tc.expected, got)
+		}
+	}
+
+	// fragments repeated at each level are not expanded beyond the limits
+	s.MaxDepth, s.MaxCost = 0, 1000
+	query := `{ root { ...f0 } }`
+	for i := 0; i < 20; i++ {
+		query += fmt.Sprintf(" fragment f%d on Node { a: children { ...f%d } b: children { ...f%d } }", i, i+1, i+1)
+	}
+	query += " fragment f20 on Node { id }"
+	if got := execute(t, s, Request{Query: query}); !strings.Contains(got, "Query costs more than the maximum of 1000") {
+		t.Errorf("expected the cost to be exceeded, got %s", got)
+	}
+}
@@ -0,0 +1,108 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+)
+
+// defaultListSize is the number of items expected in a list when the schema
+// does not set it
+const defaultListSize = 10
+
+// noLimit bounds the cost of a query when the schema has no limit, so that
+// it cannot overflow
+const noLimit = math.MaxInt32
+
+// limit checks the depth and the cost of an operation before it is
+// executed. Each field costs 1 plus its Cost, and the fields selected on the
+// items of a list are counted for each expected item
+func (e *executor) limit(op *Operation) []*Error {
+	maxDepth, maxCost := e.schema.MaxDepth, int64(e.schema.MaxCost)
+	if maxDepth <= 0 {
+		maxDepth = noLimit
+	}
+	if maxCost <= 0 {
+		maxCost = noLimit
+	}
+	depth, cost := e.measure(e.schema.Query, op.Selections, 1, maxDepth, maxCost)
+	switch {
+	case depth > maxDepth:
+		return []*Error{{Message: fmt.Sprintf("Query is nested more than the maximum of %d levels", maxDepth), Locations: []Location{op.Location}}}
+	case cost > maxCost:
+		return []*Error{{Message: fmt.Sprintf("Query costs more than the maximum of %d", maxCost), Locations: []Location{op.Location}}}
+	}
+	return nil
+}
+
+// measure returns the depth and the cost of a selection set at a level of
+// nesting. It stops as soon as a limit is exceeded, which also bounds the
+// work for the queries repeating fragments
+func (e *executor) measure(object *Object, selections []Selection, level, maxDepth int, budget int64) (int, int64) {
+	depth, total := 0, int64(0)
+	for _, group := range e.collectFields(object, selections, nil, map[string]bool{}) {
+		total++
+		if level > depth {
+			depth = level
+		}
+		if level > maxDepth || total > budget {
+			return depth, total
+		}
+		field := object.Field(group[0].Name)
+		if field == nil {
+			continue
+		}
+		items := int64(1)
+		if _, ok := field.Type.(*List); ok {
+			items = int64(e.listSize(field, group[0]))
+		}
+		total += items * int64(field.Cost)
+		if sub, ok := namedType(field.Type).(*Object); ok && total <= budget {
+			left := (budget - total) / items
+			subDepth, cost := e.measure(sub, merge(group), level+1, maxDepth, left)
+			if subDepth > depth {
+				depth = subDepth
+			}
+			if cost > left {
+				return depth, budget + 1
+			}
+			total += items * cost
+		}
+		if depth > maxDepth || total > budget {
+			return depth, total
+		}
+	}
+	return depth, total
+}
+
+// listSize returns the number of items expected in a list field, the value
+// of its limit argument if it is given. An empty list still counts as one
+// item so that its subfields are checked
+func (e *executor) listSize(field *Field, f *FieldSelection) int {
+	size := e.schema.ListSize
+	if size <= 0 {
+		size = defaultListSize
+	}
+	if field.ListSize > 0 {
+		size = field.ListSize
+	}
+	if args, err := e.arguments(field.Args, f.Arguments); err == nil {
+		if limit, ok := args["limit"].(int); ok {
+			size = limit
+		}
+	}
+	if size < 1 {
+		return 1
+	}
+	if size > noLimit {
+		return noLimit
+	}
+	return size
+}
+
+func merge(group []*FieldSelection) []Selection {
+	selections := []Selection{}
+	for _, f := range group {
+		selections = append(selections, f.Selections...)
+	}
+	return selections
+}
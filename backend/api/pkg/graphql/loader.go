@@ -0,0 +1,90 @@
+package graphql
+
+// BatchFunc fetches the values of a batch of keys, the keys without a value
+// are left out of the map
+type BatchFunc func(keys []int) (map[int]interface{}, error)
+
+// Loader batches the loads of values by int keys, such as ids, which are
+// queued while a query is executed. The keys queued when the first of their
+// thunks is called are fetched at once, and the values are cached for the
+// rest of the query. A loader is not safe for concurrent use, it is meant to
+// be created for each query
+type Loader struct {
+	fetch BatchFunc
+	cache map[int]*loaded
+	queue []int
+}
+
+type loaded struct {
+	done  bool
+	value interface{}
+	err   error
+}
+
+// NewLoader returns a loader fetching its values with fn
+func NewLoader(fn BatchFunc) *Loader {
+	return &Loader{fetch: fn, cache: map[int]*loaded{}}
+}
+
+// Load queues a key and returns the thunk of its value
+func (l *Loader) Load(key int) Thunk {
+	r, ok := l.cache[key]
+	if !ok {
+		r = &loaded{}
+		l.cache[key] = r
+		l.queue = append(l.queue, key)
+	}
+	return func() (interface{}, error) {
+		if !r.done {
+			l.dispatch()
+		}
+		return r.value, r.err
+	}
+}
+
+// LoadMany queues keys and returns the thunk of the list of their values
+func (l *Loader) LoadMany(keys []int) Thunk {
+	thunks := make([]Thunk, len(keys))
+	for i, key := range keys {
+		thunks[i] = l.Load(key)
+	}
+	return func() (interface{}, error) {
+		values := make([]interface{}, len(thunks))
+		for i, thunk := range thunks {
+			v, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+}
+
+// Prime caches the value of a key, e.g. of a resource loaded in a list
+func (l *Loader) Prime(key int, value interface{}) {
+	r, ok := l.cache[key]
+	if !ok {
+		l.cache[key] = &loaded{done: true, value: value}
+	} else if !r.done {
+		r.done, r.value = true, value
+	}
+}
+
+func (l *Loader) dispatch() {
+	keys := []int{}
+	for _, key := range l.queue {
+		if !l.cache[key].done {
+			keys = append(keys, key)
+		}
+	}
+	l.queue = nil
+	if len(keys) == 0 {
+		return
+	}
+	values, err := l.fetch(keys)
+	for _, key := range keys {
+		r := l.cache[key]
+		r.done, r.value, r.err = true, values[key], err
+	}
+}
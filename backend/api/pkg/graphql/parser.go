@@ -0,0 +1,637 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kinds of the tokens of a query
+const (
+	tokenEOF    = "<EOF>"
+	tokenPunct  = "Punctuator"
+	tokenName   = "Name"
+	tokenInt    = "Int"
+	tokenFloat  = "Float"
+	tokenString = "String"
+)
+
+type token struct {
+	kind  string
+	value string
+	loc   Location
+}
+
+// lexer splits a query in tokens, skipping the white space, commas and
+// comments
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	// lineStart is the offset of the current line
+	lineStart int
+}
+
+func (l *lexer) location() Location {
+	return Location{Line: l.line, Column: l.pos - l.lineStart + 1}
+}
+
+func (l *lexer) newline() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == '\n':
+			l.pos++
+			l.newline()
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\uFEFF"):
+			l.pos += len("\uFEFF")
+		default:
+			return
+		}
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	loc := l.location()
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, loc: loc}, nil
+	}
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokenPunct, value: "...", loc: loc}, nil
+	case strings.IndexByte("!$&()*:=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(c), loc: loc}, nil
+	case isNameStart(c):
+		for l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.blockString(loc)
+		}
+		return l.string(loc)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, syntaxError(loc, "unexpected character %q", r)
+}
+
+func (l *lexer) number(loc Location) (token, error) {
+	start := l.pos
+	kind := tokenInt
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := func() error {
+		if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+			return syntaxError(l.location(), "invalid number %q", l.src[start:l.pos])
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+		return nil
+	}
+	if err := digits(); err != nil {
+		return token{}, err
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if err := digits(); err != nil {
+			return token{}, err
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if err := digits(); err != nil {
+			return token{}, err
+		}
+	}
+	if l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || l.src[l.pos] == '.') {
+		return token{}, syntaxError(l.location(), "invalid number %q", l.src[start:l.pos+1])
+	}
+	return token{kind: kind, value: l.src[start:l.pos], loc: loc}, nil
+}
+
+var escapes = map[byte]string{'"': `"`, '\\': `\`, '/': "/", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t"}
+
+func (l *lexer) string(loc Location) (token, error) {
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokenString, value: b.String(), loc: loc}, nil
+		case c == '\n' || c == '\r':
+			return token{}, syntaxError(l.location(), "unterminated string")
+		case c == '\\' && l.pos+1 < len(l.src):
+			e := l.src[l.pos+1]
+			if s, ok := escapes[e]; ok {
+				b.WriteString(s)
+				l.pos += 2
+				continue
+			}
+			if e != 'u' || l.pos+6 > len(l.src) {
+				return token{}, syntaxError(l.location(), "invalid escape sequence")
+			}
+			code, err := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 32)
+			if err != nil {
+				return token{}, syntaxError(l.location(), "invalid escape sequence %q", l.src[l.pos:l.pos+6])
+			}
+			b.WriteRune(rune(code))
+			l.pos += 6
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, syntaxError(l.location(), "unterminated string")
+}
+
+// blockString reads a """ string, the common indentation of its lines and
+// its leading and trailing blank lines are removed
+func (l *lexer) blockString(loc Location) (token, error) {
+	l.pos += 3
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.pos += 3
+			return token{kind: tokenString, value: blockStringValue(b.String()), loc: loc}, nil
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			b.WriteString(`"""`)
+			l.pos += 4
+		default:
+			b.WriteByte(l.src[l.pos])
+			l.pos++
+			if l.src[l.pos-1] == '\n' {
+				l.newline()
+			}
+		}
+	}
+	return token{}, syntaxError(l.location(), "unterminated string")
+}
+
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.Replace(raw, "\r\n", "\n", -1), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parser builds the document of a query from its tokens
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+// Parse parses a query document, the operations and fragments are not
+// validated against a schema
+func Parse(query string) (*Document, error) {
+	p := &parser{lexer: &lexer{src: query, line: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	doc := &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunct, "{"), p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peek(tokenName, "fragment"):
+			f, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.Fragments[f.Name]; ok {
+				return nil, syntaxError(f.Location, "there can be only one fragment named %q", f.Name)
+			}
+			doc.Fragments[f.Name] = f
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, syntaxError(p.tok.loc, "the document has no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peek(kind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokenEOF {
+		return syntaxError(p.tok.loc, "unexpected end of the query")
+	}
+	return syntaxError(p.tok.loc, "unexpected %s %q", p.tok.kind, p.tok.value)
+}
+
+// skip advances past the punctuator if it is the current token
+func (p *parser) skip(punct string) (bool, error) {
+	if !p.peek(tokenPunct, punct) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(tokenPunct, punct) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Type: "query", Location: p.tok.loc}
+	if p.tok.kind == tokenName {
+		op.Type = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenName {
+			op.Name = p.tok.value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if p.peek(tokenPunct, "(") {
+			vars, err := p.variableDefinitions()
+			if err != nil {
+				return nil, err
+			}
+			op.Variables = vars
+		}
+		directives, err := p.directives()
+		if err != nil {
+			return nil, err
+		}
+		op.Directives = directives
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = selections
+	return op, nil
+}
+
+func (p *parser) variableDefinitions() ([]*VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	vars := []*VariableDefinition{}
+	for !p.peek(tokenPunct, ")") {
+		v := &VariableDefinition{Location: p.tok.loc}
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		v.Name = name
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if v.Type, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if v.Default, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		vars = append(vars, v)
+	}
+	return vars, p.advance()
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	t := &TypeRef{}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if t.Elem, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t.Name = name
+	}
+	ok, err := p.skip("!")
+	t.NonNull = ok
+	return t, err
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	f := &Fragment{Location: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, syntaxError(f.Location, "a fragment cannot be named \"on\"")
+	}
+	f.Name = name
+	if !p.peek(tokenName, "on") {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if f.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	f.Selections, err = p.selectionSet()
+	return f, err
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	selections := []Selection{}
+	for !p.peek(tokenPunct, "}") {
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+	if len(selections) == 0 {
+		return nil, syntaxError(p.tok.loc, "empty selection set")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (Selection, error) {
+	loc := p.tok.loc
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		return p.fragmentSelection(loc)
+	}
+	f := &FieldSelection{Location: loc}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	f.Name = name
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.Alias = name
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.Arguments, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunct, "{") {
+		f.Selections, err = p.selectionSet()
+	}
+	return f, err
+}
+
+func (p *parser) fragmentSelection(loc Location) (Selection, error) {
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &FragmentSpread{Name: p.tok.value, Location: loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		directives, err := p.directives()
+		spread.Directives = directives
+		return spread, err
+	}
+	f := &InlineFragment{Location: loc}
+	var err error
+	if p.peek(tokenName, "on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if f.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	f.Selections, err = p.selectionSet()
+	return f, err
+}
+
+func (p *parser) arguments(constant bool) ([]*Argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	args := []*Argument{}
+	for !p.peek(tokenPunct, ")") {
+		arg := &Argument{Location: p.tok.loc}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		arg.Name = name
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.Value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*Directive, error) {
+	directives := []*Directive{}
+	for p.peek(tokenPunct, "@") {
+		d := &Directive{Location: p.tok.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		d.Name = name
+		if d.Arguments, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+// value parses a value, variables are not allowed in constant values such
+// as the defaults of variables
+func (p *parser) value(constant bool) (Value, error) {
+	v := Value{Location: p.tok.loc, Raw: p.tok.value}
+	switch p.tok.kind {
+	case tokenInt:
+		v.Kind = IntValue
+	case tokenFloat:
+		v.Kind = FloatValue
+	case tokenString:
+		v.Kind = StringValue
+	case tokenName:
+		switch p.tok.value {
+		case "true", "false":
+			v.Kind = BooleanValue
+		case "null":
+			v.Kind = NullValue
+		default:
+			v.Kind = EnumValue
+		}
+	case tokenPunct:
+		switch p.tok.value {
+		case "$":
+			if constant {
+				return v, syntaxError(v.Location, "unexpected variable in a constant value")
+			}
+			if err := p.advance(); err != nil {
+				return v, err
+			}
+			name, err := p.name()
+			v.Kind, v.Raw = VariableValue, name
+			return v, err
+		case "[":
+			v.Kind, v.List = ListValue, []Value{}
+			if err := p.advance(); err != nil {
+				return v, err
+			}
+			for !p.peek(tokenPunct, "]") {
+				item, err := p.value(constant)
+				if err != nil {
+					return v, err
+				}
+				v.List = append(v.List, item)
+			}
+			return v, p.advance()
+		case "{":
+			v.Kind, v.Fields = ObjectValue, []*Argument{}
+			if err := p.advance(); err != nil {
+				return v, err
+			}
+			for !p.peek(tokenPunct, "}") {
+				field := &Argument{Location: p.tok.loc}
+				name, err := p.name()
+				if err != nil {
+					return v, err
+				}
+				field.Name = name
+				if err := p.expect(":"); err != nil {
+					return v, err
+				}
+				if field.Value, err = p.value(constant); err != nil {
+					return v, err
+				}
+				v.Fields = append(v.Fields, field)
+			}
+			return v, p.advance()
+		}
+	}
+	if v.Kind == "" {
+		return v, p.unexpected()
+	}
+	return v, p.advance()
+}
+
+func syntaxError(loc Location, format string, args ...interface{}) *Error {
+	return &Error{Message: "Syntax error: " + fmt.Sprintf(format, args...), Locations: []Location{loc}}
+}
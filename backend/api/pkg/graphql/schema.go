@@ -0,0 +1,392 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Type is a *Scalar, an *Object, a *List or, for arguments only, a *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved value to its JSON value
+	Serialize func(v interface{}) (interface{}, error)
+	// ParseValue coerces the value of a variable, as decoded from JSON
+	ParseValue func(v interface{}) (interface{}, error)
+	// ParseLiteral coerces a value written in a query
+	ParseLiteral func(v Value) (interface{}, error)
+}
+
+// Object is a type made of fields. The values of its fields are always
+// nullable, so that an error only nulls the field it occurred in
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+// List is a list of values of a type
+type List struct {
+	Of Type
+}
+
+// NonNull is a type whose values cannot be null, it is only allowed for
+// arguments
+type NonNull struct {
+	Of Type
+}
+
+func (s *Scalar) String() string  { return s.Name }
+func (o *Object) String() string  { return o.Name }
+func (l *List) String() string    { return "[" + l.Of.String() + "]" }
+func (n *NonNull) String() string { return n.Of.String() + "!" }
+
+// Field returns the field with the given name, nil if there is none
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field is a field of an object
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Arg
+	Resolve     ResolveFunc
+	// Cost is added to the cost of the query for each value of the field,
+	// e.g. for a field fetched from another service
+	Cost int
+	// ListSize is the number of items expected in a list field when it has
+	// no limit argument, the ListSize of the schema by default
+	ListSize int
+}
+
+// Arg is an argument of a field
+type Arg struct {
+	Name        string
+	Description string
+	Type        Type
+	// Default is used when the argument is not given, it must be a value of
+	// the coerced type, e.g. an int for Int
+	Default interface{}
+}
+
+// ResolveFunc returns the value of a field. It may return a Thunk to defer
+// the resolution until the other fields of the query are resolved, which is
+// how the loads of a Loader are batched
+type ResolveFunc func(p Params) (interface{}, error)
+
+// Thunk returns a deferred value
+type Thunk func() (interface{}, error)
+
+// Params are given to the resolvers of the fields
+type Params struct {
+	Context context.Context
+	// Source is the value of the object whose field is resolved, nil for
+	// the query type
+	Source interface{}
+	// Args holds the coerced value of the arguments which are given or have
+	// a default
+	Args map[string]interface{}
+}
+
+// Then returns a thunk transforming the value of a thunk
+func Then(t Thunk, fn func(v interface{}) (interface{}, error)) Thunk {
+	return func() (interface{}, error) {
+		v, err := t()
+		if err != nil {
+			return nil, err
+		}
+		return fn(v)
+	}
+}
+
+// Schema holds the types of the queries
+type Schema struct {
+	Query *Object
+	// MaxDepth is the number of nested fields a query may select and
+	// MaxCost the number of values it may resolve, there is no limit when
+	// they are 0
+	MaxDepth int
+	MaxCost  int
+	// ListSize is the number of items expected in a list, for the cost of
+	// the fields selected on its items
+	ListSize int
+	types    map[string]Type
+	order    []string
+}
+
+// NewSchema returns the schema of the given query type, the types reachable
+// from it are checked
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{Query: query, types: map[string]Type{}}
+	for _, scalar := range []*Scalar{Int, Float, String, Boolean} {
+		s.types[scalar.Name] = scalar
+	}
+	if err := s.add(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func namedType(t Type) Type {
+	for {
+		switch wrapper := t.(type) {
+		case *List:
+			t = wrapper.Of
+		case *NonNull:
+			t = wrapper.Of
+		default:
+			return t
+		}
+	}
+}
+
+func (s *Schema) add(t Type) error {
+	name := t.String()
+	if other, ok := s.types[name]; ok {
+		if other != t {
+			return fmt.Errorf("graphql: two types are named %q", name)
+		}
+		if _, ok := t.(*Scalar); ok {
+			s.use(name)
+		}
+		return nil
+	}
+	s.types[name] = t
+	s.use(name)
+	object, ok := t.(*Object)
+	if !ok {
+		return nil
+	}
+	for _, f := range object.Fields {
+		where := object.Name + "." + f.Name
+		if f.Resolve == nil {
+			return fmt.Errorf("graphql: %s has no resolver", where)
+		}
+		if strings.Contains(f.Type.String(), "!") {
+			return fmt.Errorf("graphql: %s is non-null, only arguments can be", where)
+		}
+		if err := s.add(namedType(f.Type)); err != nil {
+			return err
+		}
+		for _, arg := range f.Args {
+			if _, ok := namedType(arg.Type).(*Scalar); !ok {
+				return fmt.Errorf("graphql: argument %q of %s is not a scalar or a list of scalars", arg.Name, where)
+			}
+			if err := s.add(namedType(arg.Type)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// use records the order in which the types are found for the SDL
+func (s *Schema) use(name string) {
+	for _, used := range s.order {
+		if used == name {
+			return
+		}
+	}
+	s.order = append(s.order, name)
+}
+
+// inputType returns the type of a variable
+func (s *Schema) inputType(ref *TypeRef) (Type, error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := s.inputType(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = &List{Of: elem}
+	} else {
+		scalar, ok := s.types[ref.Name].(*Scalar)
+		if !ok {
+			return nil, fmt.Errorf("unknown input type %q", ref.Name)
+		}
+		t = scalar
+	}
+	if ref.NonNull {
+		t = &NonNull{Of: t}
+	}
+	return t, nil
+}
+
+// SDL describes the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for _, name := range s.order {
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			if t == Int || t == Float || t == String || t == Boolean {
+				continue
+			}
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "scalar %s\n\n", t.Name)
+		case *Object:
+			writeDescription(&b, "", t.Description)
+			fmt.Fprintf(&b, "type %s {\n", t.Name)
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				b.WriteString("  " + f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, len(f.Args))
+					for i, arg := range f.Args {
+						args[i] = arg.Name + ": " + arg.Type.String()
+						if arg.Default != nil {
+							args[i] += " = " + literal(arg.Default)
+						}
+					}
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + f.Type.String() + "\n")
+			}
+			b.WriteString("}\n\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeDescription(b *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	if strings.Contains(description, "\n") {
+		description = strings.Replace(description, "\n", "\n"+indent, -1)
+		fmt.Fprintf(b, "%s\"\"\"\n%s%s\n%s\"\"\"\n", indent, indent, description, indent)
+		return
+	}
+	fmt.Fprintf(b, "%s%s\n", indent, literal(description))
+}
+
+// literal writes a value in the syntax of a query
+func literal(v interface{}) string {
+	if items, ok := v.([]interface{}); ok {
+		s := make([]string, len(items))
+		for i, item := range items {
+			s[i] = literal(item)
+		}
+		return "[" + strings.Join(s, ", ") + "]"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func coercionError(t string, v interface{}) error {
+	return fmt.Errorf("%s cannot represent %s", t, literal(v))
+}
+
+func toInt(v interface{}) (interface{}, error) {
+	switch n := v.(type) {
+	case int:
+		if n >= math.MinInt32 && n <= math.MaxInt32 {
+			return n, nil
+		}
+	case int32:
+		return int(n), nil
+	case int64:
+		if n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int(n), nil
+		}
+	case float64:
+		if n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32 {
+			return int(n), nil
+		}
+	case json.Number:
+		if i, err := strconv.ParseInt(string(n), 10, 32); err == nil {
+			return int(i), nil
+		}
+	}
+	return nil, coercionError("Int", v)
+}
+
+func toFloat(v interface{}) (interface{}, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	return nil, coercionError("Float", v)
+}
+
+func toString(v interface{}) (interface{}, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case fmt.Stringer:
+		return s.String(), nil
+	}
+	return nil, coercionError("String", v)
+}
+
+func toBoolean(v interface{}) (interface{}, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	return nil, coercionError("Boolean", v)
+}
+
+func parseLiteral(name string, kinds ...string) func(v Value) (interface{}, error) {
+	return func(v Value) (interface{}, error) {
+		for _, kind := range kinds {
+			if v.Kind != kind {
+				continue
+			}
+			switch kind {
+			case IntValue:
+				n, err := strconv.ParseInt(v.Raw, 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("%s cannot represent %s", name, v.Raw)
+				}
+				if name == "Float" {
+					return float64(n), nil
+				}
+				return int(n), nil
+			case FloatValue:
+				return strconv.ParseFloat(v.Raw, 64)
+			case BooleanValue:
+				return v.Raw == "true", nil
+			default:
+				return v.Raw, nil
+			}
+		}
+		return nil, fmt.Errorf("%s cannot represent a %s value", name, strings.ToLower(v.Kind))
+	}
+}
+
+// The built-in scalars
+var (
+	Int = &Scalar{Name: "Int", Description: "A signed 32-bit integer",
+		Serialize: toInt, ParseValue: toInt, ParseLiteral: parseLiteral("Int", IntValue)}
+	Float = &Scalar{Name: "Float", Description: "A double-precision floating point number",
+		Serialize: toFloat, ParseValue: toFloat, ParseLiteral: parseLiteral("Float", IntValue, FloatValue)}
+	String = &Scalar{Name: "String", Description: "A UTF-8 string",
+		Serialize: toString, ParseValue: toString, ParseLiteral: parseLiteral("String", StringValue)}
+	Boolean = &Scalar{Name: "Boolean", Description: "true or false",
+		Serialize: toBoolean, ParseValue: toBoolean, ParseLiteral: parseLiteral("Boolean", BooleanValue)}
+)
@@ -0,0 +1,240 @@
+package graphql
+
+import "fmt"
+
+// validator checks an operation against the schema before it is executed
+type validator struct {
+	schema *Schema
+	doc    *Document
+	vars   map[string]*VariableDefinition
+	errors []*Error
+	// spreading holds the fragments being validated, to find cycles
+	spreading []string
+	// validated holds the fragments already validated, they are only
+	// validated once however often they are spread
+	validated map[string]bool
+}
+
+func (v *validator) fail(loc Location, format string, args ...interface{}) {
+	v.errors = append(v.errors, &Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+func (v *validator) validate(op *Operation) []*Error {
+	for _, def := range op.Variables {
+		if _, ok := v.vars[def.Name]; ok {
+			v.fail(def.Location, "There can be only one variable named $%s", def.Name)
+		}
+		v.vars[def.Name] = def
+	}
+	for name, f := range v.doc.Fragments {
+		if _, ok := v.schema.types[f.TypeCondition].(*Object); !ok {
+			v.fail(f.Location, "Fragment %q cannot condition on the unknown type %q", name, f.TypeCondition)
+		}
+	}
+	v.selectionSet(v.schema.Query, op.Selections)
+	return v.errors
+}
+
+func (v *validator) selectionSet(object *Object, selections []Selection) {
+	fields := map[string]*FieldSelection{}
+	for _, f := range v.flatten(object, selections, map[string]bool{}) {
+		if other, ok := fields[f.Key()]; ok && other.Name != f.Name {
+			v.fail(f.Location, "Fields %q conflict because %s and %s are different fields", f.Key(), other.Name, f.Name)
+		}
+		fields[f.Key()] = f
+	}
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldSelection:
+			v.directives(s.Directives)
+			v.field(object, s)
+		case *InlineFragment:
+			v.directives(s.Directives)
+			if s.TypeCondition != "" && s.TypeCondition != object.Name {
+				v.fail(s.Location, "Fragment cannot be spread here as objects of type %q can never be of type %q", object.Name, s.TypeCondition)
+				continue
+			}
+			v.selectionSet(object, s.Selections)
+		case *FragmentSpread:
+			v.directives(s.Directives)
+			f, ok := v.doc.Fragments[s.Name]
+			if !ok {
+				v.fail(s.Location, "Unknown fragment %q", s.Name)
+				continue
+			}
+			if f.TypeCondition != object.Name {
+				v.fail(s.Location, "Fragment %q cannot be spread here as objects of type %q can never be of type %q", s.Name, object.Name, f.TypeCondition)
+				continue
+			}
+			for _, name := range v.spreading {
+				if name == s.Name {
+					v.fail(s.Location, "Cannot spread fragment %q within itself", s.Name)
+					return
+				}
+			}
+			if v.validated[s.Name] {
+				continue
+			}
+			v.spreading = append(v.spreading, s.Name)
+			v.selectionSet(object, f.Selections)
+			v.spreading = v.spreading[:len(v.spreading)-1]
+			v.validated[s.Name] = true
+		}
+	}
+}
+
+// flatten returns the fields of a selection set through its fragments
+func (v *validator) flatten(object *Object, selections []Selection, visited map[string]bool) []*FieldSelection {
+	fields := []*FieldSelection{}
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldSelection:
+			fields = append(fields, s)
+		case *InlineFragment:
+			if s.TypeCondition == "" || s.TypeCondition == object.Name {
+				fields = append(fields, v.flatten(object, s.Selections, visited)...)
+			}
+		case *FragmentSpread:
+			if f, ok := v.doc.Fragments[s.Name]; ok && !visited[s.Name] && f.TypeCondition == object.Name {
+				visited[s.Name] = true
+				fields = append(fields, v.flatten(object, f.Selections, visited)...)
+			}
+		}
+	}
+	return fields
+}
+
+func (v *validator) field(object *Object, s *FieldSelection) {
+	if s.Name == "__typename" {
+		v.arguments(nil, s.Arguments, s.Name, s.Location)
+		if len(s.Selections) > 0 {
+			v.fail(s.Location, "Field %q must not have a selection since type %q has no subfields", s.Name, String.Name)
+		}
+		return
+	}
+	field := object.Field(s.Name)
+	if field == nil {
+		v.fail(s.Location, "Cannot query field %q on type %q", s.Name, object.Name)
+		return
+	}
+	v.arguments(field.Args, s.Arguments, fmt.Sprintf("%s.%s", object.Name, s.Name), s.Location)
+	switch t := namedType(field.Type).(type) {
+	case *Scalar:
+		if len(s.Selections) > 0 {
+			v.fail(s.Location, "Field %q must not have a selection since type %q has no subfields", s.Name, field.Type)
+		}
+	case *Object:
+		if len(s.Selections) == 0 {
+			v.fail(s.Location, "Field %q of type %q must have a selection of subfields", s.Name, field.Type)
+			return
+		}
+		v.selectionSet(t, s.Selections)
+	}
+}
+
+func (v *validator) directives(directives []*Directive) {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			v.fail(d.Location, "Unknown directive @%s", d.Name)
+			continue
+		}
+		v.arguments(conditionArgs, d.Arguments, "@"+d.Name, d.Location)
+	}
+}
+
+func (v *validator) arguments(defs []*Arg, given []*Argument, where string, loc Location) {
+	seen := map[string]bool{}
+	for _, arg := range given {
+		if seen[arg.Name] {
+			v.fail(arg.Location, "There can be only one argument named %q", arg.Name)
+		}
+		seen[arg.Name] = true
+		var def *Arg
+		for _, d := range defs {
+			if d.Name == arg.Name {
+				def = d
+			}
+		}
+		if def == nil {
+			v.fail(arg.Location, "Unknown argument %q on %s", arg.Name, where)
+			continue
+		}
+		v.value(def.Type, arg.Value, def.Default != nil)
+	}
+	for _, def := range defs {
+		if _, ok := def.Type.(*NonNull); ok && !seen[def.Name] && def.Default == nil {
+			v.fail(loc, "Argument %q of type %s is required on %s", def.Name, def.Type, where)
+		}
+	}
+}
+
+// value checks a value against the type expected where it is used, the
+// literals are coerced and the types of the variables are compared
+func (v *validator) value(t Type, value Value, hasDefault bool) {
+	if value.Kind == VariableValue {
+		def, ok := v.vars[value.Raw]
+		if !ok {
+			v.fail(value.Location, "Variable $%s is not defined", value.Raw)
+			return
+		}
+		varType, err := v.schema.inputType(def.Type)
+		if err != nil {
+			// reported when the variables are coerced
+			return
+		}
+		if !assignable(varType, t, def.Default.Kind != "" || hasDefault) {
+			v.fail(value.Location, "Variable $%s of type %s used in position expecting type %s", value.Raw, def.Type, t)
+		}
+		return
+	}
+	if value.Kind == ListValue {
+		elem := t
+		if nonNull, ok := elem.(*NonNull); ok {
+			elem = nonNull.Of
+		}
+		if list, ok := elem.(*List); ok {
+			for _, item := range value.List {
+				v.value(list.Of, item, false)
+			}
+			return
+		}
+	}
+	if containsVariable(value) {
+		return
+	}
+	if _, err := coerceLiteral(t, value, nil); err != nil {
+		v.fail(value.Location, "Invalid value: %s", err)
+	}
+}
+
+func containsVariable(value Value) bool {
+	if value.Kind == VariableValue {
+		return true
+	}
+	for _, item := range value.List {
+		if containsVariable(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignable checks if a variable of a type can be used where another type
+// is expected, a nullable variable with a default can be used for a
+// non-null type
+func assignable(varType, expected Type, hasDefault bool) bool {
+	if nonNull, ok := expected.(*NonNull); ok {
+		if varNonNull, ok := varType.(*NonNull); ok {
+			return assignable(varNonNull.Of, nonNull.Of, false)
+		}
+		return hasDefault && assignable(varType, nonNull.Of, false)
+	}
+	if varNonNull, ok := varType.(*NonNull); ok {
+		return assignable(varNonNull.Of, expected, false)
+	}
+	if list, ok := expected.(*List); ok {
+		varList, ok := varType.(*List)
+		return ok && assignable(varList.Of, list.Of, false)
+	}
+	return varType == expected
+}
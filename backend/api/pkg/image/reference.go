@@ -0,0 +1,113 @@
+package image
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultRegistry is the registry of images which do not name one
+const DefaultRegistry = "docker.io"
+
+// params matches the references to params in an image, e.g.
+// $(params.image), $(inputs.params.image) or $(params["image"])
+var params = regexp.MustCompile(`\$\((?:inputs\.)?params(?:\.([\w-]+)|\[["']([\w.-]+)["']\])\)`)
+
+// Reference is a parsed container image reference
+type Reference struct {
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+// Parse splits an image reference into its registry, repository, tag and
+// digest. Images of Docker Hub are normalised, e.g. alpine:3.9 is read as
+// docker.io/library/alpine:3.9
+func Parse(ref string) (Reference, error) {
+	r := Reference{}
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.ContainsAny(ref, " \t$()") {
+		return r, fmt.Errorf("invalid image reference %q", ref)
+	}
+	if i := strings.Index(ref, "@"); i != -1 {
+		r.Digest = ref[i+1:]
+		ref = ref[:i]
+		if !strings.Contains(r.Digest, ":") {
+			return Reference{}, fmt.Errorf("invalid digest in image reference %q", ref)
+		}
+	}
+	name := ref
+	if i := strings.LastIndex(ref, ":"); i != -1 && !strings.Contains(ref[i+1:], "/") {
+		name = ref[:i]
+		r.Tag = ref[i+1:]
+	}
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		r.Registry = parts[0]
+		r.Repository = parts[1]
+	} else {
+		r.Registry = DefaultRegistry
+		r.Repository = name
+	}
+	if r.Registry == DefaultRegistry && !strings.Contains(r.Repository, "/") {
+		r.Repository = "library/" + r.Repository
+	}
+	if r.Repository == "" || r.Tag == "" && strings.HasSuffix(ref, ":") {
+		return Reference{}, fmt.Errorf("invalid image reference %q", ref)
+	}
+	return r, nil
+}
+
+// Name returns the registry and repository of the image
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// Matches checks if the image is selected by query. A query without tag or
+// digest selects all the tags of the repository
+func (r Reference) Matches(query Reference) bool {
+	if r.Name() != query.Name() {
+		return false
+	}
+	if query.Digest != "" && query.Digest != r.Digest {
+		return false
+	}
+	if query.Tag != "" && query.Tag != r.Tag {
+		return false
+	}
+	return true
+}
+
+// Resolve replaces the params used in an image by their default value, the
+// image is returned as is if a param has no default
+func Resolve(image string, defaults map[string]string) (string, bool) {
+	resolved := true
+	result := params.ReplaceAllStringFunc(image, func(match string) string {
+		groups := params.FindStringSubmatch(match)
+		name := groups[1]
+		if name == "" {
+			name = groups[2]
+		}
+		if value, ok := defaults[name]; ok {
+			return value
+		}
+		resolved = false
+		return match
+	})
+	if !resolved {
+		return image, false
+	}
+	return result, true
+}
@@ -0,0 +1,71 @@
+package image
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected Reference
+	}{
+		{"alpine", Reference{Registry: "docker.io", Repository: "library/alpine"}},
+		{"alpine:3.9", Reference{Registry: "docker.io", Repository: "library/alpine", Tag: "3.9"}},
+		{"lachlanevenson/k8s-kubectl:v1.14.1", Reference{Registry: "docker.io", Repository: "lachlanevenson/k8s-kubectl", Tag: "v1.14.1"}},
+		{"gcr.io/kaniko-project/executor:v0.13.0", Reference{Registry: "gcr.io", Repository: "kaniko-project/executor", Tag: "v0.13.0"}},
+		{"localhost:5000/app", Reference{Registry: "localhost:5000", Repository: "app"}},
+		{"quay.io/buildah/stable@sha256:abc", Reference{Registry: "quay.io", Repository: "buildah/stable", Digest: "sha256:abc"}},
+		{"ubuntu:18.04@sha256:abc", Reference{Registry: "docker.io", Repository: "library/ubuntu", Tag: "18.04", Digest: "sha256:abc"}},
+	}
+	for _, tc := range tests {
+		r, err := Parse(tc.ref)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.ref, err)
+			continue
+		}
+		if r != tc.expected {
+			t.Errorf("%s: expected %+v, got %+v", tc.ref, tc.expected, r)
+		}
+	}
+	for _, ref := range []string{"", "$(params.image)", "alpine:", "alpine@123"} {
+		if _, err := Parse(ref); err == nil {
+			t.Errorf("%q: expected an error", ref)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	r, _ := Parse("docker.io/library/alpine:3.9")
+	for query, expected := range map[string]bool{
+		"alpine":            true,
+		"alpine:3.9":        true,
+		"alpine:3.10":       false,
+		"ubuntu:3.9":        false,
+		"alpine@sha256:abc": false,
+	} {
+		q, _ := Parse(query)
+		if r.Matches(q) != expected {
+			t.Errorf("%s: expected %v", query, expected)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	defaults := map[string]string{"BUILDER_IMAGE": "quay.io/buildah/stable:v1.11.0", "tag": "3.9"}
+	tests := []struct {
+		image, expected string
+		resolved        bool
+	}{
+		{"$(inputs.params.BUILDER_IMAGE)", "quay.io/buildah/stable:v1.11.0", true},
+		{"alpine:$(params.tag)", "alpine:3.9", true},
+		{`alpine:$(params["tag"])`, "alpine:3.9", true},
+		{"$(params.missing)", "$(params.missing)", false},
+		{"alpine", "alpine", true},
+	}
+	for _, tc := range tests {
+		image, resolved := Resolve(tc.image, defaults)
+		if image != tc.expected || resolved != tc.resolved {
+			t.Errorf("%s: expected %q %v, got %q %v", tc.image, tc.expected, tc.resolved, image, resolved)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditUpload       = "resource.upload"
+	AuditSync         = "resource.sync"
+	AuditImport       = "resource.import"
+	AuditDelete       = "resource.delete"
+	AuditRestore      = "resource.restore"
+	AuditPurge        = "resource.purge"
+	AuditDeprecate    = "resource.deprecate"
+	AuditUndeprecate  = "resource.undeprecate"
+	AuditVerify       = "resource.verify"
+	AuditRatingAdd    = "rating.add"
+	AuditRatingUpdate = "rating.update"
+	AuditLogin        = "user.login"
+	AuditScanImport   = "scan.import"
+	AuditTokenCreate  = "token.create"
+	AuditTokenRevoke  = "token.revoke"
+)
+
+// Types of the targets of audit events
+const (
+	ResourceTarget = "resource"
+	UserTarget     = "user"
+	ImageTarget    = "image"
+	TokenTarget    = "token"
+)
+
+// AuditSystem is the actor of the actions the hub runs by itself
+const AuditSystem = "system"
+
+// AuditEvent records a write operation on the hub. Events are only added,
+// they outlive the target they refer to.
+type AuditEvent struct {
+	ID         int    `gorm:"primary_key;auto_increment" json:"id"`
+	Action     string `gorm:"not null;index" json:"action"`
+	Actor      string `gorm:"index" json:"actor"`
+	TargetType string `gorm:"index" json:"target_type"`
+	TargetID   int    `gorm:"index" json:"target_id"`
+	// Before and After are JSON summaries of the target around the action
+	Before    string    `gorm:"type:text" json:"before,omitempty"`
+	After     string    `gorm:"type:text" json:"after,omitempty"`
+	RequestID string    `gorm:"index" json:"request_id,omitempty"`
+	SourceIP  string    `json:"source_ip,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// AuditSummary encodes the fields of a target for the Before and After of
+// an event
+func AuditSummary(fields map[string]interface{}) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		log.Println(err)
+		return ""
+	}
+	return string(b)
+}
+
+// protectAuditEvents makes the audit log append-only, updates and deletes
+// of events are ignored by the database
+func protectAuditEvents(db *gorm.DB) error {
+	if err := db.Exec(`CREATE OR REPLACE RULE AUDIT_EVENT_NO_UPDATE AS ON UPDATE TO AUDIT_EVENT DO INSTEAD NOTHING`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`CREATE OR REPLACE RULE AUDIT_EVENT_NO_DELETE AS ON DELETE TO AUDIT_EVENT DO INSTEAD NOTHING`).Error
+}
+
+// AddAuditEvent appends an event to the audit log
+func AddAuditEvent(event *AuditEvent) error {
+	return addAuditEvent(DB, event)
+}
+
+// addAuditEvent appends an event with the database or in a transaction
+func addAuditEvent(db interface {
+	QueryRow(string, ...interface{}) *sql.Row
+}, event *AuditEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	sqlStatement := `
+	INSERT INTO AUDIT_EVENT(ACTION,ACTOR,TARGET_TYPE,TARGET_ID,BEFORE,AFTER,REQUEST_ID,SOURCE_IP,CREATED_AT)
+	VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING ID`
+	err := db.QueryRow(sqlStatement, event.Action, event.Actor, event.TargetType, event.TargetID,
+		event.Before, event.After, event.RequestID, event.SourceIP, event.CreatedAt).Scan(&event.ID)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// AuditFilter selects audit events, empty fields match all the events
+type AuditFilter struct {
+	Action     string
+	Actor      string
+	TargetType string
+	TargetID   int
+	RequestID  string
+	Since      time.Time
+	Until      time.Time
+	// AfterID pages through the events, only the events with a greater id
+	// are returned
+	AfterID int
+	// Limit bounds the number of events, 0 returns all of them
+	Limit int
+}
+
+func (f AuditFilter) query() (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if f.Action != "" {
+		add("ACTION=$%d", f.Action)
+	}
+	if f.Actor != "" {
+		add("ACTOR=$%d", f.Actor)
+	}
+	if f.TargetType != "" {
+		add("TARGET_TYPE=$%d", f.TargetType)
+	}
+	if f.TargetID != 0 {
+		add("TARGET_ID=$%d", f.TargetID)
+	}
+	if f.RequestID != "" {
+		add("REQUEST_ID=$%d", f.RequestID)
+	}
+	if !f.Since.IsZero() {
+		add("CREATED_AT>=$%d", f.Since)
+	}
+	if !f.Until.IsZero() {
+		add("CREATED_AT<$%d", f.Until)
+	}
+	if f.AfterID != 0 {
+		add("ID>$%d", f.AfterID)
+	}
+	sqlStatement := `
+	SELECT ID,ACTION,ACTOR,TARGET_TYPE,TARGET_ID,COALESCE(BEFORE,''),COALESCE(AFTER,''),COALESCE(REQUEST_ID,''),COALESCE(SOURCE_IP,''),CREATED_AT
+	FROM AUDIT_EVENT`
+	if len(conditions) > 0 {
+		sqlStatement += "\n\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	sqlStatement += "\n\tORDER BY ID"
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		sqlStatement += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	return sqlStatement, args
+}
+
+// EachAuditEvent calls fn for the events matching the filter, oldest first,
+// without holding them all in memory
+func EachAuditEvent(filter AuditFilter, fn func(AuditEvent) error) error {
+	sqlStatement, args := filter.query()
+	rows, err := DB.Query(sqlStatement, args...)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := AuditEvent{}
+		err := rows.Scan(&e.ID, &e.Action, &e.Actor, &e.TargetType, &e.TargetID, &e.Before, &e.After, &e.RequestID, &e.SourceIP, &e.CreatedAt)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetAuditEvents returns the events matching the filter, oldest first
+func GetAuditEvents(filter AuditFilter) ([]AuditEvent, error) {
+	events := []AuditEvent{}
+	err := EachAuditEvent(filter, func(e AuditEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	return events, err
+}
@@ -30,3 +30,24 @@ func GetAllCategorieswithTags() map[string][]string {
 	}
 	return categoryTagMap
 }
+
+// GetCategories returns all the categories
+func GetCategories() ([]Category, error) {
+	sqlStatement := `SELECT ID,NAME FROM CATEGORY ORDER BY ID`
+	rows, err := DB.Query(sqlStatement)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	categories := []Category{}
+	for rows.Next() {
+		category := Category{}
+		if err := rows.Scan(&category.ID, &category.Name); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
@@ -4,6 +4,8 @@ import "github.com/jinzhu/gorm"
 
 func initialiseTables(GDB *gorm.DB) {
 	for _, resource := range initResource {
+		// catalog resources were written against v1alpha1
+		resource.APIVersion = "tekton.dev/v1alpha1"
 		GDB.Create(&resource)
 	}
 	for _, category := range initCategories {
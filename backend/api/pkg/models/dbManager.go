@@ -13,6 +13,163 @@ func CreateAndInitialiseTables(db *gorm.DB) error {
 	gormigrateObj := gormigrate.New(db, gormigrate.DefaultOptions, []*gormigrate.Migration{
 		// Add Migration Here
 		// If writing a migration for a new table then add the same in InitSchema
+		{
+			ID: "add-download-table",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&Download{}).Error; err != nil {
+					return err
+				}
+				return tx.Model(Download{}).AddForeignKey("resource_id", "resource (id)", "CASCADE", "CASCADE").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.DropTable("download").Error
+			},
+		},
+		{
+			ID: "add-daily-stat-table",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&DailyStat{}).Error; err != nil {
+					return err
+				}
+				return tx.Model(DailyStat{}).AddForeignKey("resource_id", "resource (id)", "CASCADE", "CASCADE").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.DropTable("daily_stat").Error
+			},
+		},
+		{
+			ID: "add-resource-api-version",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&Resource{}).Error; err != nil {
+					return err
+				}
+				// resources uploaded so far were validated against v1alpha1 only
+				return tx.Exec("UPDATE RESOURCE SET API_VERSION=? WHERE API_VERSION IS NULL OR API_VERSION=''", "tekton.dev/v1alpha1").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Model(&Resource{}).DropColumn("api_version").Error
+			},
+		},
+		{
+			ID: "add-resource-quality",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&Resource{}).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Model(&Resource{}).DropColumn("quality").Error
+			},
+		},
+		{
+			ID: "add-resource-version-table",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&ResourceVersion{}, &ResourceInterface{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(ResourceVersion{}).AddForeignKey("resource_id", "resource (id)", "CASCADE", "CASCADE").Error; err != nil {
+					return err
+				}
+				return tx.Model(ResourceInterface{}).AddForeignKey("resource_version_id", "resource_version (id)", "CASCADE", "CASCADE").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.DropTable("resource_interface", "resource_version").Error
+			},
+		},
+		{
+			ID: "add-resource-image-table",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&ResourceImage{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(ResourceImage{}).AddForeignKey("resource_version_id", "resource_version (id)", "CASCADE", "CASCADE").Error; err != nil {
+					return err
+				}
+				return addVersionImages(tx)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.DropTable("resource_image").Error
+			},
+		},
+		{
+			ID: "add-image-scan-table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&ImageScan{}, &Resource{}).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Model(&Resource{}).DropColumn("critical").Error; err != nil {
+					return err
+				}
+				return tx.DropTable("image_scan").Error
+			},
+		},
+		{
+			ID: "add-resource-deprecation-table",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&ResourceDeprecation{}, &Resource{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(ResourceDeprecation{}).AddForeignKey("resource_id", "resource (id)", "CASCADE", "CASCADE").Error; err != nil {
+					return err
+				}
+				return tx.Model(ResourceDeprecation{}).AddForeignKey("superseded_by", "resource (id)", "SET NULL", "CASCADE").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Model(&Resource{}).DropColumn("deprecated").Error; err != nil {
+					return err
+				}
+				return tx.DropTable("resource_deprecation").Error
+			},
+		},
+		{
+			ID: "add-resource-soft-delete",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&Resource{}, &AuditEvent{}).Error; err != nil {
+					return err
+				}
+				return protectAuditEvents(tx)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Exec(`DROP RULE IF EXISTS AUDIT_EVENT_NO_UPDATE ON AUDIT_EVENT`).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec(`DROP RULE IF EXISTS AUDIT_EVENT_NO_DELETE ON AUDIT_EVENT`).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&Resource{}).DropColumn("deleted_at").Error; err != nil {
+					return err
+				}
+				return tx.DropTable("audit_event").Error
+			},
+		},
+		{
+			ID: "add-personal-token-table",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&PersonalToken{}).Error; err != nil {
+					return err
+				}
+				return tx.Model(PersonalToken{}).AddForeignKey("user_id", "user_credential (id)", "CASCADE", "CASCADE").Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.DropTable("personal_token").Error
+			},
+		},
+		{
+			ID: "add-resource-version-digest",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&ResourceVersion{}).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Model(&ResourceVersion{}).DropColumn("digest").Error
+			},
+		},
+		{
+			ID: "backfill-rating-stats",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.Exec(backfillRatingStats).Error
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return nil
+			},
+		},
 	})
 
 	gormigrateObj.InitSchema(func(db *gorm.DB) error {
@@ -28,6 +185,15 @@ func CreateAndInitialiseTables(db *gorm.DB) error {
 			&UserCredential{},
 			&UserRating{},
 			&UserResource{},
+			&Download{},
+			&DailyStat{},
+			&ResourceVersion{},
+			&ResourceInterface{},
+			&ResourceImage{},
+			&ImageScan{},
+			&ResourceDeprecation{},
+			&AuditEvent{},
+			&PersonalToken{},
 		).Error
 
 		if err != nil {
@@ -62,11 +228,53 @@ func CreateAndInitialiseTables(db *gorm.DB) error {
 			return err
 		}
 
+		if err := db.Model(Download{}).AddForeignKey("resource_id", "resource (id)", "CASCADE", "CASCADE").Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(DailyStat{}).AddForeignKey("resource_id", "resource (id)", "CASCADE", "CASCADE").Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(ResourceVersion{}).AddForeignKey("resource_id", "resource (id)", "CASCADE", "CASCADE").Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(ResourceInterface{}).AddForeignKey("resource_version_id", "resource_version (id)", "CASCADE", "CASCADE").Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(ResourceImage{}).AddForeignKey("resource_version_id", "resource_version (id)", "CASCADE", "CASCADE").Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(ResourceDeprecation{}).AddForeignKey("resource_id", "resource (id)", "CASCADE", "CASCADE").Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(ResourceDeprecation{}).AddForeignKey("superseded_by", "resource (id)", "SET NULL", "CASCADE").Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(PersonalToken{}).AddForeignKey("user_id", "user_credential (id)", "CASCADE", "CASCADE").Error; err != nil {
+			return err
+		}
+
+		if err := protectAuditEvents(db); err != nil {
+			return err
+		}
+
 		log.Printf("Schema initialised successfully !!")
 
 		// Add Data to the Tables
 		initialiseTables(db)
 
+		// the initial ratings are recorded in the daily stats as the
+		// backfill-rating-stats migration does on upgraded databases
+		if err := db.Exec(backfillRatingStats).Error; err != nil {
+			return err
+		}
+
 		log.Printf("Data added successfully !!")
 
 		return nil
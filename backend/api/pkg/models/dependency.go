@@ -0,0 +1,135 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/graph"
+)
+
+// Dependency represents a task used by a pipeline
+type Dependency struct {
+	ResourceID  int    `json:"resource_id"`
+	DependsOnID int    `json:"depends_on_id"`
+	RawPath     string `json:"raw_path"`
+}
+
+// GetAllDependencies returns every pipeline to task relation. Tasks which
+// are referenced by a pipeline but are not available on the hub have a
+// DependsOnID of 0
+func GetAllDependencies() ([]Dependency, error) {
+	sqlStatement := `
+	SELECT P.RESOURCE_ID,TRIM(P.RAW_PATH),T.RESOURCE_ID
+	FROM RESOURCE_RAW_PATH P JOIN RESOURCE R ON (R.ID=P.RESOURCE_ID AND R.TYPE='pipeline' AND R.DELETED_AT IS NULL)
+	LEFT JOIN (SELECT DISTINCT RP.RESOURCE_ID,TRIM(RP.RAW_PATH) AS RAW_PATH
+		FROM RESOURCE_RAW_PATH RP JOIN RESOURCE RT ON (RT.ID=RP.RESOURCE_ID AND RT.TYPE='task' AND RT.DELETED_AT IS NULL)
+		WHERE RP.TYPE='task') T ON (T.RAW_PATH=TRIM(P.RAW_PATH))
+	WHERE P.TYPE='task' ORDER BY P.RESOURCE_ID`
+	rows, err := DB.Query(sqlStatement)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	dependencies := []Dependency{}
+	for rows.Next() {
+		dependency := Dependency{}
+		var dependsOn sql.NullInt64
+		if err := rows.Scan(&dependency.ResourceID, &dependency.RawPath, &dependsOn); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		dependency.DependsOnID = int(dependsOn.Int64)
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, rows.Err()
+}
+
+// GetDependencyGraph returns the graph of resources used by the resource
+// when dependents is false, or the resources using it when dependents is
+// true. Only direct neighbours are included unless transitive is set
+func GetDependencyGraph(resourceID int, dependents bool, transitive bool) (*graph.Graph, error) {
+	nodes, err := getResourceNodes()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := nodes[resourceID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrResourceNotFound, resourceID)
+	}
+	dependencies, err := GetAllDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	g := graph.New(root)
+	visited := map[int]bool{resourceID: true}
+	queue := []int{resourceID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, d := range dependencies {
+			var next int
+			switch {
+			case !dependents && d.ResourceID == current:
+				next = d.DependsOnID
+			case dependents && d.DependsOnID == current:
+				next = d.ResourceID
+			default:
+				continue
+			}
+
+			node := externalNode(d.RawPath)
+			if next != 0 {
+				node = nodes[next]
+			}
+			g.AddNode(node)
+			if dependents {
+				g.AddEdge(node.ID, nodes[current].ID)
+			} else {
+				g.AddEdge(nodes[current].ID, node.ID)
+			}
+
+			if next != 0 && transitive && !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return g, nil
+}
+
+func getResourceNodes() (map[int]graph.Node, error) {
+	sqlStatement := `SELECT ID,NAME,TYPE FROM RESOURCE WHERE DELETED_AT IS NULL`
+	rows, err := DB.Query(sqlStatement)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	nodes := make(map[int]graph.Node)
+	for rows.Next() {
+		node := graph.Node{}
+		if err := rows.Scan(&node.ResourceID, &node.Name, &node.Type); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		node.ID = fmt.Sprintf("resource-%d", node.ResourceID)
+		nodes[node.ResourceID] = node
+	}
+	return nodes, rows.Err()
+}
+
+// externalNode represents a task which is referenced by a pipeline but has
+// not been uploaded to the hub
+func externalNode(rawPath string) graph.Node {
+	return graph.Node{
+		ID:      "external-" + rawPath,
+		Name:    strings.TrimSuffix(path.Base(rawPath), path.Ext(rawPath)),
+		Type:    "task",
+		RawPath: rawPath,
+	}
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ResourceDeprecation tells why a resource is deprecated and what to use
+// instead: another resource, a version or a version of another resource
+type ResourceDeprecation struct {
+	ID           int    `gorm:"primary_key;auto_increment" json:"-"`
+	ResourceID   int    `gorm:"not null;unique_index" json:"resource_id"`
+	Reason       string `json:"reason"`
+	SupersededBy *int   `json:"superseded_by,omitempty"`
+	// SupersededByVersion is a version of SupersededBy, or of the resource
+	// itself if it is not set
+	SupersededByVersion string    `json:"superseded_by_version,omitempty"`
+	DeprecatedAt        time.Time `json:"deprecated_at"`
+}
+
+// Warning describes the deprecation for the users of the resource
+func (d *ResourceDeprecation) Warning(name string) string {
+	warning := fmt.Sprintf("%s is deprecated: %s", name, d.Reason)
+	switch {
+	case d.SupersededBy != nil && d.SupersededByVersion != "":
+		warning += fmt.Sprintf(", use version %s of resource %d instead", d.SupersededByVersion, *d.SupersededBy)
+	case d.SupersededBy != nil:
+		warning += fmt.Sprintf(", use resource %d instead", *d.SupersededBy)
+	case d.SupersededByVersion != "":
+		warning += fmt.Sprintf(", use version %s instead", d.SupersededByVersion)
+	}
+	return warning
+}
+
+// DeprecateResource marks a resource as deprecated, the reason and
+// replacement of an already deprecated resource are updated
+func DeprecateResource(d *ResourceDeprecation) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer tx.Rollback()
+	if d.DeprecatedAt.IsZero() {
+		d.DeprecatedAt = time.Now()
+	}
+	sqlStatement := `
+	INSERT INTO RESOURCE_DEPRECATION(RESOURCE_ID,REASON,SUPERSEDED_BY,SUPERSEDED_BY_VERSION,DEPRECATED_AT)
+	VALUES($1,$2,$3,$4,$5)
+	ON CONFLICT (RESOURCE_ID) DO UPDATE SET REASON=$2,SUPERSEDED_BY=$3,SUPERSEDED_BY_VERSION=$4
+	RETURNING ID,DEPRECATED_AT`
+	err = tx.QueryRow(sqlStatement, d.ResourceID, d.Reason, d.SupersededBy, d.SupersededByVersion, d.DeprecatedAt).Scan(&d.ID, &d.DeprecatedAt)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE RESOURCE SET DEPRECATED=TRUE WHERE ID=$1`, d.ResourceID); err != nil {
+		log.Println(err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// UndeprecateResource removes the deprecation of a resource
+func UndeprecateResource(resourceID int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM RESOURCE_DEPRECATION WHERE RESOURCE_ID=$1`, resourceID); err != nil {
+		log.Println(err)
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE RESOURCE SET DEPRECATED=FALSE WHERE ID=$1`, resourceID); err != nil {
+		log.Println(err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// GetResourceDeprecation returns the deprecation of a resource, nil is
+// returned if it is not deprecated
+func GetResourceDeprecation(resourceID int) (*ResourceDeprecation, error) {
+	sqlStatement := `
+	SELECT ID,RESOURCE_ID,REASON,SUPERSEDED_BY,SUPERSEDED_BY_VERSION,DEPRECATED_AT
+	FROM RESOURCE_DEPRECATION WHERE RESOURCE_ID=$1`
+	d := &ResourceDeprecation{}
+	var supersededBy sql.NullInt64
+	err := DB.QueryRow(sqlStatement, resourceID).Scan(&d.ID, &d.ResourceID, &d.Reason, &supersededBy, &d.SupersededByVersion, &d.DeprecatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if supersededBy.Valid {
+		id := int(supersededBy.Int64)
+		d.SupersededBy = &id
+	}
+	return d, nil
+}
+
+// GetDeprecationsByResources returns the deprecations of the given
+// resources, by resource id. The resources which are not deprecated are
+// left out
+func GetDeprecationsByResources(resourceIDs []int) (map[int]*ResourceDeprecation, error) {
+	sqlStatement := `
+	SELECT ID,RESOURCE_ID,REASON,SUPERSEDED_BY,SUPERSEDED_BY_VERSION,DEPRECATED_AT
+	FROM RESOURCE_DEPRECATION WHERE RESOURCE_ID=ANY($1)`
+	rows, err := DB.Query(sqlStatement, pq.Array(resourceIDs))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	deprecations := map[int]*ResourceDeprecation{}
+	for rows.Next() {
+		d := &ResourceDeprecation{}
+		var supersededBy sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.ResourceID, &d.Reason, &supersededBy, &d.SupersededByVersion, &d.DeprecatedAt); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		if supersededBy.Valid {
+			id := int(supersededBy.Int64)
+			d.SupersededBy = &id
+		}
+		deprecations[d.ResourceID] = d
+	}
+	return deprecations, rows.Err()
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"log"
+	"time"
+)
+
+// Download represents a single download or install of a resource
+type Download struct {
+	ID         int       `gorm:"primary_key;auto_increment" json:"id"`
+	ResourceID int       `gorm:"not null;index" json:"resource_id"`
+	Version    string    `json:"version"`
+	Client     string    `json:"client"`
+	ClientID   string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RecordDownload records a download event along with the download count
+// and the daily statistics of its resource, all of them or none
+func RecordDownload(download *Download) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer tx.Rollback()
+	sqlStatement := `
+	INSERT INTO DOWNLOAD(RESOURCE_ID,VERSION,CLIENT,CLIENT_ID,CREATED_AT)
+	VALUES($1,$2,$3,$4,$5) RETURNING ID`
+	err = tx.QueryRow(sqlStatement, download.ResourceID, download.Version, download.Client, download.ClientID, download.CreatedAt).Scan(&download.ID)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	sqlStatement = `
+	INSERT INTO DAILY_STAT(RESOURCE_ID,VERSION,DAY,DOWNLOADS) VALUES($1,$2,$3,1)
+	ON CONFLICT (RESOURCE_ID,VERSION,DAY) DO UPDATE SET DOWNLOADS=DAILY_STAT.DOWNLOADS+1`
+	if _, err := tx.Exec(sqlStatement, download.ResourceID, download.Version, download.CreatedAt.UTC().Format("2006-01-02")); err != nil {
+		log.Println(err)
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE RESOURCE SET DOWNLOADS = DOWNLOADS + 1 WHERE ID=$1`, download.ResourceID); err != nil {
+		log.Println(err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/jinzhu/gorm"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/image"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/spec"
+)
+
+// ResourceImage is a container image used by a step, a sidecar or the
+// stepTemplate of a resource version. Reference is the normalised image,
+// params are replaced by their default value, it is empty if the image can
+// not be resolved
+type ResourceImage struct {
+	ID                int    `gorm:"primary_key;auto_increment" json:"id"`
+	ResourceVersionID int    `gorm:"not null;index" json:"resource_version_id"`
+	Kind              string `json:"kind"`
+	Container         string `json:"container"`
+	Image             string `json:"image"`
+	Reference         string `gorm:"index" json:"reference"`
+	Registry          string `json:"registry"`
+	Repository        string `gorm:"index" json:"repository"`
+	Tag               string `json:"tag"`
+	Digest            string `json:"digest"`
+}
+
+// Image is an image used by the resources of the hub
+type Image struct {
+	Reference  string `json:"reference"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Digest     string `json:"digest"`
+	Resources  int    `json:"resources"`
+}
+
+// ImageResource is a resource version using an image
+type ImageResource struct {
+	ResourceID int    `json:"resource_id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Version    string `json:"version"`
+	Kind       string `json:"kind"`
+	Container  string `json:"container"`
+	Image      string `json:"image"`
+	Reference  string `json:"reference"`
+}
+
+// latestVersion restricts a query on RESOURCE_VERSION V to the latest
+// version of each resource
+const latestVersion = `V.ID=(SELECT MAX(ID) FROM RESOURCE_VERSION WHERE RESOURCE_ID=V.RESOURCE_ID)`
+
+func imageRows(iface *spec.Interface) []ResourceImage {
+	defaults := map[string]string{}
+	for _, p := range iface.Params {
+		if p.HasDefault {
+			defaults[p.Name] = p.Default
+		}
+	}
+	images := []ResourceImage{}
+	add := func(kind string, containers []spec.Container) {
+		for _, c := range containers {
+			if c.Image == "" {
+				continue
+			}
+			row := ResourceImage{Kind: kind, Container: c.Name, Image: c.Image}
+			if resolved, ok := image.Resolve(c.Image, defaults); ok {
+				if ref, err := image.Parse(resolved); err == nil {
+					row.Reference = ref.String()
+					row.Registry = ref.Registry
+					row.Repository = ref.Repository
+					row.Tag = ref.Tag
+					row.Digest = ref.Digest
+				}
+			}
+			images = append(images, row)
+		}
+	}
+	add(StepField, iface.Steps)
+	add(SidecarField, iface.Sidecars)
+	if iface.StepTemplate != nil {
+		add(StepTemplateField, []spec.Container{*iface.StepTemplate})
+	}
+	return images
+}
+
+func addResourceImages(tx *sql.Tx, versionID int, iface *spec.Interface) error {
+	sqlStatement := `
+	INSERT INTO RESOURCE_IMAGE(RESOURCE_VERSION_ID,KIND,CONTAINER,IMAGE,REFERENCE,REGISTRY,REPOSITORY,TAG,DIGEST)
+	VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9)`
+	for _, i := range imageRows(iface) {
+		_, err := tx.Exec(sqlStatement, versionID, i.Kind, i.Container, i.Image, i.Reference, i.Registry, i.Repository, i.Tag, i.Digest)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetImages returns the images used by the latest version of the resources
+// along with the number of resources using each of them
+func GetImages() ([]Image, error) {
+	sqlStatement := `
+	SELECT I.REFERENCE,I.REGISTRY,I.REPOSITORY,I.TAG,I.DIGEST,COUNT(DISTINCT V.RESOURCE_ID)
+	FROM RESOURCE_IMAGE I JOIN RESOURCE_VERSION V ON I.RESOURCE_VERSION_ID=V.ID
+	WHERE I.REFERENCE<>'' AND ` + latestVersion + `
+	AND EXISTS (SELECT 1 FROM RESOURCE R WHERE R.ID=V.RESOURCE_ID AND R.DELETED_AT IS NULL)
+	GROUP BY I.REFERENCE,I.REGISTRY,I.REPOSITORY,I.TAG,I.DIGEST ORDER BY I.REFERENCE`
+	rows, err := DB.Query(sqlStatement)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	images := []Image{}
+	for rows.Next() {
+		i := Image{}
+		if err := rows.Scan(&i.Reference, &i.Registry, &i.Repository, &i.Tag, &i.Digest, &i.Resources); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		images = append(images, i)
+	}
+	return images, rows.Err()
+}
+
+// GetResourcesUsingImage returns the resources using an image. A query
+// without tag or digest selects all the tags of the repository. Only the
+// latest version of each resource is looked at unless allVersions is set
+func GetResourcesUsingImage(query image.Reference, allVersions bool) ([]ImageResource, error) {
+	sqlStatement := `
+	SELECT R.ID,R.NAME,R.TYPE,V.VERSION,I.KIND,I.CONTAINER,I.IMAGE,I.REFERENCE
+	FROM RESOURCE_IMAGE I JOIN RESOURCE_VERSION V ON I.RESOURCE_VERSION_ID=V.ID JOIN RESOURCE R ON R.ID=V.RESOURCE_ID
+	WHERE R.DELETED_AT IS NULL AND I.REGISTRY=$1 AND I.REPOSITORY=$2 AND ($3='' OR I.TAG=$3) AND ($4='' OR I.DIGEST=$4)`
+	if !allVersions {
+		sqlStatement += " AND " + latestVersion
+	}
+	sqlStatement += " ORDER BY R.ID,V.ID"
+	rows, err := DB.Query(sqlStatement, query.Registry, query.Repository, query.Tag, query.Digest)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	resources := []ImageResource{}
+	for rows.Next() {
+		r := ImageResource{}
+		if err := rows.Scan(&r.ResourceID, &r.Name, &r.Type, &r.Version, &r.Kind, &r.Container, &r.Image, &r.Reference); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
+}
+
+// addVersionImages records the images of the versions stored before the
+// images were
+func addVersionImages(tx *gorm.DB) error {
+	contents, err := versionContents(tx)
+	if err != nil {
+		return err
+	}
+	for id, content := range contents {
+		iface, err := spec.Parse([]byte(content))
+		if err != nil {
+			continue
+		}
+		for _, i := range imageRows(iface) {
+			err := tx.Exec(`INSERT INTO RESOURCE_IMAGE(RESOURCE_VERSION_ID,KIND,CONTAINER,IMAGE,REFERENCE,REGISTRY,REPOSITORY,TAG,DIGEST)
+			VALUES(?,?,?,?,?,?,?,?,?)`, id, i.Kind, i.Container, i.Image, i.Reference, i.Registry, i.Repository, i.Tag, i.Digest).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// InterfaceQuery selects resources by the interface of their latest
+// version, each listed name must be present. Names are matched case
+// insensitively.
+type InterfaceQuery struct {
+	Params     []string
+	Workspaces []string
+	Results    []string
+	Resources  []string
+	// IncludeDeprecated also returns deprecated resources
+	IncludeDeprecated bool
+}
+
+// IsEmpty checks if the query has no condition
+func (q InterfaceQuery) IsEmpty() bool {
+	return len(q.Params) == 0 && len(q.Workspaces) == 0 && len(q.Results) == 0 && len(q.Resources) == 0
+}
+
+// SearchResourcesByInterface returns the resources matching the query, e.g.
+// the tasks which accept a DOCKERFILE param
+func SearchResourcesByInterface(query InterfaceQuery) ([]Resource, error) {
+	conditions := []string{"R.DELETED_AT IS NULL"}
+	args := []interface{}{}
+	addConditions := func(names []string, kinds ...string) {
+		for _, name := range names {
+			args = append(args, strings.ToLower(name))
+			conditions = append(conditions, fmt.Sprintf(`EXISTS (SELECT 1 FROM RESOURCE_INTERFACE I
+			WHERE I.RESOURCE_VERSION_ID=V.ID AND I.KIND IN ('%s') AND LOWER(I.NAME)=$%d)`, strings.Join(kinds, "','"), len(args)))
+		}
+	}
+	addConditions(query.Params, ParamField)
+	addConditions(query.Workspaces, WorkspaceField)
+	addConditions(query.Results, ResultField)
+	addConditions(query.Resources, InputField, OutputField)
+	if !query.IncludeDeprecated {
+		conditions = append(conditions, "NOT COALESCE(R.DEPRECATED,FALSE)")
+	}
+
+	sqlStatement := `
+	SELECT R.ID,R.NAME,R.TYPE,R.DESCRIPTION,R.DOWNLOADS,R.RATING,R.GITHUB,R.VERIFIED,COALESCE(R.API_VERSION,''),COALESCE(R.QUALITY,0),COALESCE(R.CRITICAL,FALSE),COALESCE(R.DEPRECATED,FALSE)
+	FROM RESOURCE R JOIN RESOURCE_VERSION V ON V.RESOURCE_ID=R.ID
+	AND V.ID=(SELECT MAX(ID) FROM RESOURCE_VERSION WHERE RESOURCE_ID=R.ID)`
+	sqlStatement += "\n\tWHERE " + strings.Join(conditions, " AND ")
+	sqlStatement += "\n\tORDER BY R.ID"
+	rows, err := DB.Query(sqlStatement, args...)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	resourceTagMap := getResourceTagMap()
+	resources := []Resource{}
+	for rows.Next() {
+		resource := Resource{}
+		err := rows.Scan(&resource.ID, &resource.Name, &resource.Type, &resource.Description, &resource.Downloads, &resource.Rating, &resource.Github, &resource.Verified, &resource.APIVersion, &resource.Quality, &resource.Critical, &resource.Deprecated)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		resource.Tags = resourceTagMap[resource.ID]
+		resources = append(resources, resource)
+	}
+	return resources, rows.Err()
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"context"
+	"log"
+)
+
+// Keys of the advisory locks of the jobs only one replica of the hub runs
+// at a time
+const (
+	PurgeLock int64 = iota + 1
+	VersionBackfillLock
+)
+
+// WithLock runs fn while holding the advisory lock key, it returns false
+// without running fn when another replica holds it
+func WithLock(key int64, fn func()) (bool, error) {
+	ctx := context.Background()
+	// the lock belongs to a session, it is taken and released on the same
+	// connection
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+			log.Println(err)
+		}
+	}()
+	fn()
+	return true, nil
+}
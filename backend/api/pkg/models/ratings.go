@@ -3,6 +3,8 @@ package models
 import (
 	"fmt"
 	"log"
+
+	"github.com/lib/pq"
 )
 
 // Rating represents Rating model in database
@@ -33,6 +35,29 @@ func GetRatingDetialsByResourceID(resourceID int) Rating {
 	return taskRating
 }
 
+// GetRatingsByResources returns the ratings of the given resources, by
+// resource id. The resources which were never rated are left out
+func GetRatingsByResources(resourceIDs []int) (map[int]Rating, error) {
+	sqlStatement := `SELECT ID,RESOURCE_ID,ONE_STAR,TWO_STAR,THREE_STAR,FOUR_STAR,FIVE_STAR FROM RATING WHERE RESOURCE_ID=ANY($1)`
+	rows, err := DB.Query(sqlStatement, pq.Array(resourceIDs))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	ratings := map[int]Rating{}
+	for rows.Next() {
+		rating := Rating{}
+		err := rows.Scan(&rating.ID, &rating.ResourceID, &rating.OneStar, &rating.TwoStar, &rating.ThreeStar, &rating.FourStar, &rating.FiveStar)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		ratings[rating.ResourceID] = rating
+	}
+	return ratings, rows.Err()
+}
+
 func calculateAverageRating(resourceID int) float64 {
 	rating := Rating{}
 	sqlStatement := `SELECT * FROM RATING WHERE RESOURCE_ID=$1`
@@ -1,12 +1,20 @@
 package models
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"strconv"
+	"time"
 
 	"github.com/lib/pq"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/spec"
 )
 
+// ErrResourceNotFound is returned for a resource which does not exist
+var ErrResourceNotFound = errors.New("resource does not exist")
+
 // Resource is a database model representing task and pipeline
 type Resource struct {
 	ID          int            `gorm:"primary_key;auto_increment" json:"id"`
@@ -18,6 +26,18 @@ type Resource struct {
 	Github      string         `json:"github"`
 	Tags        pq.StringArray `gorm:"type:text[]" json:"tags"`
 	Verified    bool           `gorm:"default:false" json:"verified"`
+	APIVersion  string         `json:"api_version"`
+	Quality     int            `gorm:"default:0" json:"quality"`
+	// Critical flags resources using an image with critical vulnerabilities
+	Critical bool `gorm:"default:false" json:"critical_vulnerabilities"`
+	// Deprecated resources are still served but left out of the search
+	Deprecated bool `gorm:"default:false" json:"deprecated"`
+	// DeletedAt is set while a deleted resource can still be restored
+	DeletedAt *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	// Deprecation is only set for the detail of a deprecated resource
+	Deprecation *ResourceDeprecation `gorm:"-" json:"deprecation,omitempty"`
+	// Interface of the latest version, only set for the detail of a resource
+	Interface *spec.Interface `gorm:"-" json:"interface,omitempty"`
 }
 
 // AddCatalogResource is called to add resource from catalog
@@ -38,9 +58,9 @@ func AddCatalogResource(resource *Resource) (int, error) {
 func AddResource(resource *Resource, userID int, owner string, respositoryName string, path string) (int, error) {
 	var resourceID int
 	sqlStatement := `
-	INSERT INTO RESOURCE (NAME,DESCRIPTION,DOWNLOADS,RATING,GITHUB,TYPE)
-	VALUES ($1, $2, $3, $4, $5,$6) RETURNING ID`
-	err := DB.QueryRow(sqlStatement, resource.Name, resource.Description, resource.Downloads, resource.Rating, resource.Github, resource.Type).Scan(&resourceID)
+	INSERT INTO RESOURCE (NAME,DESCRIPTION,DOWNLOADS,RATING,GITHUB,TYPE,API_VERSION,QUALITY)
+	VALUES ($1, $2, $3, $4, $5,$6,$7,$8) RETURNING ID`
+	err := DB.QueryRow(sqlStatement, resource.Name, resource.Description, resource.Downloads, resource.Rating, resource.Github, resource.Type, resource.APIVersion, resource.Quality).Scan(&resourceID)
 	if err != nil {
 		return 0, err
 	}
@@ -69,7 +89,10 @@ func AddResource(resource *Resource, userID int, owner string, respositoryName s
 			}
 		}
 	}
-	addGithubDetails(resourceID, owner, respositoryName, path)
+	// resources imported from a bundle have no GitHub repository
+	if owner != "" {
+		addGithubDetails(resourceID, owner, respositoryName, path)
+	}
 	return resourceID, addUserResource(userID, resourceID)
 }
 
@@ -138,19 +161,20 @@ func CheckSameResourceUpload(userID int, name string) bool {
 func GetAllResources() []Resource {
 	resources := []Resource{}
 	sqlStatement := `
-	SELECT * FROM RESOURCE ORDER BY ID`
+	SELECT ID,NAME,TYPE,DESCRIPTION,DOWNLOADS,RATING,GITHUB,TAGS,VERIFIED,COALESCE(API_VERSION,''),COALESCE(QUALITY,0),COALESCE(CRITICAL,FALSE),COALESCE(DEPRECATED,FALSE)
+	FROM RESOURCE WHERE DELETED_AT IS NULL ORDER BY ID`
 	rows, err := DB.Query(sqlStatement)
 	defer rows.Close()
 	for rows.Next() {
 		resource := Resource{}
-		err = rows.Scan(&resource.ID, &resource.Name, &resource.Type, &resource.Description, &resource.Downloads, &resource.Rating, &resource.Github, &resource.Tags, &resource.Verified)
+		err = rows.Scan(&resource.ID, &resource.Name, &resource.Type, &resource.Description, &resource.Downloads, &resource.Rating, &resource.Github, &resource.Tags, &resource.Verified, &resource.APIVersion, &resource.Quality, &resource.Critical, &resource.Deprecated)
 		if err != nil {
 			log.Println(err)
 		}
 		resources = append(resources, resource)
 	}
 	resourceIndexMap := make(map[int]int)
-	sqlStatement = `SELECT ID FROM RESOURCE ORDER BY ID`
+	sqlStatement = `SELECT ID FROM RESOURCE WHERE DELETED_AT IS NULL ORDER BY ID`
 	rows, err = DB.Query(sqlStatement)
 	if err != nil {
 		log.Println(err)
@@ -163,7 +187,7 @@ func GetAllResources() []Resource {
 		resourceIndex = resourceIndex + 1
 	}
 
-	sqlStatement = `SELECT R.ID,TG.NAME FROM TAG TG JOIN RESOURCE_TAG TT ON TT.TAG_ID=TG.ID JOIN RESOURCE R ON R.ID=TT.RESOURCE_ID`
+	sqlStatement = `SELECT R.ID,TG.NAME FROM TAG TG JOIN RESOURCE_TAG TT ON TT.TAG_ID=TG.ID JOIN RESOURCE R ON R.ID=TT.RESOURCE_ID WHERE R.DELETED_AT IS NULL`
 	rows, err = DB.Query(sqlStatement)
 	if err != nil {
 		log.Println(err)
@@ -187,8 +211,9 @@ func GetResourceByID(id int) Resource {
 	resourceTagMap = make(map[int][]string)
 	resourceTagMap = getResourceTagMap()
 	sqlStatement := `
-	SELECT * FROM RESOURCE WHERE ID=$1;`
-	err := DB.QueryRow(sqlStatement, id).Scan(&resource.ID, &resource.Name, &resource.Type, &resource.Description, &resource.Downloads, &resource.Rating, &resource.Github, &resource.Tags, &resource.Verified)
+	SELECT ID,NAME,TYPE,DESCRIPTION,DOWNLOADS,RATING,GITHUB,TAGS,VERIFIED,COALESCE(API_VERSION,''),COALESCE(QUALITY,0),COALESCE(CRITICAL,FALSE),COALESCE(DEPRECATED,FALSE)
+	FROM RESOURCE WHERE ID=$1 AND DELETED_AT IS NULL;`
+	err := DB.QueryRow(sqlStatement, id).Scan(&resource.ID, &resource.Name, &resource.Type, &resource.Description, &resource.Downloads, &resource.Rating, &resource.Github, &resource.Tags, &resource.Verified, &resource.APIVersion, &resource.Quality, &resource.Critical, &resource.Deprecated)
 	if err != nil {
 		return Resource{}
 	}
@@ -196,6 +221,75 @@ func GetResourceByID(id int) Resource {
 	return resource
 }
 
+// GetResourcesByIDs returns the resources with the given ids which are not
+// deleted, by id
+func GetResourcesByIDs(ids []int) (map[int]Resource, error) {
+	sqlStatement := `
+	SELECT ID,NAME,TYPE,DESCRIPTION,DOWNLOADS,RATING,GITHUB,VERIFIED,COALESCE(API_VERSION,''),COALESCE(QUALITY,0),COALESCE(CRITICAL,FALSE),COALESCE(DEPRECATED,FALSE)
+	FROM RESOURCE WHERE ID=ANY($1) AND DELETED_AT IS NULL`
+	rows, err := DB.Query(sqlStatement, pq.Array(ids))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	resources := map[int]Resource{}
+	for rows.Next() {
+		resource := Resource{Tags: pq.StringArray{}}
+		err := rows.Scan(&resource.ID, &resource.Name, &resource.Type, &resource.Description, &resource.Downloads, &resource.Rating, &resource.Github, &resource.Verified, &resource.APIVersion, &resource.Quality, &resource.Critical, &resource.Deprecated)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		resources[resource.ID] = resource
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sqlStatement = `SELECT TT.RESOURCE_ID,TG.NAME FROM RESOURCE_TAG TT JOIN TAG TG ON TG.ID=TT.TAG_ID WHERE TT.RESOURCE_ID=ANY($1) ORDER BY TG.ID`
+	rows, err = DB.Query(sqlStatement, pq.Array(ids))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var resourceID int
+		var tag string
+		if err := rows.Scan(&resourceID, &tag); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		if resource, ok := resources[resourceID]; ok {
+			resource.Tags = append(resource.Tags, tag)
+			resources[resourceID] = resource
+		}
+	}
+	return resources, rows.Err()
+}
+
+// FindCatalogResource returns the id of the resource of a kind with a name
+// in a catalog, which is the GitHub owner, repository or owner/repository
+// of the resource. Any catalog or kind matches when it is empty. Verified resources
+// are preferred when several match
+func FindCatalogResource(catalog string, kind string, name string) (int, error) {
+	sqlStatement := `
+	SELECT R.ID FROM RESOURCE R LEFT JOIN GITHUB_DETAIL G ON G.RESOURCE_ID=R.ID
+	WHERE ($2='' OR LOWER(R.TYPE)=LOWER($2)) AND R.NAME=$3 AND R.DELETED_AT IS NULL
+	AND ($1='' OR LOWER(G.OWNER)=LOWER($1) OR LOWER(G.REPOSITORY_NAME)=LOWER($1) OR LOWER(G.OWNER||'/'||G.REPOSITORY_NAME)=LOWER($1))
+	ORDER BY R.VERIFIED DESC,R.ID LIMIT 1`
+	var resourceID int
+	err := DB.QueryRow(sqlStatement, catalog, kind, name).Scan(&resourceID)
+	if err == sql.ErrNoRows {
+		return 0, ErrResourceNotFound
+	}
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	return resourceID, nil
+}
+
 // GetTaskNameFromID returns name from given ID
 func GetTaskNameFromID(taskID string) string {
 	id, err := strconv.Atoi(taskID)
@@ -212,17 +306,14 @@ func GetTaskNameFromID(taskID string) string {
 }
 
 // IncrementDownloads will increment the number of downloads
-func IncrementDownloads(taskID string) {
-	id, err := strconv.Atoi(taskID)
-	if err != nil {
-		log.Println(err)
-	}
-	log.Println(id)
+func IncrementDownloads(resourceID int) error {
 	sqlStatement := `UPDATE RESOURCE SET DOWNLOADS = DOWNLOADS + 1 WHERE ID=$1`
-	_, err = DB.Exec(sqlStatement, id)
+	_, err := DB.Exec(sqlStatement, resourceID)
 	if err != nil {
 		log.Println(err)
+		return err
 	}
+	return nil
 }
 
 func updateAverageRating(resourceID int, rating float64) error {
@@ -247,6 +338,19 @@ func GetResourceIDFromName(name string) (int, error) {
 	return resourceID, nil
 }
 
+// GetResourceIDByNameAndType returns the id of the resource of a type with a
+// name, the deleted resources are left out. sql.ErrNoRows is returned if
+// there is no such resource
+func GetResourceIDByNameAndType(name string, kind string) (int, error) {
+	sqlStatement := `SELECT ID FROM RESOURCE WHERE NAME=$1 AND LOWER(TYPE)=LOWER($2) AND DELETED_AT IS NULL ORDER BY ID LIMIT 1`
+	var resourceID int
+	err := DB.QueryRow(sqlStatement, name, kind).Scan(&resourceID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println(err)
+	}
+	return resourceID, err
+}
+
 func resourceExists(resourceName string) bool {
 	sqlStatement := `SELECT EXISTS(SELECT 1 FROM RESOURCE WHERE NAME=$1 AND VERIFIED=$2)`
 	var exists bool
@@ -257,13 +361,161 @@ func resourceExists(resourceName string) bool {
 	return exists
 }
 
-// DeleteResource will delete a resource
-func DeleteResource(resourceID int) error {
-	sqlStatement := `DELETE FROM RESOURCE WHERE ID=$1`
-	_, err := DB.Exec(sqlStatement, resourceID)
+// DeleteResource hides a resource until it is restored or purged and
+// records it with the actor and request of the event. sql.ErrNoRows is
+// returned if the resource does not exist or is already deleted
+func DeleteResource(resourceID int, event AuditEvent) error {
+	event.Action = AuditDelete
+	return changeDeletion(resourceID, event, true,
+		`UPDATE RESOURCE SET DELETED_AT=$2 WHERE ID=$1 AND DELETED_AT IS NULL`, time.Now())
+}
+
+// RestoreResource brings back a resource deleted after the given time,
+// sql.ErrNoRows is returned if there is no such deleted resource
+func RestoreResource(resourceID int, event AuditEvent, deletedAfter time.Time) error {
+	event.Action = AuditRestore
+	return changeDeletion(resourceID, event, false,
+		`UPDATE RESOURCE SET DELETED_AT=NULL WHERE ID=$1 AND DELETED_AT>$2`, deletedAfter)
+}
+
+// changeDeletion runs a delete or restore statement and records it in the
+// audit log
+func changeDeletion(resourceID int, event AuditEvent, deleted bool, sqlStatement string, at time.Time) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer tx.Rollback()
+	result, err := tx.Exec(sqlStatement, resourceID, at)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return sql.ErrNoRows
+	}
+	var name string
+	if err := tx.QueryRow(`SELECT NAME FROM RESOURCE WHERE ID=$1`, resourceID).Scan(&name); err != nil {
+		log.Println(err)
+		return err
+	}
+	event.TargetType, event.TargetID = ResourceTarget, resourceID
+	event.Before = AuditSummary(map[string]interface{}{"name": name, "deleted": !deleted})
+	event.After = AuditSummary(map[string]interface{}{"name": name, "deleted": deleted})
+	if err := addAuditEvent(tx, &event); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// PurgeDeletedResources removes the resources deleted before the given
+// time along with their ratings, versions and downloads, and returns how
+// many were purged
+func PurgeDeletedResources(deletedBefore time.Time) (int, error) {
+	tx, err := DB.Begin()
 	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.Query(`DELETE FROM RESOURCE WHERE DELETED_AT<$1 RETURNING ID,NAME`, deletedBefore)
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	events := []AuditEvent{}
+	for rows.Next() {
+		var name string
+		event := AuditEvent{Action: AuditPurge, Actor: AuditSystem, TargetType: ResourceTarget}
+		if err := rows.Scan(&event.TargetID, &name); err != nil {
+			rows.Close()
+			log.Println(err)
+			return 0, err
+		}
+		event.Before = AuditSummary(map[string]interface{}{"name": name, "deleted": true})
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	for i := range events {
+		if err := addAuditEvent(tx, &events[i]); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// SetResourceVerified changes the verification of a resource and returns
+// the previous one, sql.ErrNoRows is returned if the resource does not exist
+func SetResourceVerified(resourceID int, verified bool) (bool, error) {
+	var previous bool
+	sqlStatement := `
+	UPDATE RESOURCE R SET VERIFIED=$2 FROM RESOURCE P
+	WHERE R.ID=$1 AND P.ID=R.ID AND R.DELETED_AT IS NULL RETURNING P.VERIFIED`
+	err := DB.QueryRow(sqlStatement, resourceID, verified).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		log.Println(err)
+	}
+	return previous, err
+}
+
+// CheckResource returns ErrResourceNotFound unless the resource exists and
+// is not deleted
+func CheckResource(resourceID int) error {
+	var exists bool
+	sqlStatement := `SELECT EXISTS (SELECT 1 FROM RESOURCE WHERE ID=$1 AND DELETED_AT IS NULL)`
+	if err := DB.QueryRow(sqlStatement, resourceID).Scan(&exists); err != nil {
 		log.Println(err)
 		return err
 	}
+	if !exists {
+		return fmt.Errorf("%w: %d", ErrResourceNotFound, resourceID)
+	}
 	return nil
 }
+
+// IsResourceOwner checks if a resource was uploaded by the user
+func IsResourceOwner(userID int, resourceID int) bool {
+	var exists bool
+	sqlStatement := `SELECT EXISTS(SELECT 1 FROM USER_RESOURCE WHERE USER_ID=$1 AND RESOURCE_ID=$2)`
+	if err := DB.QueryRow(sqlStatement, userID, resourceID).Scan(&exists); err != nil {
+		log.Println(err)
+		return false
+	}
+	return exists
+}
+
+// FilterResourcesByAPIVersion returns the resources written against the given
+// Tekton apiVersion
+func FilterResourcesByAPIVersion(resources []Resource, apiVersion string) []Resource {
+	filtered := []Resource{}
+	for _, resource := range resources {
+		if resource.APIVersion == apiVersion {
+			filtered = append(filtered, resource)
+		}
+	}
+	return filtered
+}
+
+// UpdateResourceValidation stores the apiVersion and quality found when
+// validating the latest version of a resource
+func UpdateResourceValidation(resourceID int, apiVersion string, quality int) error {
+	sqlStatement := `UPDATE RESOURCE SET API_VERSION=$2,QUALITY=$3 WHERE ID=$1`
+	_, err := DB.Exec(sqlStatement, resourceID, apiVersion, quality)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -44,7 +44,7 @@ func GetAllResourcesWithGivenTags(resourceType string, verified string, tags []s
 	}
 	for rows.Next() {
 		resource := Resource{}
-		err = rows.Scan(&resource.ID, &resource.Name, &resource.Type, &resource.Description, &resource.Downloads, &resource.Rating, &resource.Github, &resource.Verified)
+		err = rows.Scan(&resource.ID, &resource.Name, &resource.Type, &resource.Description, &resource.Downloads, &resource.Rating, &resource.Github, &resource.Verified, &resource.APIVersion, &resource.Quality, &resource.Critical, &resource.Deprecated)
 		if err != nil {
 			log.Println(err)
 		}
@@ -62,15 +62,15 @@ func executeTagsQuery(tags []string, params string, args []interface{}) (*sql.Ro
 	)
 	if len(tags) > 0 {
 		sqlStatement = `
-	SELECT DISTINCT T.ID,T.NAME,T.TYPE,T.DESCRIPTION,T.DOWNLOADS,T.RATING,T.GITHUB,T.VERIFIED
+	SELECT DISTINCT T.ID,T.NAME,T.TYPE,T.DESCRIPTION,T.DOWNLOADS,T.RATING,T.GITHUB,T.VERIFIED,COALESCE(T.API_VERSION,''),COALESCE(T.QUALITY,0),COALESCE(T.CRITICAL,FALSE),COALESCE(T.DEPRECATED,FALSE)
 	FROM RESOURCE AS T JOIN RESOURCE_TAG AS TT ON (T.ID=TT.RESOURCE_ID) JOIN TAG
 	AS TG ON (TG.ID=TT.TAG_ID AND TG.NAME in (` +
-			params + `));`
+			params + `)) WHERE T.DELETED_AT IS NULL ORDER BY T.ID;`
 		rows, err = DB.Query(sqlStatement, args...)
 	} else {
 		sqlStatement = `
-	SELECT DISTINCT T.ID,T.NAME,T.TYPE,T.DESCRIPTION,T.DOWNLOADS,T.RATING,T.GITHUB,T.VERIFIED
-	FROM RESOURCE T`
+	SELECT DISTINCT T.ID,T.NAME,T.TYPE,T.DESCRIPTION,T.DOWNLOADS,T.RATING,T.GITHUB,T.VERIFIED,COALESCE(T.API_VERSION,''),COALESCE(T.QUALITY,0),COALESCE(T.CRITICAL,FALSE),COALESCE(T.DEPRECATED,FALSE)
+	FROM RESOURCE T WHERE T.DELETED_AT IS NULL ORDER BY T.ID`
 		rows, err = DB.Query(sqlStatement)
 	}
 	return rows, err
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"log"
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/scan"
+)
+
+// ImageScan holds the vulnerabilities found by a scanner in an image. A
+// report is stored once for each tag and digest of the scanned image
+type ImageScan struct {
+	ID         int       `gorm:"primary_key;auto_increment" json:"id"`
+	Reference  string    `gorm:"not null;index" json:"reference"`
+	Registry   string    `json:"registry"`
+	Repository string    `json:"repository"`
+	Tag        string    `json:"tag"`
+	Digest     string    `gorm:"index" json:"digest"`
+	Scanner    string    `json:"scanner"`
+	Critical   int       `json:"critical"`
+	High       int       `json:"high"`
+	Medium     int       `json:"medium"`
+	Low        int       `json:"low"`
+	Unknown    int       `json:"unknown"`
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+// Counts returns the number of vulnerabilities of each severity
+func (s ImageScan) Counts() scan.Counts {
+	return scan.Counts{Critical: s.Critical, High: s.High, Medium: s.Medium, Low: s.Low, Unknown: s.Unknown}
+}
+
+// ImageVulnerabilities are the vulnerabilities of an image of a resource
+// version according to its latest scan
+type ImageVulnerabilities struct {
+	Reference  string      `json:"reference"`
+	Scanner    string      `json:"scanner"`
+	Counts     scan.Counts `json:"counts"`
+	ImportedAt time.Time   `json:"imported_at"`
+}
+
+// VersionVulnerabilities sums the vulnerabilities of the scanned images of
+// a resource version
+type VersionVulnerabilities struct {
+	VersionID int                    `json:"version_id"`
+	Version   string                 `json:"version"`
+	Counts    scan.Counts            `json:"counts"`
+	Images    []ImageVulnerabilities `json:"images"`
+}
+
+// scannedImage joins the images of resource versions RESOURCE_IMAGE I with
+// the latest scan IMAGE_SCAN S of the same digest, or of the same tag for
+// images referenced without a digest
+const scannedImage = `((I.DIGEST<>'' AND S.DIGEST=I.DIGEST AND S.REGISTRY=I.REGISTRY AND S.REPOSITORY=I.REPOSITORY
+	AND S.ID=(SELECT MAX(ID) FROM IMAGE_SCAN WHERE DIGEST=S.DIGEST AND REGISTRY=S.REGISTRY AND REPOSITORY=S.REPOSITORY))
+	OR (I.DIGEST='' AND S.REFERENCE=I.REFERENCE
+	AND S.ID=(SELECT MAX(ID) FROM IMAGE_SCAN WHERE REFERENCE=S.REFERENCE)))`
+
+// updateCritical flags the resources R whose latest version uses an image
+// with critical vulnerabilities
+const updateCritical = `
+	UPDATE RESOURCE R SET CRITICAL=EXISTS (
+		SELECT 1 FROM RESOURCE_VERSION V JOIN RESOURCE_IMAGE I ON I.RESOURCE_VERSION_ID=V.ID
+		JOIN IMAGE_SCAN S ON ` + scannedImage + `
+		WHERE V.RESOURCE_ID=R.ID AND ` + latestVersion + ` AND S.CRITICAL>0)`
+
+// AddImageScans stores the scans of an imported report
+func AddImageScans(scans []ImageScan) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer tx.Rollback()
+	sqlStatement := `
+	INSERT INTO IMAGE_SCAN(REFERENCE,REGISTRY,REPOSITORY,TAG,DIGEST,SCANNER,CRITICAL,HIGH,MEDIUM,LOW,UNKNOWN,IMPORTED_AT)
+	VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING ID`
+	for i := range scans {
+		s := &scans[i]
+		if s.ImportedAt.IsZero() {
+			s.ImportedAt = time.Now()
+		}
+		err := tx.QueryRow(sqlStatement, s.Reference, s.Registry, s.Repository, s.Tag, s.Digest, s.Scanner, s.Critical, s.High, s.Medium, s.Low, s.Unknown, s.ImportedAt).Scan(&s.ID)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// UpdateCriticalResources flags the resources whose latest version uses an
+// image with critical vulnerabilities and returns how many are flagged
+func UpdateCriticalResources() (int, error) {
+	if _, err := DB.Exec(updateCritical); err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	var flagged int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM RESOURCE WHERE CRITICAL`).Scan(&flagged); err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	return flagged, nil
+}
+
+// GetResourceVulnerabilities returns the vulnerabilities of each version of
+// a resource, oldest first. Images which were never scanned are left out
+func GetResourceVulnerabilities(resourceID int) ([]VersionVulnerabilities, error) {
+	versions, err := GetResourceVersions(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]VersionVulnerabilities, len(versions))
+	index := map[int]int{}
+	for i, v := range versions {
+		result[i] = VersionVulnerabilities{VersionID: v.ID, Version: v.Version, Images: []ImageVulnerabilities{}}
+		index[v.ID] = i
+	}
+
+	sqlStatement := `
+	SELECT V.ID,I.REFERENCE,S.SCANNER,S.CRITICAL,S.HIGH,S.MEDIUM,S.LOW,S.UNKNOWN,S.IMPORTED_AT
+	FROM RESOURCE_VERSION V JOIN RESOURCE_IMAGE I ON I.RESOURCE_VERSION_ID=V.ID
+	JOIN IMAGE_SCAN S ON ` + scannedImage + `
+	WHERE V.RESOURCE_ID=$1 ORDER BY V.ID,I.ID,S.ID DESC`
+	rows, err := DB.Query(sqlStatement, resourceID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	seen := map[int]map[string]bool{}
+	for rows.Next() {
+		var versionID int
+		var s ImageScan
+		var reference string
+		if err := rows.Scan(&versionID, &reference, &s.Scanner, &s.Critical, &s.High, &s.Medium, &s.Low, &s.Unknown, &s.ImportedAt); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		// an image used twice or matching both a tag and a digest scan is
+		// counted once
+		if seen[versionID] == nil {
+			seen[versionID] = map[string]bool{}
+		}
+		if seen[versionID][reference] {
+			continue
+		}
+		seen[versionID][reference] = true
+		v := &result[index[versionID]]
+		v.Images = append(v.Images, ImageVulnerabilities{Reference: reference, Scanner: s.Scanner, Counts: s.Counts(), ImportedAt: s.ImportedAt})
+		v.Counts = v.Counts.Add(s.Counts())
+	}
+	return result, rows.Err()
+}
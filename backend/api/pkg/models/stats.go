@@ -0,0 +1,124 @@
+package models
+
+import (
+	"log"
+	"time"
+)
+
+// DailyStat is the daily aggregate of downloads and ratings of a resource
+// version. Ratings are not versioned and are recorded with an empty version
+type DailyStat struct {
+	ResourceID int       `gorm:"primary_key;auto_increment:false" json:"resource_id"`
+	Version    string    `gorm:"primary_key" json:"version"`
+	Day        time.Time `gorm:"primary_key;type:date" json:"day"`
+	Downloads  int       `gorm:"default:0" json:"downloads"`
+	Ratings    int       `gorm:"default:0" json:"ratings"`
+	RatingSum  int       `gorm:"default:0" json:"rating_sum"`
+}
+
+// TrendingResource represents a resource ranked by growth in downloads
+type TrendingResource struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Rating    float64 `json:"rating"`
+	Downloads int     `json:"downloads"`
+	Recent    int     `json:"recent_downloads"`
+	Previous  int     `json:"previous_downloads"`
+	Growth    int     `json:"growth"`
+}
+
+// backfillRatingStats records the ratings given before the daily stats were
+// kept, so that the average of the series matches the rating of the resource.
+// They are recorded the day before the first stat of the resource, or
+// yesterday if it has none
+const backfillRatingStats = `
+INSERT INTO DAILY_STAT(RESOURCE_ID,VERSION,DAY,RATINGS,RATING_SUM)
+SELECT R.RESOURCE_ID,'',COALESCE(S.FIRST_DAY,CURRENT_DATE)-1,R.RATINGS-COALESCE(S.RATINGS,0),R.RATING_SUM-COALESCE(S.RATING_SUM,0)
+FROM (SELECT RESOURCE_ID,ONE_STAR+TWO_STAR+THREE_STAR+FOUR_STAR+FIVE_STAR AS RATINGS,
+	ONE_STAR+2*TWO_STAR+3*THREE_STAR+4*FOUR_STAR+5*FIVE_STAR AS RATING_SUM FROM RATING) R
+LEFT JOIN (SELECT RESOURCE_ID,MIN(DAY) AS FIRST_DAY,SUM(RATINGS) AS RATINGS,SUM(RATING_SUM) AS RATING_SUM
+	FROM DAILY_STAT GROUP BY RESOURCE_ID) S ON S.RESOURCE_ID=R.RESOURCE_ID
+WHERE R.RATINGS<>COALESCE(S.RATINGS,0) OR R.RATING_SUM<>COALESCE(S.RATING_SUM,0)
+ON CONFLICT (RESOURCE_ID,VERSION,DAY) DO UPDATE SET
+RATINGS=DAILY_STAT.RATINGS+EXCLUDED.RATINGS, RATING_SUM=DAILY_STAT.RATING_SUM+EXCLUDED.RATING_SUM`
+
+// addRatingStat records new ratings and the change in stars for the day
+func addRatingStat(resourceID int, ratings int, stars int) {
+	sqlStatement := `
+	INSERT INTO DAILY_STAT(RESOURCE_ID,VERSION,DAY,RATINGS,RATING_SUM) VALUES($1,'',$2,$3,$4)
+	ON CONFLICT (RESOURCE_ID,VERSION,DAY) DO UPDATE SET
+	RATINGS=DAILY_STAT.RATINGS+$3, RATING_SUM=DAILY_STAT.RATING_SUM+$4`
+	_, err := DB.Exec(sqlStatement, resourceID, time.Now().UTC().Format("2006-01-02"), ratings, stars)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// GetDailyStats returns the daily aggregates of a resource between the given
+// days along with the ratings recorded before the first day
+func GetDailyStats(resourceID int, from time.Time, to time.Time) ([]DailyStat, DailyStat, error) {
+	prior := DailyStat{ResourceID: resourceID}
+	sqlStatement := `
+	SELECT COALESCE(SUM(RATINGS),0),COALESCE(SUM(RATING_SUM),0) FROM DAILY_STAT
+	WHERE RESOURCE_ID=$1 AND DAY<$2`
+	err := DB.QueryRow(sqlStatement, resourceID, from.Format("2006-01-02")).Scan(&prior.Ratings, &prior.RatingSum)
+	if err != nil {
+		log.Println(err)
+		return nil, prior, err
+	}
+
+	sqlStatement = `
+	SELECT RESOURCE_ID,VERSION,DAY,DOWNLOADS,RATINGS,RATING_SUM FROM DAILY_STAT
+	WHERE RESOURCE_ID=$1 AND DAY>=$2 AND DAY<=$3 ORDER BY DAY`
+	rows, err := DB.Query(sqlStatement, resourceID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		log.Println(err)
+		return nil, prior, err
+	}
+	defer rows.Close()
+	stats := []DailyStat{}
+	for rows.Next() {
+		stat := DailyStat{}
+		err := rows.Scan(&stat.ResourceID, &stat.Version, &stat.Day, &stat.Downloads, &stat.Ratings, &stat.RatingSum)
+		if err != nil {
+			log.Println(err)
+			return nil, prior, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, prior, rows.Err()
+}
+
+// GetTrendingResources ranks resources by the growth of downloads in the last
+// given days compared to the days before that
+func GetTrendingResources(days int, limit int) ([]TrendingResource, error) {
+	today := time.Now().UTC()
+	since := today.AddDate(0, 0, -days).Format("2006-01-02")
+	before := today.AddDate(0, 0, -2*days).Format("2006-01-02")
+	sqlStatement := `
+	SELECT R.ID,R.NAME,R.TYPE,R.RATING,R.DOWNLOADS,
+	COALESCE(SUM(CASE WHEN S.DAY>$1 THEN S.DOWNLOADS END),0) AS RECENT,
+	COALESCE(SUM(CASE WHEN S.DAY<=$1 THEN S.DOWNLOADS END),0) AS PREVIOUS
+	FROM RESOURCE R JOIN DAILY_STAT S ON (S.RESOURCE_ID=R.ID)
+	WHERE S.DAY>$2 AND R.DELETED_AT IS NULL GROUP BY R.ID
+	ORDER BY RECENT-PREVIOUS DESC, RECENT DESC, R.ID LIMIT $3`
+	rows, err := DB.Query(sqlStatement, since, before, limit)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	resources := []TrendingResource{}
+	for rows.Next() {
+		r := TrendingResource{}
+		err := rows.Scan(&r.ID, &r.Name, &r.Type, &r.Rating, &r.Downloads, &r.Recent, &r.Previous)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		r.Growth = r.Recent - r.Previous
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
+}
@@ -1,6 +1,10 @@
 package models
 
-import "log"
+import (
+	"log"
+
+	"github.com/lib/pq"
+)
 
 // Tag is a model representing tags associated with tasks
 type Tag struct {
@@ -26,6 +30,28 @@ func GetAllTags() []Tag {
 	return tags
 }
 
+// GetTagsByCategories returns the tags of the given categories, by
+// category id
+func GetTagsByCategories(categoryIDs []int) (map[int][]Tag, error) {
+	sqlStatement := `SELECT ID,NAME,CATEGORY_ID FROM TAG WHERE CATEGORY_ID=ANY($1) ORDER BY ID`
+	rows, err := DB.Query(sqlStatement, pq.Array(categoryIDs))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	tags := map[int][]Tag{}
+	for rows.Next() {
+		tag := Tag{}
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.CategoryID); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		tags[tag.CategoryID] = append(tags[tag.CategoryID], tag)
+	}
+	return tags, rows.Err()
+}
+
 // AddTag will add a new tag
 func AddTag(tag string) (int, error) {
 	var newTagID int
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"strings"
+	"time"
+)
+
+// PersonalTokenPrefix starts the personal tokens, so that they are told
+// apart from the JWTs of the users
+const PersonalTokenPrefix = "hub_"
+
+// PersonalToken lets a user authenticate scripts and the CLI without
+// signing in with GitHub. Only a hash of the token is stored, the token
+// itself is shown once when it is created.
+type PersonalToken struct {
+	ID         int        `gorm:"primary_key;auto_increment" json:"id"`
+	UserID     int        `gorm:"not null;index" json:"user_id"`
+	Name       string     `json:"name"`
+	Hash       string     `gorm:"not null;unique_index" json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func hashPersonalToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AddPersonalToken creates a token for a user and returns it along with its
+// record
+func AddPersonalToken(userID int, name string) (PersonalToken, string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		log.Println(err)
+		return PersonalToken{}, "", err
+	}
+	token := PersonalTokenPrefix + hex.EncodeToString(secret)
+	t := PersonalToken{UserID: userID, Name: name, Hash: hashPersonalToken(token), CreatedAt: time.Now()}
+	sqlStatement := `INSERT INTO PERSONAL_TOKEN(USER_ID,NAME,HASH,CREATED_AT) VALUES($1,$2,$3,$4) RETURNING ID`
+	if err := DB.QueryRow(sqlStatement, t.UserID, t.Name, t.Hash, t.CreatedAt).Scan(&t.ID); err != nil {
+		log.Println(err)
+		return PersonalToken{}, "", err
+	}
+	return t, token, nil
+}
+
+// GetPersonalTokens returns the tokens of a user, oldest first
+func GetPersonalTokens(userID int) ([]PersonalToken, error) {
+	sqlStatement := `SELECT ID,USER_ID,NAME,CREATED_AT,LAST_USED_AT FROM PERSONAL_TOKEN WHERE USER_ID=$1 ORDER BY ID`
+	rows, err := DB.Query(sqlStatement, userID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	tokens := []PersonalToken{}
+	for rows.Next() {
+		t := PersonalToken{}
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Name, &t.CreatedAt, &t.LastUsedAt); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// DeletePersonalToken revokes a token of a user, sql.ErrNoRows is returned
+// if the user has no such token
+func DeletePersonalToken(userID int, tokenID int) error {
+	result, err := DB.Exec(`DELETE FROM PERSONAL_TOKEN WHERE ID=$1 AND USER_ID=$2`, tokenID, userID)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PersonalTokenUser returns the user of a personal token and records its
+// use, false is returned for an unknown or revoked token
+func PersonalTokenUser(token string) (int, bool) {
+	if !strings.HasPrefix(token, PersonalTokenPrefix) || DB == nil {
+		return 0, false
+	}
+	var userID int
+	sqlStatement := `UPDATE PERSONAL_TOKEN SET LAST_USED_AT=$2 WHERE HASH=$1 RETURNING USER_ID`
+	if err := DB.QueryRow(sqlStatement, hashPersonalToken(token), time.Now()).Scan(&userID); err != nil {
+		if err != sql.ErrNoRows {
+			log.Println(err)
+		}
+		return 0, false
+	}
+	return userID, true
+}
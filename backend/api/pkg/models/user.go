@@ -1,6 +1,11 @@
 package models
 
-import "log"
+import (
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 // User represents User model in database
 type User struct {
@@ -62,7 +67,7 @@ type UserResource struct {
 // GetAllResourcesByUser will return all tasks uploaded by user
 func GetAllResourcesByUser(userID int) []UserTaskResponse {
 	sqlStatement := `SELECT ID,NAME,DOWNLOADS,RATING FROM RESOURCE T JOIN USER_RESOURCE
-	U ON T.ID=U.RESOURCE_ID WHERE U.USER_ID=$1`
+	U ON T.ID=U.RESOURCE_ID WHERE U.USER_ID=$1 AND T.DELETED_AT IS NULL`
 	rows, err := DB.Query(sqlStatement, userID)
 	if err != nil {
 		log.Println(err)
@@ -80,6 +85,37 @@ func GetAllResourcesByUser(userID int) []UserTaskResponse {
 	return tasks
 }
 
+// DeletedResource is a deleted resource of a user which can be restored
+type DeletedResource struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// GetDeletedResourcesByUser returns the deleted resources of a user which
+// were deleted after deletedAfter, the last deleted first
+func GetDeletedResourcesByUser(userID int, deletedAfter time.Time) ([]DeletedResource, error) {
+	sqlStatement := `SELECT R.ID,R.NAME,R.TYPE,R.DELETED_AT FROM RESOURCE R JOIN USER_RESOURCE U ON R.ID=U.RESOURCE_ID
+	WHERE U.USER_ID=$1 AND R.DELETED_AT>$2 ORDER BY R.DELETED_AT DESC`
+	rows, err := DB.Query(sqlStatement, userID, deletedAfter)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	resources := []DeletedResource{}
+	for rows.Next() {
+		r := DeletedResource{}
+		if err := rows.Scan(&r.ID, &r.Name, &r.Type, &r.DeletedAt); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
+}
+
 // GetGithubToken will return github token by ID
 func GetGithubToken(userID int) string {
 	var token string
@@ -97,9 +133,11 @@ func AddResourceRawPath(resourcePath string, resourceID int, resourceType string
 	}
 }
 
-// GetResourceGithubDetails will return resource path and github details
+// GetResourceGithubDetails will return resource path and github details,
+// a deleted resource has none
 func GetResourceGithubDetails(resourceID int) ResourceGithubResponse {
-	sqlStatement := `SELECT * FROM GITHUB_DETAIL WHERE RESOURCE_ID=$1`
+	sqlStatement := `SELECT G.RESOURCE_ID,G.OWNER,G.REPOSITORY_NAME,G.PATH,COALESCE(G.README_PATH,'')
+	FROM GITHUB_DETAIL G JOIN RESOURCE R ON R.ID=G.RESOURCE_ID WHERE G.RESOURCE_ID=$1 AND R.DELETED_AT IS NULL`
 	githubDetails := ResourceGithubResponse{}
 	DB.QueryRow(sqlStatement, resourceID).Scan(&githubDetails.ResourceID, &githubDetails.Owner, &githubDetails.RepositoryName, &githubDetails.Path, &githubDetails.ReadmePath)
 	return githubDetails
@@ -126,3 +164,98 @@ func GetResourceRawLinks(resourceID int) RawLinksResponse {
 	}
 	return links
 }
+
+// GetGithubDetailsByResources returns the GitHub details of the given
+// resources, by resource id
+func GetGithubDetailsByResources(resourceIDs []int) (map[int]ResourceGithubResponse, error) {
+	sqlStatement := `SELECT RESOURCE_ID,OWNER,REPOSITORY_NAME,PATH,COALESCE(README_PATH,'') FROM GITHUB_DETAIL WHERE RESOURCE_ID=ANY($1)`
+	rows, err := DB.Query(sqlStatement, pq.Array(resourceIDs))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	details := map[int]ResourceGithubResponse{}
+	for rows.Next() {
+		d := ResourceGithubResponse{}
+		if err := rows.Scan(&d.ResourceID, &d.Owner, &d.RepositoryName, &d.Path, &d.ReadmePath); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		details[d.ResourceID] = d
+	}
+	return details, rows.Err()
+}
+
+// GetRawLinksByResources returns the raw GitHub links of the given
+// resources, by resource id
+func GetRawLinksByResources(resourceIDs []int) (map[int]RawLinksResponse, error) {
+	sqlStatement := `SELECT RESOURCE_ID,RAW_PATH,TYPE FROM RESOURCE_RAW_PATH WHERE RESOURCE_ID=ANY($1)`
+	rows, err := DB.Query(sqlStatement, pq.Array(resourceIDs))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	links := map[int]RawLinksResponse{}
+	for rows.Next() {
+		var resourceID int
+		var link, rawResourceType string
+		if err := rows.Scan(&resourceID, &link, &rawResourceType); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		l := links[resourceID]
+		if rawResourceType == "task" {
+			l.Tasks = append(l.Tasks, link)
+		} else if rawResourceType == "pipeline" {
+			l.Pipelines = append(l.Pipelines, link)
+		}
+		links[resourceID] = l
+	}
+	return links, rows.Err()
+}
+
+// GetUsersByIDs returns the users with the given ids, by id
+func GetUsersByIDs(ids []int) (map[int]UserCredential, error) {
+	sqlStatement := `SELECT ID,USER_NAME,COALESCE(FIRST_NAME,''),COALESCE(LAST_NAME,'') FROM USER_CREDENTIAL WHERE ID=ANY($1)`
+	rows, err := DB.Query(sqlStatement, pq.Array(ids))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	users := map[int]UserCredential{}
+	for rows.Next() {
+		user := UserCredential{}
+		if err := rows.Scan(&user.ID, &user.UserName, &user.FirstName, &user.LastName); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		users[user.ID] = user
+	}
+	return users, rows.Err()
+}
+
+// GetResourceIDsByUsers returns the ids of the resources uploaded by the
+// given users which are not deleted, by user id
+func GetResourceIDsByUsers(userIDs []int) (map[int][]int, error) {
+	sqlStatement := `SELECT U.USER_ID,R.ID FROM USER_RESOURCE U JOIN RESOURCE R ON R.ID=U.RESOURCE_ID
+	WHERE U.USER_ID=ANY($1) AND R.DELETED_AT IS NULL ORDER BY R.ID`
+	rows, err := DB.Query(sqlStatement, pq.Array(userIDs))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	resources := map[int][]int{}
+	for rows.Next() {
+		var userID, resourceID int
+		if err := rows.Scan(&userID, &resourceID); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		resources[userID] = append(resources[userID], resourceID)
+	}
+	return resources, rows.Err()
+}
@@ -1,6 +1,12 @@
 package models
 
-import "log"
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/lib/pq"
+)
 
 // UserRating represents relationship between User and Rating
 type UserRating struct {
@@ -9,37 +15,66 @@ type UserRating struct {
 	Stars      int `json:"stars"`
 }
 
+// ErrRatingExists is returned when a user rates a resource again, the
+// rating has to be updated instead
+var ErrRatingExists = errors.New("Use PUT method to update existing rating")
+
+// ErrUnknownRater is returned when the user or resource of a rating does
+// not exist
+var ErrUnknownRater = errors.New("Unknown user or resource")
+
+// ratingError tells a rating which already exists or whose user or resource
+// does not exist from other failures of its insert
+func ratingError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return ErrRatingExists
+		case "23503":
+			return ErrUnknownRater
+		}
+	}
+	return err
+}
+
 // AddRating add's rating provided by user
-func AddRating(userID int, resourceID int, stars int, prevStars int) interface{} {
+func AddRating(userID int, resourceID int, stars int, prevStars int) (UpdatedRatingResponse, error) {
 	sqlStatement := `INSERT INTO USER_RATING(USER_ID,RESOURCE_ID,STARS) VALUES($1,$2,$3)`
 	_, err := DB.Exec(sqlStatement, userID, resourceID, stars)
 	if err != nil {
 		log.Println(err)
-		return map[string]interface{}{"status": false, "message": "Use PUT method to update existing rating"}
+		return UpdatedRatingResponse{}, ratingError(err)
 	}
 	err = addStars(resourceID, stars, prevStars)
 	if err != nil {
-		return map[string]interface{}{"status": false, "message": "Not able to add stars to ratings table"}
+		return UpdatedRatingResponse{}, err
 	}
+	addRatingStat(resourceID, 1, stars)
 	averageRating := calculateAverageRating(resourceID)
 	err = updateAverageRating(resourceID, averageRating)
 	if err != nil {
-		return map[string]interface{}{"status": false, "message": "Unable to update average rating in task"}
+		return UpdatedRatingResponse{}, err
 	}
-	return updatedRatings(userID, resourceID)
+	return updatedRatings(userID, resourceID), nil
 }
 
-// UpdateRating will update existing rating
-func UpdateRating(userID int, resourceID int, stars int, prevStars int) UpdatedRatingResponse {
+// UpdateRating will update existing rating, sql.ErrNoRows is returned if
+// the user has not rated the resource
+func UpdateRating(userID int, resourceID int, stars int, prevStars int) (UpdatedRatingResponse, error) {
 	sqlStatement := `UPDATE USER_RATING SET STARS=$3 WHERE RESOURCE_ID=$2 AND USER_ID=$1`
-	_, err := DB.Exec(sqlStatement, userID, resourceID, stars)
+	res, err := DB.Exec(sqlStatement, userID, resourceID, stars)
 	if err != nil {
 		log.Println(err)
+		return UpdatedRatingResponse{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return UpdatedRatingResponse{}, sql.ErrNoRows
 	}
 	updateStars(resourceID, stars, prevStars)
+	addRatingStat(resourceID, 0, stars-prevStars)
 	averageRating := calculateAverageRating(resourceID)
 	updateAverageRating(resourceID, averageRating)
-	return updatedRatings(userID, resourceID)
+	return updatedRatings(userID, resourceID), nil
 }
 
 // GetUserRating queries for user rating by id
@@ -53,6 +88,28 @@ func GetUserRating(userID int, resourceID int) UserRating {
 	return userRating
 }
 
+// GetUserRatings returns the stars given by a user to the given resources,
+// by resource id. The resources the user did not rate are left out
+func GetUserRatings(userID int, resourceIDs []int) (map[int]int, error) {
+	sqlStatement := `SELECT RESOURCE_ID,STARS FROM USER_RATING WHERE USER_ID=$1 AND RESOURCE_ID=ANY($2)`
+	rows, err := DB.Query(sqlStatement, userID, pq.Array(resourceIDs))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	stars := map[int]int{}
+	for rows.Next() {
+		var resourceID, n int
+		if err := rows.Scan(&resourceID, &n); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		stars[resourceID] = n
+	}
+	return stars, rows.Err()
+}
+
 func updatedRatings(userID int, resourceID int) UpdatedRatingResponse {
 	updatedRatingResponse := UpdatedRatingResponse{}
 	rating := Rating{}
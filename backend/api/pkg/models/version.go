@@ -0,0 +1,283 @@
+package models
+
+import (
+	"database/sql"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/spec"
+)
+
+// ResourceVersion is the content of a resource as it was uploaded or synced
+type ResourceVersion struct {
+	ID         int    `gorm:"primary_key;auto_increment" json:"id"`
+	ResourceID int    `gorm:"not null;index" json:"resource_id"`
+	Version    string `json:"version"`
+	APIVersion string `json:"api_version"`
+	RawPath    string `json:"raw_path"`
+	// Digest is the digest of the bundle a version was imported from, the
+	// version cannot change as long as it has one
+	Digest    string    `gorm:"index" json:"digest,omitempty"`
+	Content   string    `gorm:"type:text" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Kinds of the fields of a resource interface
+const (
+	ParamField     = "param"
+	WorkspaceField = "workspace"
+	ResultField    = "result"
+	InputField     = "input"
+	OutputField    = "output"
+	StepField      = "step"
+	SidecarField   = "sidecar"
+	// StepTemplateField is the image of the stepTemplate of a Task, it
+	// is only recorded as an image
+	StepTemplateField = "step-template"
+)
+
+// ResourceInterface is a single field of the interface of a resource
+// version: a param, workspace, result, PipelineResource, step or sidecar
+type ResourceInterface struct {
+	ID                int    `gorm:"primary_key;auto_increment" json:"id"`
+	ResourceVersionID int    `gorm:"not null;index" json:"resource_version_id"`
+	Kind              string `gorm:"not null;index" json:"kind"`
+	Name              string `gorm:"index" json:"name"`
+	Type              string `json:"type"`
+	DefaultValue      string `json:"default_value"`
+	HasDefault        bool   `json:"has_default"`
+	Description       string `json:"description"`
+	Optional          bool   `json:"optional"`
+	Image             string `json:"image"`
+	MountPath         string `json:"mount_path"`
+}
+
+// AddResourceVersion stores a new version of a resource along with its
+// interface and images, and flags the resource if they have critical
+// vulnerabilities
+func AddResourceVersion(version *ResourceVersion, iface *spec.Interface) (int, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+	sqlStatement := `
+	INSERT INTO RESOURCE_VERSION(RESOURCE_ID,VERSION,API_VERSION,RAW_PATH,DIGEST,CONTENT,CREATED_AT)
+	VALUES($1,$2,$3,$4,$5,$6,$7) RETURNING ID`
+	err = tx.QueryRow(sqlStatement, version.ResourceID, version.Version, version.APIVersion, version.RawPath, version.Digest, version.Content, version.CreatedAt).Scan(&version.ID)
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	sqlStatement = `
+	INSERT INTO RESOURCE_INTERFACE(RESOURCE_VERSION_ID,KIND,NAME,TYPE,DEFAULT_VALUE,HAS_DEFAULT,DESCRIPTION,OPTIONAL,IMAGE,MOUNT_PATH)
+	VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`
+	for _, field := range interfaceFields(iface) {
+		_, err = tx.Exec(sqlStatement, version.ID, field.Kind, field.Name, field.Type, field.DefaultValue, field.HasDefault, field.Description, field.Optional, field.Image, field.MountPath)
+		if err != nil {
+			log.Println(err)
+			return 0, err
+		}
+	}
+	if err := addResourceImages(tx, version.ID, iface); err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	// the images of the new version may have scans of their own
+	if _, err := tx.Exec(updateCritical+` WHERE R.ID=$1`, version.ResourceID); err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return 0, err
+	}
+	return version.ID, nil
+}
+
+func interfaceFields(iface *spec.Interface) []ResourceInterface {
+	fields := []ResourceInterface{}
+	for _, p := range iface.Params {
+		fields = append(fields, ResourceInterface{Kind: ParamField, Name: p.Name, Type: p.Type, DefaultValue: p.Default, HasDefault: p.HasDefault, Description: p.Description})
+	}
+	for _, w := range iface.Workspaces {
+		fields = append(fields, ResourceInterface{Kind: WorkspaceField, Name: w.Name, Description: w.Description, Optional: w.Optional, MountPath: w.MountPath})
+	}
+	for _, r := range iface.Results {
+		fields = append(fields, ResourceInterface{Kind: ResultField, Name: r.Name, Description: r.Description})
+	}
+	for _, r := range iface.Resources {
+		kind := InputField
+		if r.Direction == spec.Output {
+			kind = OutputField
+		}
+		fields = append(fields, ResourceInterface{Kind: kind, Name: r.Name, Type: r.Type, Optional: r.Optional})
+	}
+	for _, s := range iface.Steps {
+		fields = append(fields, ResourceInterface{Kind: StepField, Name: s.Name, Image: s.Image})
+	}
+	for _, s := range iface.Sidecars {
+		fields = append(fields, ResourceInterface{Kind: SidecarField, Name: s.Name, Image: s.Image})
+	}
+	return fields
+}
+
+const resourceVersionColumns = `ID,RESOURCE_ID,VERSION,API_VERSION,RAW_PATH,COALESCE(DIGEST,''),CONTENT,CREATED_AT`
+
+// liveVersion keeps the versions of deleted resources out of the lookups
+const liveVersion = `EXISTS (SELECT 1 FROM RESOURCE R WHERE R.ID=RESOURCE_VERSION.RESOURCE_ID AND R.DELETED_AT IS NULL)`
+
+func scanResourceVersion(row interface{ Scan(...interface{}) error }) (ResourceVersion, error) {
+	v := ResourceVersion{}
+	err := row.Scan(&v.ID, &v.ResourceID, &v.Version, &v.APIVersion, &v.RawPath, &v.Digest, &v.Content, &v.CreatedAt)
+	return v, err
+}
+
+// GetResourceVersions returns all the versions of a resource, oldest first
+func GetResourceVersions(resourceID int) ([]ResourceVersion, error) {
+	sqlStatement := `SELECT ` + resourceVersionColumns + ` FROM RESOURCE_VERSION WHERE ` + liveVersion + ` AND RESOURCE_ID=$1 ORDER BY ID`
+	rows, err := DB.Query(sqlStatement, resourceID)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	versions := []ResourceVersion{}
+	for rows.Next() {
+		v, err := scanResourceVersion(rows)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// GetVersionsByResources returns the versions of the given resources,
+// oldest first, by resource id
+func GetVersionsByResources(resourceIDs []int) (map[int][]ResourceVersion, error) {
+	sqlStatement := `SELECT ` + resourceVersionColumns + ` FROM RESOURCE_VERSION WHERE ` + liveVersion + ` AND RESOURCE_ID=ANY($1) ORDER BY ID`
+	rows, err := DB.Query(sqlStatement, pq.Array(resourceIDs))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	versions := map[int][]ResourceVersion{}
+	for rows.Next() {
+		v, err := scanResourceVersion(rows)
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		versions[v.ResourceID] = append(versions[v.ResourceID], v)
+	}
+	return versions, rows.Err()
+}
+
+// GetResourceIDsWithoutVersion returns the resources which have no stored
+// version, they were added before versions were stored
+func GetResourceIDsWithoutVersion() ([]int, error) {
+	sqlStatement := `SELECT ID FROM RESOURCE WHERE DELETED_AT IS NULL AND
+	NOT EXISTS (SELECT 1 FROM RESOURCE_VERSION WHERE RESOURCE_ID=RESOURCE.ID) ORDER BY ID`
+	rows, err := DB.Query(sqlStatement)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			log.Println(err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetLatestResourceVersion returns the last version stored for a resource,
+// sql.ErrNoRows is returned if it has none
+func GetLatestResourceVersion(resourceID int) (ResourceVersion, error) {
+	sqlStatement := `SELECT ` + resourceVersionColumns + ` FROM RESOURCE_VERSION WHERE ` + liveVersion + ` AND RESOURCE_ID=$1 ORDER BY ID DESC LIMIT 1`
+	return scanResourceVersion(DB.QueryRow(sqlStatement, resourceID))
+}
+
+// GetResourceVersion returns the last stored content of a given version of
+// a resource, sql.ErrNoRows is returned if the version does not exist
+func GetResourceVersion(resourceID int, version string) (ResourceVersion, error) {
+	sqlStatement := `SELECT ` + resourceVersionColumns + ` FROM RESOURCE_VERSION WHERE ` + liveVersion + ` AND RESOURCE_ID=$1 AND VERSION=$2 ORDER BY ID DESC LIMIT 1`
+	return scanResourceVersion(DB.QueryRow(sqlStatement, resourceID, version))
+}
+
+// FindResourceVersion returns the version of a resource given by its
+// version label or, for unlabelled versions, by its id
+func FindResourceVersion(resourceID int, ref string) (ResourceVersion, error) {
+	v, err := GetResourceVersion(resourceID, ref)
+	if err != sql.ErrNoRows {
+		return v, err
+	}
+	versionID, convErr := strconv.Atoi(ref)
+	if convErr != nil {
+		return v, err
+	}
+	sqlStatement := `SELECT ` + resourceVersionColumns + ` FROM RESOURCE_VERSION WHERE ` + liveVersion + ` AND RESOURCE_ID=$1 AND ID=$2`
+	return scanResourceVersion(DB.QueryRow(sqlStatement, resourceID, versionID))
+}
+
+// GetResourceVersionByDigest returns the version of a resource imported
+// from the bundle of a digest, sql.ErrNoRows is returned if there is none
+func GetResourceVersionByDigest(resourceID int, digest string) (ResourceVersion, error) {
+	sqlStatement := `SELECT ` + resourceVersionColumns + ` FROM RESOURCE_VERSION WHERE ` + liveVersion + ` AND RESOURCE_ID=$1 AND DIGEST=$2 ORDER BY ID LIMIT 1`
+	return scanResourceVersion(DB.QueryRow(sqlStatement, resourceID, digest))
+}
+
+// GetPreviousResourceVersion returns the version stored before a version
+// of a resource, sql.ErrNoRows is returned if it is the first one
+func GetPreviousResourceVersion(resourceID int, versionID int) (ResourceVersion, error) {
+	sqlStatement := `SELECT ` + resourceVersionColumns + ` FROM RESOURCE_VERSION WHERE ` + liveVersion + ` AND RESOURCE_ID=$1 AND ID<$2 ORDER BY ID DESC LIMIT 1`
+	return scanResourceVersion(DB.QueryRow(sqlStatement, resourceID, versionID))
+}
+
+// GetResourceInterface returns the interface of the latest version of a
+// resource, nil is returned if the resource has no version yet
+func GetResourceInterface(resourceID int) (*spec.Interface, error) {
+	version, err := GetLatestResourceVersion(resourceID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return spec.Parse([]byte(version.Content))
+}
+
+// versionContents returns the YAML of the stored versions by version id
+func versionContents(tx *gorm.DB) (map[int]string, error) {
+	rows, err := tx.Raw(`SELECT ID,CONTENT FROM RESOURCE_VERSION`).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	contents := map[int]string{}
+	for rows.Next() {
+		var id int
+		var content string
+		if err := rows.Scan(&id, &content); err != nil {
+			return nil, err
+		}
+		contents[id] = content
+	}
+	return contents, rows.Err()
+}
@@ -0,0 +1,177 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// Object is a Tekton object of a bundle
+type Object struct {
+	// APIVersion is the version of the Tekton API, e.g. v1beta1
+	APIVersion string
+	// Kind is the lowercase kind of the object, e.g. task
+	Kind    string
+	Name    string
+	Content []byte
+}
+
+// Image is an image along with its blobs
+type Image struct {
+	Manifest []byte
+	Digest   string
+	Config   []byte
+	Layers   [][]byte
+}
+
+// Blob returns the config or a layer of the image by its digest
+func (i *Image) Blob(digest string) ([]byte, bool) {
+	if Digest(i.Config) == digest {
+		return i.Config, true
+	}
+	for _, layer := range i.Layers {
+		if Digest(layer) == digest {
+			return layer, true
+		}
+	}
+	return nil, false
+}
+
+// NewBundle returns the Tekton bundle of some objects, one layer each. The
+// bundle is built deterministically: the same objects always give an image
+// of the same digest
+func NewBundle(objects ...Object) (*Image, error) {
+	image := &Image{}
+	manifest := Manifest{SchemaVersion: 2, MediaType: ManifestMediaType, Layers: []Descriptor{}}
+	config := Config{RootFS: RootFS{Type: "layers", DiffIDs: []string{}}}
+	for _, object := range objects {
+		layer, diffID, err := objectLayer(object)
+		if err != nil {
+			return nil, err
+		}
+		image.Layers = append(image.Layers, layer)
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, diffID)
+		manifest.Layers = append(manifest.Layers, Descriptor{
+			MediaType: LayerMediaType,
+			Digest:    Digest(layer),
+			Size:      int64(len(layer)),
+			Annotations: map[string]string{
+				APIVersionAnnotation: object.APIVersion,
+				KindAnnotation:       strings.ToLower(object.Kind),
+				NameAnnotation:       object.Name,
+			},
+		})
+	}
+	var err error
+	if image.Config, err = json.Marshal(config); err != nil {
+		return nil, err
+	}
+	manifest.Config = Descriptor{MediaType: ConfigMediaType, Digest: Digest(image.Config), Size: int64(len(image.Config))}
+	if image.Manifest, err = json.Marshal(manifest); err != nil {
+		return nil, err
+	}
+	image.Digest = Digest(image.Manifest)
+	return image, nil
+}
+
+// objectLayer returns the gzipped tar of a single object along with the
+// digest of the tar. The tar header has no time nor owner and the gzip
+// stream is stored uncompressed, so that its bytes don't depend on the
+// version of the compressor
+func objectLayer(object Object) ([]byte, string, error) {
+	var tarball bytes.Buffer
+	tw := tar.NewWriter(&tarball)
+	header := &tar.Header{
+		Name:     fmt.Sprintf("%s-%s.yaml", strings.ToLower(object.Kind), object.Name),
+		Mode:     0644,
+		Size:     int64(len(object.Content)),
+		ModTime:  time.Unix(0, 0),
+		Typeflag: tar.TypeReg,
+		Format:   tar.FormatUSTAR,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return nil, "", err
+	}
+	if _, err := tw.Write(object.Content); err != nil {
+		return nil, "", err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, "", err
+	}
+	var layer bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&layer, gzip.NoCompression)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := gw.Write(tarball.Bytes()); err != nil {
+		return nil, "", err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, "", err
+	}
+	return layer.Bytes(), Digest(tarball.Bytes()), nil
+}
+
+// Objects returns the Tekton objects of a bundle, as given by the
+// annotations of its layers and the single file of each layer
+func (i *Image) Objects() ([]Object, error) {
+	manifest := Manifest{}
+	if err := json.Unmarshal(i.Manifest, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	if len(manifest.Layers) != len(i.Layers) {
+		return nil, errors.New("the image does not hold all the layers of its manifest")
+	}
+	objects := []Object{}
+	for n, layer := range manifest.Layers {
+		object := Object{
+			APIVersion: layer.Annotations[APIVersionAnnotation],
+			Kind:       strings.ToLower(layer.Annotations[KindAnnotation]),
+			Name:       layer.Annotations[NameAnnotation],
+		}
+		if object.Kind == "" || object.Name == "" {
+			return nil, fmt.Errorf("layer %d is not a Tekton object, it lacks the %s and %s annotations", n, KindAnnotation, NameAnnotation)
+		}
+		content, err := layerFile(layer.MediaType, i.Layers[n])
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %v", n, err)
+		}
+		object.Content = content
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// layerFile returns the content of the single file of a layer, gzipped or
+// not
+func layerFile(mediaType string, layer []byte) ([]byte, error) {
+	var r io.Reader = bytes.NewReader(layer)
+	if strings.HasSuffix(mediaType, "gzip") {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("the layer holds no file")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA {
+			return ioutil.ReadAll(io.LimitReader(tr, maxBlobSize))
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package oci
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// maxBlobSize bounds the size of the manifests and blobs pulled, bundles
+// only hold a few YAML documents
+const maxBlobSize = 10 << 20
+
+// Client pulls images from a registry
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	username   string
+
+	mu sync.Mutex
+	// scopes holds the tokens issued by the token service of the registry
+	// for each repository
+	scopes map[string]string
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithHTTPClient sends the requests with an HTTP client other than
+// http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken authenticates the requests with a bearer token. When the
+// registry asks for a token of its token service instead, the token is only
+// sent to the token service, as the password of the username if one is set
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithUsername sets the username the token is the password of, for the
+// token services and the registries using basic authentication
+func WithUsername(username string) ClientOption {
+	return func(c *Client) {
+		c.username = username
+	}
+}
+
+// Host returns the host of the registry, e.g. registry.example.com
+func (c *Client) Host() string {
+	host := c.baseURL
+	if i := strings.Index(host, "://"); i != -1 {
+		host = host[i+3:]
+	}
+	return strings.SplitN(host, "/", 2)[0]
+}
+
+// NewClient returns a client of the registry at baseURL, e.g.
+// https://registry.example.com
+func NewClient(baseURL string, opts ...ClientOption) *Client {
+	c := &Client{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: http.DefaultClient, scopes: map[string]string{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// get fetches a manifest or a blob of a repository and checks it against its
+// digest, the digest is taken from the response when the reference is a tag
+func (c *Client) get(repository, path, accept, digest string) ([]byte, error) {
+	path = repository + path
+	res, err := c.send(path, accept, c.authorization(repository))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		authorization, err := c.authenticate(repository, res.Header.Get("WWW-Authenticate"))
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("GET %s: %v", path, err)
+		}
+		if res, err = c.send(path, accept, authorization); err != nil {
+			return nil, err
+		}
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(http.MaxBytesReader(nil, res.Body, maxBlobSize))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", path, responseError(res.Status, data))
+	}
+	if digest == "" {
+		digest = res.Header.Get("Docker-Content-Digest")
+	}
+	if digest != "" && Digest(data) != digest {
+		return nil, fmt.Errorf("GET %s: content does not match digest %s", path, digest)
+	}
+	return data, nil
+}
+
+func (c *Client) send(path, accept, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/v2/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return c.httpClient.Do(req)
+}
+
+// authorization returns the Authorization header of the requests for a
+// repository: the token issued for it if there is one, the configured token
+// otherwise unless it is the password of a username
+func (c *Client) authorization(repository string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if token, ok := c.scopes[repository]; ok {
+		return "Bearer " + token
+	}
+	if c.token != "" && c.username == "" {
+		return "Bearer " + c.token
+	}
+	return ""
+}
+
+// authenticate answers the challenge of a 401 response and returns the
+// Authorization header to retry with. A Bearer challenge is answered with a
+// token of the token service of the registry, which is kept for the later
+// requests for the repository
+func (c *Client) authenticate(repository, header string) (string, error) {
+	scheme, params := parseChallenge(header)
+	switch {
+	case strings.EqualFold(scheme, "basic") && c.username != "":
+		return "Basic " + basicAuth(c.username, c.token), nil
+	case strings.EqualFold(scheme, "basic"):
+		return "", errors.New("unauthorized, the registry requires a username and a token")
+	case !strings.EqualFold(scheme, "bearer") || params["realm"] == "":
+		return "", fmt.Errorf("unauthorized, unsupported challenge %q", header)
+	}
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + repository + ":pull"
+	}
+	query.Set("scope", scope)
+	req, err := http.NewRequest(http.MethodGet, params["realm"], nil)
+	if err != nil {
+		return "", err
+	}
+	req.URL.RawQuery = query.Encode()
+	switch {
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.token)
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(http.MaxBytesReader(nil, res.Body, maxBlobSize))
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token service: %s", responseError(res.Status, data))
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", fmt.Errorf("token service: invalid response: %v", err)
+	}
+	token := body.Token
+	if token == "" {
+		token = body.AccessToken
+	}
+	if token == "" {
+		return "", errors.New("token service: no token in the response")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scopes[repository] = token
+	return "Bearer " + token, nil
+}
+
+func basicAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g.
+// Bearer realm="https://auth.example.com/token",service="registry.example.com"
+func parseChallenge(header string) (string, map[string]string) {
+	params := map[string]string{}
+	header = strings.TrimSpace(header)
+	i := strings.IndexByte(header, ' ')
+	if i == -1 {
+		return header, params
+	}
+	scheme, rest := header[:i], header[i+1:]
+	for {
+		rest = strings.TrimLeft(rest, " ,")
+		eq := strings.IndexByte(rest, '=')
+		if eq == -1 {
+			return scheme, params
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			// quoted values may hold commas, e.g. the scope
+			end := 1
+			for end < len(rest) && rest[end] != '"' {
+				if rest[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(rest) {
+				return scheme, params
+			}
+			value = strings.Replace(rest[1:end], `\"`, `"`, -1)
+			rest = rest[end+1:]
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end == -1 {
+				end = len(rest)
+			}
+			value, rest = strings.TrimSpace(rest[:end]), rest[end:]
+		}
+		params[key] = value
+	}
+}
+
+// responseError returns the first error of a registry response
+func responseError(status string, data []byte) string {
+	var body struct {
+		Errors []registryError `json:"errors"`
+	}
+	if json.Unmarshal(data, &body) == nil && len(body.Errors) > 0 {
+		return fmt.Sprintf("%s: %s", body.Errors[0].Code, body.Errors[0].Message)
+	}
+	return status
+}
+
+// Pull fetches the manifest of an image by tag or digest along with its
+// config and layers, all of them are checked against their digest
+func (c *Client) Pull(repository, reference string) (*Image, error) {
+	digest := ""
+	if IsDigest(reference) {
+		digest = reference
+	}
+	data, err := c.get(repository, "/manifests/"+reference, ManifestMediaType+", "+DockerManifestMediaType, digest)
+	if err != nil {
+		return nil, err
+	}
+	manifest := Manifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	switch {
+	case manifest.SchemaVersion != 2:
+		return nil, fmt.Errorf("unsupported manifest schema %d, expected an image manifest", manifest.SchemaVersion)
+	case manifest.MediaType != "" && manifest.MediaType != ManifestMediaType && manifest.MediaType != DockerManifestMediaType:
+		return nil, fmt.Errorf("unsupported manifest %q, expected an OCI or Docker image manifest", manifest.MediaType)
+	}
+	image := &Image{Manifest: data, Digest: Digest(data)}
+	if image.Config, err = c.get(repository, "/blobs/"+manifest.Config.Digest, "", manifest.Config.Digest); err != nil {
+		return nil, err
+	}
+	for _, layer := range manifest.Layers {
+		blob, err := c.get(repository, "/blobs/"+layer.Digest, "", layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		image.Layers = append(image.Layers, blob)
+	}
+	return image, nil
+}
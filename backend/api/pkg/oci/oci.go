@@ -0,0 +1,88 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Media types of the documents of an image
+const (
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// DockerManifestMediaType is the Docker image manifest, which has the
+	// same format, still pushed by many tools
+	DockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	ConfigMediaType         = "application/vnd.oci.image.config.v1+json"
+	LayerMediaType          = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// Annotations of the layers of a Tekton bundle, each layer holds a single
+// Tekton object
+const (
+	APIVersionAnnotation = "dev.tekton.image.apiVersion"
+	KindAnnotation       = "dev.tekton.image.kind"
+	NameAnnotation       = "dev.tekton.image.name"
+)
+
+// ErrNameUnknown is returned for a repository which is not known
+var ErrNameUnknown = errors.New("repository name not known to registry")
+
+var (
+	tagPattern    = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`)
+	digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// Descriptor points to a blob by its digest
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest lists the config and the layers of an image
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Config is the configuration of an image, a bundle only fills its root
+// filesystem
+type Config struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	RootFS       RootFS `json:"rootfs"`
+}
+
+// RootFS holds the digests of the uncompressed layers of an image
+type RootFS struct {
+	Type    string   `json:"type"`
+	DiffIDs []string `json:"diff_ids"`
+}
+
+// Digest returns the sha256 digest of a blob, e.g. sha256:e3b0c442...
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// IsDigest checks if a manifest reference is a digest rather than a tag
+func IsDigest(reference string) bool {
+	return strings.Contains(reference, ":")
+}
+
+// ValidTag checks if a tag can be used in a manifest reference
+func ValidTag(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
+
+// ValidDigest checks if a digest is a sha256 digest, the only algorithm
+// supported
+func ValidDigest(digest string) bool {
+	return digestPattern.MatchString(digest)
+}
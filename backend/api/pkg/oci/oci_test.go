@@ -0,0 +1,217 @@
+package oci
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const gitClone = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: git-clone
+`
+
+type testSource struct {
+	tags   map[string][]Tag
+	pulled []string
+}
+
+func (s *testSource) Tags(repository string) ([]Tag, error) {
+	tags, ok := s.tags[repository]
+	if !ok {
+		return nil, ErrNameUnknown
+	}
+	return tags, nil
+}
+
+func (s *testSource) Pulled(w http.ResponseWriter, r *http.Request, repository string, tag Tag) {
+	s.pulled = append(s.pulled, repository+":"+tag.Name)
+	w.Header().Set("Warning", `299 - "`+repository+` is deprecated"`)
+}
+
+func testRegistry(t *testing.T) (*httptest.Server, *testSource, *Image) {
+	image, err := NewBundle(Object{APIVersion: "v1beta1", Kind: "Task", Name: "git-clone", Content: []byte(gitClone)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &testSource{tags: map[string][]Tag{
+		"catalog/git-clone": {{Name: "0.2", Image: image}, {Name: "latest", Image: image}},
+	}}
+	r := mux.NewRouter()
+	NewRegistry(source).Register(r)
+	return httptest.NewServer(r), source, image
+}
+
+func TestNewBundle(t *testing.T) {
+	object := Object{APIVersion: "v1beta1", Kind: "Task", Name: "git-clone", Content: []byte(gitClone)}
+	first, err := NewBundle(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := NewBundle(object)
+	if first.Digest != second.Digest {
+		t.Errorf("expected the same digest for the same objects, got %s and %s", first.Digest, second.Digest)
+	}
+	objects, err := first.Objects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Kind != "task" || objects[0].Name != "git-clone" || string(objects[0].Content) != gitClone {
+		t.Errorf("unexpected objects %+v", objects)
+	}
+}
+
+func TestPull(t *testing.T) {
+	server, source, image := testRegistry(t)
+	defer server.Close()
+	client := NewClient(server.URL)
+	for _, reference := range []string{"0.2", image.Digest} {
+		pulled, err := client.Pull("catalog/git-clone", reference)
+		if err != nil {
+			t.Fatalf("%s: %v", reference, err)
+		}
+		if pulled.Digest != image.Digest {
+			t.Errorf("%s: expected digest %s, got %s", reference, image.Digest, pulled.Digest)
+		}
+		objects, err := pulled.Objects()
+		if err != nil || len(objects) != 1 || string(objects[0].Content) != gitClone {
+			t.Errorf("%s: unexpected objects %+v %v", reference, objects, err)
+		}
+	}
+	if len(source.pulled) != 2 || source.pulled[0] != "catalog/git-clone:0.2" {
+		t.Errorf("unexpected pulls %v", source.pulled)
+	}
+	res, err := http.Get(server.URL + "/v2/catalog/git-clone/manifests/0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if warning := res.Header.Get("Warning"); warning != `299 - "catalog/git-clone is deprecated"` {
+		t.Errorf("expected the headers of the source, got warning %q", warning)
+	}
+
+	tests := []struct {
+		repository, reference, expected string
+	}{
+		{"catalog/unknown", "0.2", "NAME_UNKNOWN"},
+		{"catalog/git-clone", "0.3", "MANIFEST_UNKNOWN"},
+		{"catalog/git-clone", "sha256:123", "DIGEST_INVALID"},
+	}
+	for _, tc := range tests {
+		_, err := client.Pull(tc.repository, tc.reference)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%s:%s expected %s, got %v", tc.repository, tc.reference, tc.expected, err)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	server, _, image := testRegistry(t)
+	defer server.Close()
+
+	res, err := http.Head(server.URL + "/v2/catalog/git-clone/manifests/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.Header.Get("Docker-Content-Digest") != image.Digest || len(body) != 0 {
+		t.Errorf("unexpected HEAD response %v %q", res.Header, body)
+	}
+
+	res, err = http.Get(server.URL + "/v2/catalog/git-clone/tags/list?n=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	list := struct {
+		Name string
+		Tags []string
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Name != "catalog/git-clone" || len(list.Tags) != 1 || list.Tags[0] != "0.2" {
+		t.Errorf("unexpected tags %+v", list)
+	}
+	if link := res.Header.Get("Link"); link != `</v2/catalog/git-clone/tags/list?n=1&last=0.2>; rel="next"` {
+		t.Errorf("unexpected link %q", link)
+	}
+}
+
+func TestPullAuthentication(t *testing.T) {
+	image, err := NewBundle(Object{APIVersion: "v1beta1", Kind: "Task", Name: "git-clone", Content: []byte(gitClone)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same image with a Docker manifest
+	manifest := Manifest{}
+	json.Unmarshal(image.Manifest, &manifest)
+	manifest.MediaType = DockerManifestMediaType
+	docker := *image
+	docker.Manifest, _ = json.Marshal(manifest)
+	docker.Digest = Digest(docker.Manifest)
+
+	r := mux.NewRouter()
+	issued := 0
+	r.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "robot" || password != "secret" || r.FormValue("service") != "registry.test" ||
+			r.FormValue("scope") != "repository:catalog/git-clone:pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		issued++
+		json.NewEncoder(w).Encode(map[string]string{"token": "pull-token"})
+	})
+	NewRegistry(&testSource{tags: map[string][]Tag{
+		"catalog/git-clone": {{Name: "0.2", Image: image}, {Name: "docker", Image: &docker}},
+	}}).Register(r)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/v2/") && req.Header.Get("Authorization") != "Bearer pull-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry.test",scope="repository:catalog/git-clone:pull"`)
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required", "")
+			return
+		}
+		r.ServeHTTP(w, req)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithUsername("robot"), WithToken("secret"))
+	for _, reference := range []string{"0.2", "docker"} {
+		pulled, err := client.Pull("catalog/git-clone", reference)
+		if err != nil {
+			t.Fatalf("%s: %v", reference, err)
+		}
+		if objects, err := pulled.Objects(); err != nil || len(objects) != 1 || string(objects[0].Content) != gitClone {
+			t.Errorf("%s: unexpected objects %+v %v", reference, objects, err)
+		}
+	}
+	// the token is kept for the repository
+	if issued != 1 {
+		t.Errorf("expected a single token to be issued, got %d", issued)
+	}
+
+	_, err = NewClient(server.URL, WithUsername("robot"), WithToken("wrong")).Pull("catalog/git-clone", "0.2")
+	if err == nil || !strings.Contains(err.Error(), "token service: 401") {
+		t.Errorf("expected the token service to refuse the credentials, got %v", err)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:a/b:pull,push"`)
+	if scheme != "Bearer" || params["realm"] != "https://auth.example.com/token" || params["service"] != "registry.example.com" ||
+		params["scope"] != "repository:a/b:pull,push" {
+		t.Errorf("unexpected challenge %s %v", scheme, params)
+	}
+	if scheme, params := parseChallenge(`Basic realm=registry`); scheme != "Basic" || params["realm"] != "registry" {
+		t.Errorf("unexpected challenge %s %v", scheme, params)
+	}
+}
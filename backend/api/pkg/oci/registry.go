@@ -0,0 +1,182 @@
+package oci
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Error codes of the distribution API
+const (
+	NameUnknown     = "NAME_UNKNOWN"
+	ManifestUnknown = "MANIFEST_UNKNOWN"
+	BlobUnknown     = "BLOB_UNKNOWN"
+	DigestInvalid   = "DIGEST_INVALID"
+	Unknown         = "UNKNOWN"
+)
+
+// Tag is an image of a repository along with its tag
+type Tag struct {
+	Name  string
+	Image *Image
+	// Replaced is set when the tag was moved to another image, the image is
+	// then only served by its digest so that the references pinning it
+	// still resolve
+	Replaced bool
+}
+
+// Source holds the repositories served by a registry
+type Source interface {
+	// Tags returns the tagged images of a repository along with the
+	// replaced ones, ErrNameUnknown if there is no such repository
+	Tags(repository string) ([]Tag, error)
+	// Pulled is called when the manifest of a tag is fetched, before it is
+	// written so that headers can be added to the response
+	Pulled(w http.ResponseWriter, r *http.Request, repository string, tag Tag)
+}
+
+// Registry serves the read-only part of the OCI distribution API, enough to
+// pull the images of a source
+type Registry struct {
+	source Source
+}
+
+// NewRegistry returns a registry serving the images of a source
+func NewRegistry(source Source) *Registry {
+	return &Registry{source: source}
+}
+
+// Register adds the /v2 routes of the registry to a router. Repository
+// names may hold slashes, e.g. /v2/catalog/git-clone/manifests/0.2
+func (reg *Registry) Register(r *mux.Router) {
+	r.HandleFunc("/v2/", reg.base).Methods("GET", "HEAD")
+	r.HandleFunc("/v2/{name:.+}/manifests/{reference}", reg.manifest).Methods("GET", "HEAD")
+	r.HandleFunc("/v2/{name:.+}/blobs/{digest}", reg.blob).Methods("GET", "HEAD")
+	r.HandleFunc("/v2/{name:.+}/tags/list", reg.tags).Methods("GET")
+}
+
+type registryError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// writeError writes an error in the format expected by the registry clients,
+// which is not the one of the rest of the API
+func writeError(w http.ResponseWriter, status int, code, message, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string][]registryError{"errors": {{Code: code, Message: message, Detail: detail}}})
+}
+
+func (reg *Registry) base(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("{}"))
+}
+
+// repositoryTags writes an error and returns false if the tags of the
+// repository of the path cannot be listed
+func (reg *Registry) repositoryTags(w http.ResponseWriter, r *http.Request) ([]Tag, bool) {
+	name := mux.Vars(r)["name"]
+	tags, err := reg.source.Tags(name)
+	if err == ErrNameUnknown {
+		writeError(w, http.StatusNotFound, NameUnknown, "repository name not known to registry", name)
+		return nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, Unknown, "unable to read the repository", name)
+		return nil, false
+	}
+	return tags, true
+}
+
+// writeBlob writes a manifest or a blob, only its headers for HEAD requests
+func writeBlob(w http.ResponseWriter, r *http.Request, mediaType string, data []byte) {
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Docker-Content-Digest", Digest(data))
+	w.Header().Set("Etag", `"`+Digest(data)+`"`)
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(data)
+}
+
+// manifest serves the manifest of an image by tag or digest
+func (reg *Registry) manifest(w http.ResponseWriter, r *http.Request) {
+	reference := mux.Vars(r)["reference"]
+	if IsDigest(reference) && !ValidDigest(reference) {
+		writeError(w, http.StatusBadRequest, DigestInvalid, "provided digest did not match uploaded content", reference)
+		return
+	}
+	tags, ok := reg.repositoryTags(w, r)
+	if !ok {
+		return
+	}
+	for _, tag := range tags {
+		if !tag.Replaced && tag.Name == reference || tag.Image.Digest == reference {
+			if r.Method == http.MethodGet {
+				reg.source.Pulled(w, r, mux.Vars(r)["name"], tag)
+			}
+			writeBlob(w, r, ManifestMediaType, tag.Image.Manifest)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, ManifestUnknown, "manifest unknown", reference)
+}
+
+// blob serves the config or a layer of any image of a repository
+func (reg *Registry) blob(w http.ResponseWriter, r *http.Request) {
+	digest := mux.Vars(r)["digest"]
+	if !ValidDigest(digest) {
+		writeError(w, http.StatusBadRequest, DigestInvalid, "provided digest did not match uploaded content", digest)
+		return
+	}
+	tags, ok := reg.repositoryTags(w, r)
+	if !ok {
+		return
+	}
+	for _, tag := range tags {
+		if data, ok := tag.Image.Blob(digest); ok {
+			writeBlob(w, r, "application/octet-stream", data)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, BlobUnknown, "blob unknown to registry", digest)
+}
+
+// tags lists the tags of a repository in lexical order, n and last paginate
+// the list as in the distribution API
+func (reg *Registry) tags(w http.ResponseWriter, r *http.Request) {
+	tags, ok := reg.repositoryTags(w, r)
+	if !ok {
+		return
+	}
+	names := []string{}
+	for _, tag := range tags {
+		if !tag.Replaced {
+			names = append(names, tag.Name)
+		}
+	}
+	sort.Strings(names)
+	if last := r.URL.Query().Get("last"); last != "" {
+		i := sort.SearchStrings(names, last)
+		if i < len(names) && names[i] == last {
+			i++
+		}
+		names = names[i:]
+	}
+	if n, err := strconv.Atoi(r.URL.Query().Get("n")); err == nil && n > 0 && n < len(names) {
+		names = names[:n]
+		w.Header().Set("Link", `<`+r.URL.Path+`?n=`+strconv.Itoa(n)+`&last=`+names[len(names)-1]+`>; rel="next"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}{mux.Vars(r)["name"], names})
+}
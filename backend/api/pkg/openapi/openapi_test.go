@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type node struct {
+	Name     string            `json:"name"`
+	Children []*node           `json:"children,omitempty"`
+	Labels   map[string]string `json:"labels"`
+	Created  time.Time         `json:"created"`
+	Hidden   string            `json:"-"`
+	internal int
+}
+
+func TestSchema(t *testing.T) {
+	d := New("test", "v1")
+	s := d.Schema([]node{})
+	if s.Type != "array" || s.Items.Ref != "#/components/schemas/Openapinode" {
+		t.Fatalf("unexpected schema %+v", s)
+	}
+	c := d.Components.Schemas["Openapinode"]
+	if c == nil {
+		t.Fatal("expected the struct in the components")
+	}
+	if len(c.Properties) != 4 {
+		t.Errorf("expected 4 properties, got %v", c.Properties)
+	}
+	if c.Properties["children"].Items.Ref != "#/components/schemas/Openapinode" {
+		t.Errorf("expected the recursive type to refer to itself, got %+v", c.Properties["children"])
+	}
+	if c.Properties["labels"].AdditionalProperties.Type != "string" {
+		t.Errorf("unexpected map schema %+v", c.Properties["labels"])
+	}
+	if c.Properties["created"].Format != "date-time" {
+		t.Errorf("unexpected time schema %+v", c.Properties["created"])
+	}
+}
+
+func TestCheck(t *testing.T) {
+	d := New("test", "v1")
+	ok := map[string]*Response{"200": {Description: "success"}}
+	d.Add("GET", "/items/{id}", &Operation{
+		OperationID: "getItem",
+		Parameters:  []Parameter{{Name: "id", In: "path", Required: true, Schema: Integer}},
+		Responses:   ok,
+	})
+	if err := d.Check(); err != nil {
+		t.Fatal(err)
+	}
+	d.Add("DELETE", "/items/{id}", &Operation{OperationID: "getItem", Responses: ok})
+	err := d.Check()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, problem := range []string{`path parameter "id" is not documented`, `operation id "getItem"`} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("expected %q in %v", problem, err)
+		}
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 // GetDirContents returns the contents of a directory
 func GetDirContents(ctx context.Context, client *github.Client, owner, repo, path string, options *github.RepositoryContentGetOptions) ([]*github.RepositoryContent, error) {
-	_, dirs, _, err := client.Repositories.GetContents(ctx, owner, repo, path, nil)
+	_, dirs, _, err := client.Repositories.GetContents(ctx, owner, repo, path, options)
 	if err != nil {
 		return nil, err
 	}
@@ -17,7 +17,7 @@ func GetDirContents(ctx context.Context, client *github.Client, owner, repo, pat
 
 // GetFileContent returns the contents of a file
 func GetFileContent(ctx context.Context, client *github.Client, owner, repo, path string, options *github.RepositoryContentGetOptions) (*github.RepositoryContent, error) {
-	files, _, _, err := client.Repositories.GetContents(ctx, owner, repo, path, nil)
+	files, _, _, err := client.Repositories.GetContents(ctx, owner, repo, path, options)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,81 @@
+package purge
+
+import (
+	"os"
+	"time"
+
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/app"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultRestoreWindow is how long deleted resources can be restored
+	// unless RESTORE_WINDOW is set
+	defaultRestoreWindow = 30 * 24 * time.Hour
+	// interval between two purges
+	interval = time.Hour
+)
+
+// RestoreWindow returns how long a deleted resource can be restored. It is
+// set with the RESTORE_WINDOW environment variable, e.g. 168h
+func RestoreWindow() time.Duration {
+	if window, err := time.ParseDuration(os.Getenv("RESTORE_WINDOW")); err == nil && window > 0 {
+		return window
+	}
+	return defaultRestoreWindow
+}
+
+// Purger deletes for good the resources whose restore window is over
+type Purger struct {
+	log    *zap.SugaredLogger
+	window time.Duration
+	purge  func(deletedBefore time.Time) (int, error)
+}
+
+func New(app app.Config) *Purger {
+	return newPurger(app.Logger().With("name", "purge"), RestoreWindow(), purgeOnce)
+}
+
+// purgeOnce purges the deleted resources unless another replica of the hub
+// is already purging them
+func purgeOnce(deletedBefore time.Time) (int, error) {
+	purged := 0
+	var err error
+	_, lockErr := models.WithLock(models.PurgeLock, func() {
+		purged, err = models.PurgeDeletedResources(deletedBefore)
+	})
+	if lockErr != nil {
+		return 0, lockErr
+	}
+	return purged, err
+}
+
+func newPurger(log *zap.SugaredLogger, window time.Duration, purge func(time.Time) (int, error)) *Purger {
+	return &Purger{log: log, window: window, purge: purge}
+}
+
+// Start purges the resources in the background, once right away and then
+// every hour
+func (p *Purger) Start() {
+	go func() {
+		for {
+			p.Purge(time.Now())
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// Purge deletes the resources deleted before the restore window preceding
+// now and returns how many were deleted
+func (p *Purger) Purge(now time.Time) int {
+	purged, err := p.purge(now.Add(-p.window))
+	if err != nil {
+		p.log.Error(err)
+		return 0
+	}
+	if purged > 0 {
+		p.log.Infof("purged %d deleted resources", purged)
+	}
+	return purged
+}
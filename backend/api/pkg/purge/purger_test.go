@@ -0,0 +1,36 @@
+package purge
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestPurge(t *testing.T) {
+	now := time.Now()
+	var cutoff time.Time
+	purger := newPurger(zap.NewNop().Sugar(), 24*time.Hour, func(deletedBefore time.Time) (int, error) {
+		cutoff = deletedBefore
+		return 2, nil
+	})
+	if purged := purger.Purge(now); purged != 2 {
+		t.Errorf("Purged Expected: %v , Got: %v", 2, purged)
+	}
+	if !cutoff.Equal(now.Add(-24 * time.Hour)) {
+		t.Errorf("Resources deleted before %v should be purged, got %v", now.Add(-24*time.Hour), cutoff)
+	}
+}
+
+func TestRestoreWindow(t *testing.T) {
+	defer os.Unsetenv("RESTORE_WINDOW")
+	os.Setenv("RESTORE_WINDOW", "168h")
+	if window := RestoreWindow(); window != 168*time.Hour {
+		t.Errorf("Restore window Expected: %v , Got: %v", 168*time.Hour, window)
+	}
+	os.Setenv("RESTORE_WINDOW", "a week")
+	if window := RestoreWindow(); window != defaultRestoreWindow {
+		t.Errorf("Invalid restore window should fall back to %v, got %v", defaultRestoreWindow, window)
+	}
+}
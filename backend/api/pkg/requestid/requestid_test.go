@@ -0,0 +1,34 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromRequest(r)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/resources", nil))
+	if len(seen) != 32 || w.Header().Get(Header) != seen {
+		t.Errorf("Expected a generated request id, got %q and header %q", seen, w.Header().Get(Header))
+	}
+
+	r := httptest.NewRequest("GET", "/resources", nil)
+	r.Header.Set(Header, "proxy-1234")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if seen != "proxy-1234" || w.Header().Get(Header) != "proxy-1234" {
+		t.Errorf("Expected the request id of the proxy, got %q", seen)
+	}
+
+	r.Header.Set(Header, "bad id\n")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if seen == "bad id\n" {
+		t.Errorf("Expected an invalid request id to be replaced")
+	}
+}
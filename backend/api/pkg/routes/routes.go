@@ -1,30 +1,62 @@
 package routes
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/gorilla/mux"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/api"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/app"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/requestid"
 )
 
 // Register registers all routes with router
 func Register(r *mux.Router, conf app.Config) {
 	api := api.New(conf)
+	r.Use(requestid.Middleware)
+
+	registerV1(r, api)
+	// the OCI distribution API is served at /v2 where the registry clients
+	// expect it
+	api.Registry().Register(r)
 
-	r.HandleFunc("/resource/{id}", api.GetResourceByID).Methods("GET") //
-	r.HandleFunc("/resource/{id}", api.DeleteResourceHandler).Methods("DELETE")
-	r.HandleFunc("/resource/yaml/{id}", api.GetResourceYAMLFile).Methods("GET")     //
-	r.HandleFunc("/resource/readme/{id}", api.GetResourceReadmeFile).Methods("GET") //
-	r.HandleFunc("/tags", api.GetAllTags).Methods("GET")                            //
-	r.HandleFunc("/categories", api.GetAllCategorieswithTags).Methods("GET")        //
-	r.Path("/resources/{type}/{verified}").Queries("tags", "{tags}").HandlerFunc(api.GetAllFilteredResourcesByTag).Methods("GET")
-	r.HandleFunc("/resources", api.GetAllResources).Methods("GET")    //
-	r.HandleFunc("/rating", api.AddRating).Methods("POST")            //
-	r.HandleFunc("/rating", api.UpdateRating).Methods("PUT")          //
-	r.HandleFunc("/rating/{id}", api.GetRatingDetails).Methods("GET") //
-	r.HandleFunc("/upload", api.Upload).Methods("POST")               //
-	r.HandleFunc("/stars", api.GetPrevStars).Methods("POST")          //
+	// the routes served before /v1 are kept for the existing clients, they
+	// point to their v1 successor in their response headers, the endpoints
+	// added since are only served under /v1
+	legacy := func(path, method, successor string, handler http.HandlerFunc) *mux.Route {
+		return r.HandleFunc(path, deprecated(successor, handler)).Methods(method)
+	}
+	legacy("/resource/{id}", "GET", "/v1/resources/{id}", api.GetResourceByID) //
+	legacy("/resource/{id}", "DELETE", "/v1/resources/{id}", api.DeleteResourceHandler)
+	legacy("/resource/yaml/{id}", "GET", "/v1/resources/{id}/yaml", api.GetResourceYAMLFile)       //
+	legacy("/resource/readme/{id}", "GET", "/v1/resources/{id}/readme", api.GetResourceReadmeFile) //
+	legacy("/tags", "GET", "/v1/tags", api.GetAllTags)                                             //
+	legacy("/categories", "GET", "/v1/categories", api.GetAllCategorieswithTags)                   //
+	legacy("/resources/{type}/{verified}", "GET", "/v1/resources?type={type}&verified={verified}", api.GetAllFilteredResourcesByTag).Queries("tags", "{tags}")
+	legacy("/resources", "GET", "/v1/resources", api.GetAllResources)                 //
+	legacy("/rating", "POST", "/v1/resources/{id}/ratings", api.AddRating)            //
+	legacy("/rating", "PUT", "/v1/resources/{id}/ratings", api.UpdateRating)          //
+	legacy("/rating/{id}", "GET", "/v1/resources/{id}/ratings", api.GetRatingDetails) //
+	legacy("/upload", "POST", "/v1/resources", api.Upload)                            //
+	legacy("/stars", "POST", "/v1/resources/{id}/ratings/{user}", api.GetPrevStars)   //
+
+	legacy("/oauth/redirect", "POST", "/v1/auth/github", api.GithubAuth)                                //
+	legacy("/resources/user/{id}", "GET", "/v1/users/{id}/resources", api.GetAllResourcesByUserHandler) //
+	legacy("/resource/links/{id}", "GET", "/v1/resources/{id}/links", api.GetResourceLinksHandler)      //
+}
 
-	r.HandleFunc("/oauth/redirect", api.GithubAuth).Methods("POST")                       //
-	r.HandleFunc("/resources/user/{id}", api.GetAllResourcesByUserHandler).Methods("GET") //
-	r.HandleFunc("/resource/links/{id}", api.GetResourceLinksHandler).Methods("GET")      //
+// deprecated marks the responses of a legacy route as deprecated and links
+// to the route replacing it, the variables of the route are filled in the
+// successor
+func deprecated(successor string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		link := successor
+		for name, value := range mux.Vars(r) {
+			link = strings.Replace(link, "{"+name+"}", value, -1)
+		}
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+		handler(w, r)
+	}
 }
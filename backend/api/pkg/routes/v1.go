@@ -0,0 +1,371 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/mux"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/analytics"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/api"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/diff"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/graph"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/graphql"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/openapi"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/upload"
+)
+
+// V1 is the prefix of the routes of the v1 API
+const V1 = "/v1"
+
+// patterns restrict the path parameters of the v1 routes, so that
+// /v1/resources/search is not read as the resource "search"
+var patterns = map[string]string{
+	"id":   "[0-9]+",
+	"user": "[0-9]+",
+	// image references hold slashes, e.g. gcr.io/kaniko-project/executor:v0.13.0
+	"ref": ".+",
+	// versions are labels such as 0.2 or the ids of unlabelled versions
+	"version": "[^/]+",
+}
+
+// endpoint is a route of the v1 API along with its documentation
+type endpoint struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+	summary string
+	tag     string
+	params  []openapi.Parameter
+	// body and response are values of the types of the JSON bodies, a nil
+	// response is written as is with the content type
+	body        interface{}
+	response    interface{}
+	contentType string
+	// auth is set for the endpoints requiring a bearer token
+	auth bool
+}
+
+func pathParam(name, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "path", Description: description, Required: true, Schema: openapi.Integer}
+}
+
+func stringParam(name, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "path", Description: description, Required: true, Schema: openapi.String}
+}
+
+func query(name string, schema *openapi.Schema, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func list(schema *openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{Type: "array", Items: schema}
+}
+
+var (
+	resourceID = pathParam("id", "id of the resource")
+	formats    = query("format", openapi.String, "format of the bundle: yaml, zip or tar.gz")
+	// pageParams paginate the lists whose total is in api.TotalCountHeader
+	pageParams = []openapi.Parameter{
+		query("limit", openapi.Integer, "number of items, all of them by default"),
+		query("offset", openapi.Integer, "number of items skipped"),
+	}
+	// resolverParams give a resource as the Tekton hub resolver does
+	resolverParams = []openapi.Parameter{
+		stringParam("catalog", "GitHub owner or repository of the resource, or the name of the hub catalog"),
+		stringParam("kind", "task or pipeline"),
+		stringParam("name", "name of the resource"),
+	}
+)
+
+// v1Endpoints returns the endpoints of the v1 API, the handlers are not
+// called so h may be nil to document the API
+func v1Endpoints(h *api.Api) []endpoint {
+	return []endpoint{
+		{method: "GET", path: "/resources", handler: h.ListResources, tag: "resources",
+			summary: "List the resources",
+			params: []openapi.Parameter{
+				query("type", openapi.String, "task, pipeline or all"),
+				query("verified", openapi.String, "true, false or all"),
+				query("tag", list(openapi.String), "tags of the resources, any of them matches"),
+				query("apiVersion", openapi.String, "Tekton apiVersion of the resources"),
+				pageParams[0], pageParams[1],
+			},
+			response: []models.Resource{}},
+		{method: "POST", path: "/resources", handler: h.Upload, tag: "resources",
+			summary: "Upload a task or pipeline from GitHub for the signed in user",
+			body:    upload.NewUploadRequestObject{}, response: map[string]interface{}{}, auth: true},
+		{method: "POST", path: "/resources/bundles", handler: h.ImportBundle, tag: "resources",
+			summary: "Import a task or pipeline from a Tekton bundle of the configured registry",
+			body:    upload.BundleImportRequest{}, response: map[string]interface{}{}, auth: true},
+		{method: "GET", path: "/resources/search", handler: h.SearchResources, tag: "resources",
+			summary: "Search the resources by their interface",
+			params: []openapi.Parameter{
+				query("param", list(openapi.String), "params the resource has"),
+				query("workspace", list(openapi.String), "workspaces the resource has"),
+				query("result", list(openapi.String), "results the resource has"),
+				query("resource", list(openapi.String), "PipelineResources the resource has"),
+				query("type", openapi.String, "task or pipeline"),
+				query("deprecated", openapi.Boolean, "include the deprecated resources"),
+				pageParams[0], pageParams[1],
+			},
+			response: []models.Resource{}},
+		{method: "GET", path: "/resources/trending", handler: h.GetTrendingResources, tag: "resources",
+			summary: "List the resources ranked by recent growth in downloads",
+			params: []openapi.Parameter{
+				query("days", openapi.Integer, "days of the recent period, 7 by default"),
+				query("limit", openapi.Integer, "number of resources, 10 by default"),
+			},
+			response: []models.TrendingResource{}},
+		{method: "GET", path: "/resources/{id}", handler: h.GetResourceByID, tag: "resources",
+			summary: "Get a resource", params: []openapi.Parameter{resourceID},
+			response: models.Resource{}},
+		{method: "DELETE", path: "/resources/{id}", handler: h.DeleteResourceHandler, tag: "resources",
+			summary: "Delete a resource, it can be restored until it is purged", params: []openapi.Parameter{resourceID},
+			response: map[string]interface{}{}, auth: true},
+		{method: "POST", path: "/resources/{id}/restore", handler: h.RestoreResource, tag: "resources",
+			summary: "Restore a deleted resource", params: []openapi.Parameter{resourceID},
+			response: map[string]interface{}{}, auth: true},
+		{method: "GET", path: "/resources/{id}/yaml", handler: h.GetResourceYAMLFile, tag: "resources",
+			summary: "Get the YAML file of a resource", params: []openapi.Parameter{resourceID},
+			contentType: "text/plain"},
+		{method: "GET", path: "/resources/{id}/readme", handler: h.GetResourceReadmeFile, tag: "resources",
+			summary: "Get the README file of a resource", params: []openapi.Parameter{resourceID},
+			contentType: "text/plain"},
+		{method: "GET", path: "/resources/{id}/links", handler: h.GetResourceLinksHandler, tag: "resources",
+			summary: "Get the raw GitHub links of the files of a resource", params: []openapi.Parameter{resourceID},
+			response: models.RawLinksResponse{}},
+		{method: "GET", path: "/resources/{id}/dependencies", handler: h.GetResourceDependencies, tag: "resources",
+			summary: "Get the graph of the tasks used by a pipeline",
+			params: []openapi.Parameter{resourceID,
+				query("transitive", openapi.Boolean, "include the indirect dependencies"),
+				query("format", openapi.String, "json, dot or mermaid")},
+			response: graph.Graph{}},
+		{method: "GET", path: "/resources/{id}/dependents", handler: h.GetResourceDependents, tag: "resources",
+			summary: "Get the graph of the pipelines using a task",
+			params: []openapi.Parameter{resourceID,
+				query("transitive", openapi.Boolean, "include the indirect dependents"),
+				query("format", openapi.String, "json, dot or mermaid")},
+			response: graph.Graph{}},
+		{method: "GET", path: "/resources/{id}/bundle", handler: h.GetResourceBundle, tag: "resources",
+			summary: "Download a resource with the tasks it depends on",
+			params: []openapi.Parameter{resourceID, formats,
+				query("version", openapi.String, "stored version to download, the current YAML by default")},
+			contentType: "application/octet-stream"},
+		{method: "GET", path: "/resources/{id}/install", handler: h.InstallResource, tag: "resources",
+			summary: "Install a resource, counted as a download",
+			params: []openapi.Parameter{resourceID, formats,
+				query("version", openapi.String, "stored version to install, the current YAML by default")},
+			contentType: "application/octet-stream"},
+		{method: "GET", path: "/resources/{id}/stats", handler: h.GetResourceStats, tag: "resources",
+			summary: "Get the downloads and ratings of a resource over time",
+			params: []openapi.Parameter{resourceID,
+				query("from", openapi.String, "first day, YYYY-MM-DD"),
+				query("to", openapi.String, "last day, YYYY-MM-DD, at most 366 days after from"),
+				query("interval", openapi.String, "day, week or month")},
+			response: analytics.Series{}},
+		{method: "POST", path: "/resources/{id}/sync", handler: h.SyncResource, tag: "resources",
+			summary: "Fetch the YAML of a resource again and record a new version", params: []openapi.Parameter{resourceID},
+			response: map[string]interface{}{}, auth: true},
+		{method: "GET", path: "/resources/{id}/versions", handler: h.GetResourceVersions, tag: "resources",
+			summary: "List the versions of a resource, oldest first", params: []openapi.Parameter{resourceID},
+			response: []models.ResourceVersion{}},
+		{method: "GET", path: "/resources/{id}/versions/{version}", handler: h.GetResourceVersionYAML, tag: "resources",
+			summary: "Get the YAML of a version of a resource",
+			params: []openapi.Parameter{resourceID,
+				{Name: "version", In: "path", Description: "version label, or id of an unlabelled version", Required: true, Schema: openapi.String}},
+			contentType: "text/plain"},
+		{method: "GET", path: "/resources/{id}/diff", handler: h.GetResourceDiff, tag: "resources",
+			summary: "Compare two versions of a resource",
+			params: []openapi.Parameter{resourceID,
+				query("from", openapi.String, "version compared, the version before to by default"),
+				query("to", openapi.String, "version compared to, the latest by default")},
+			response: diff.Diff{}},
+		{method: "GET", path: "/resources/{id}/vulnerabilities", handler: h.GetResourceVulnerabilities, tag: "resources",
+			summary: "Get the vulnerabilities of the images of each version of a resource", params: []openapi.Parameter{resourceID},
+			response: []models.VersionVulnerabilities{}},
+		{method: "POST", path: "/resources/{id}/deprecation", handler: h.DeprecateResource, tag: "resources",
+			summary: "Deprecate a resource, by its owner or an admin", params: []openapi.Parameter{resourceID},
+			body: api.DeprecationRequest{}, response: map[string]interface{}{}, auth: true},
+		{method: "DELETE", path: "/resources/{id}/deprecation", handler: h.UndeprecateResource, tag: "resources",
+			summary: "Remove the deprecation of a resource, by its owner or an admin", params: []openapi.Parameter{resourceID},
+			response: map[string]interface{}{}, auth: true},
+		{method: "PUT", path: "/resources/{id}/verification", handler: h.VerifyResource, tag: "admin",
+			summary: "Change whether a resource is verified", params: []openapi.Parameter{resourceID},
+			body: api.VerificationRequest{}, response: map[string]interface{}{}, auth: true},
+		{method: "GET", path: "/resources/{id}/ratings", handler: h.GetRatingDetails, tag: "ratings",
+			summary: "Get the ratings of a resource", params: []openapi.Parameter{resourceID},
+			response: models.Rating{}},
+		{method: "POST", path: "/resources/{id}/ratings", handler: h.AddRating, tag: "ratings",
+			summary: "Rate a resource as the signed in user", params: []openapi.Parameter{resourceID},
+			body: api.AddRatingsRequest{}, response: models.UpdatedRatingResponse{}, auth: true},
+		{method: "PUT", path: "/resources/{id}/ratings", handler: h.UpdateRating, tag: "ratings",
+			summary: "Change the rating of a resource by the signed in user", params: []openapi.Parameter{resourceID},
+			body: api.AddRatingsRequest{}, response: models.UpdatedRatingResponse{}, auth: true},
+		{method: "GET", path: "/resources/{id}/ratings/{user}", handler: h.GetUserRating, tag: "ratings",
+			summary:  "Get the rating of a resource by a user",
+			params:   []openapi.Parameter{resourceID, pathParam("user", "id of the user")},
+			response: models.UserRating{}},
+		{method: "GET", path: "/users/{id}/resources", handler: h.GetAllResourcesByUserHandler, tag: "users",
+			summary: "List the resources uploaded by a user", params: []openapi.Parameter{pathParam("id", "id of the user")},
+			response: []models.UserTaskResponse{}},
+		{method: "GET", path: "/users/{id}/resources/deleted", handler: h.GetDeletedResourcesByUser, tag: "users",
+			summary:  "List the deleted resources of a user which can still be restored, to the user or an admin",
+			params:   []openapi.Parameter{pathParam("id", "id of the user")},
+			response: []api.DeletedResource{}, auth: true},
+		{method: "GET", path: "/tags", handler: h.GetAllTags, tag: "catalog",
+			summary: "List the tags", response: []models.Tag{}},
+		{method: "GET", path: "/categories", handler: h.GetAllCategorieswithTags, tag: "catalog",
+			summary: "List the categories with their tags", response: map[string][]string{}},
+		{method: "GET", path: "/images", handler: h.GetImages, tag: "images",
+			summary: "List the container images used by the resources", response: []models.Image{}},
+		{method: "GET", path: "/images/{ref}/resources", handler: h.GetImageResources, tag: "images",
+			summary: "List the resources using an image",
+			params: []openapi.Parameter{
+				{Name: "ref", In: "path", Description: "reference of the image", Required: true, Schema: openapi.String},
+				query("all", openapi.Boolean, "include the older versions of the resources"),
+			},
+			response: []models.ImageResource{}},
+		{method: "POST", path: "/scans", handler: h.ImportScanReport, tag: "admin",
+			summary: "Import a Trivy or Grype report of an image",
+			params:  []openapi.Parameter{query("image", openapi.String, "scanned image, read from the report by default")},
+			body:    map[string]interface{}{}, response: api.ScanImportResponse{}, auth: true},
+		{method: "POST", path: "/auth/github", handler: h.GithubAuth, tag: "users",
+			summary: "Sign in with a GitHub OAuth code",
+			body:    api.Code{}, response: map[string]interface{}{}},
+		{method: "GET", path: "/user", handler: h.GetCurrentUser, tag: "users",
+			summary: "Get the signed in user", response: api.CurrentUser{}, auth: true},
+		{method: "GET", path: "/tokens", handler: h.GetTokens, tag: "users",
+			summary: "List the personal tokens of the user", response: []models.PersonalToken{}, auth: true},
+		{method: "POST", path: "/tokens", handler: h.CreateToken, tag: "users",
+			summary: "Create a personal token, it is only shown in the response",
+			body:    api.TokenRequest{}, response: api.TokenResponse{}, auth: true},
+		{method: "DELETE", path: "/tokens/{id}", handler: h.DeleteToken, tag: "users",
+			summary: "Revoke a personal token", params: []openapi.Parameter{pathParam("id", "id of the token")},
+			response: map[string]interface{}{}, auth: true},
+		{method: "GET", path: "/audit/events", handler: h.GetAuditEvents, tag: "admin",
+			summary: "List the events of the audit log, oldest first",
+			params:  auditParams(), response: []models.AuditEvent{}, auth: true},
+		{method: "GET", path: "/audit/events/export", handler: h.ExportAuditEvents, tag: "admin",
+			summary: "Export the events of the audit log as JSON Lines",
+			params:  auditParams(), contentType: "application/x-ndjson", auth: true},
+		{method: "GET", path: "/resource/{catalog}/{kind}/{name}", handler: h.GetResolverVersions, tag: "resolver",
+			summary: "List the versions of a resource given by its catalog, kind and name",
+			params:  resolverParams, response: api.ResolverVersionsResponse{}},
+		{method: "GET", path: "/resource/{catalog}/{kind}/{name}/yaml", handler: h.ResolveResource, tag: "resolver",
+			summary: "Resolve the latest version of a resource for the Tekton hub resolver",
+			params:  resolverParams, response: api.ResolverResponse{}},
+		{method: "GET", path: "/resource/{catalog}/{kind}/{name}/{version}/yaml", handler: h.ResolveResource, tag: "resolver",
+			summary:  "Resolve a version of a resource for the Tekton hub resolver",
+			params:   append(resolverParams[:3:3], stringParam("version", "version label, or id of an unlabelled version")),
+			response: api.ResolverResponse{}},
+		{method: "POST", path: "/graphql", handler: h.GraphQL, tag: "graphql",
+			summary: "Run a GraphQL query on the resources, versions, tags, categories, ratings and users",
+			body:    graphql.Request{}, response: graphql.Response{}},
+		{method: "GET", path: "/graphql/schema", handler: h.GetGraphQLSchema, tag: "graphql",
+			summary: "Get the GraphQL schema in the schema definition language", contentType: "text/plain"},
+	}
+}
+
+func auditParams() []openapi.Parameter {
+	return []openapi.Parameter{
+		query("action", openapi.String, "action of the events, e.g. resource.delete"),
+		query("actor", openapi.String, "actor of the events, e.g. user:42"),
+		query("target_type", openapi.String, "type of the targets"),
+		query("target_id", openapi.Integer, "id of the target"),
+		query("request_id", openapi.String, "id of the request"),
+		query("since", openapi.String, "RFC 3339 time of the first events"),
+		query("until", openapi.String, "RFC 3339 time after the last events"),
+		query("after_id", openapi.Integer, "id of the last event of the previous page"),
+		query("limit", openapi.Integer, "number of events, 100 by default and 1000 at most"),
+	}
+}
+
+// muxPath restricts the path parameters of a path with their patterns
+func muxPath(path string) string {
+	for name, pattern := range patterns {
+		path = strings.Replace(path, "{"+name+"}", "{"+name+":"+pattern+"}", -1)
+	}
+	return path
+}
+
+// operationID names an operation after its method and path, e.g.
+// getResourcesIdRatings
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// Spec returns the OpenAPI document of the v1 API
+func Spec() *openapi.Document {
+	doc := openapi.New("Tekton Hub API", "v1")
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		"bearer": {Type: "http", Scheme: "bearer", Description: "the JWT or a personal token of a user, or the ADMIN_TOKEN"},
+	}
+	errorSchema := doc.Schema(apierror.Body{})
+	for _, e := range v1Endpoints(nil) {
+		op := &openapi.Operation{
+			OperationID: operationID(e.method, e.path),
+			Summary:     e.summary,
+			Tags:        []string{e.tag},
+			Parameters:  e.params,
+			Responses: map[string]*openapi.Response{
+				"default": {
+					Description: "error",
+					Content:     map[string]*openapi.MediaType{"application/json": {Schema: errorSchema}},
+				},
+			},
+		}
+		if e.body != nil {
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]*openapi.MediaType{"application/json": {Schema: doc.Schema(e.body)}},
+			}
+		}
+		ok := &openapi.Response{Description: "success"}
+		if e.response != nil {
+			ok.Content = map[string]*openapi.MediaType{"application/json": {Schema: doc.Schema(e.response)}}
+		} else {
+			schema := openapi.String
+			if e.contentType == "application/octet-stream" {
+				schema = openapi.Binary
+			}
+			ok.Content = map[string]*openapi.MediaType{e.contentType: {Schema: schema}}
+		}
+		op.Responses["200"] = ok
+		if e.auth {
+			op.Security = []map[string][]string{{"bearer": {}}}
+		}
+		doc.Add(e.method, V1+e.path, op)
+	}
+	doc.Add("GET", V1+"/openapi.json", &openapi.Operation{
+		OperationID: "getOpenapiJson",
+		Summary:     "Get the OpenAPI document of the API",
+		Tags:        []string{"catalog"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "success", Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "object"}}}},
+		},
+	})
+	return doc
+}
+
+// registerV1 registers the routes of the v1 API and its OpenAPI document
+func registerV1(r *mux.Router, h *api.Api) {
+	v1 := r.PathPrefix(V1).Subrouter()
+	for _, e := range v1Endpoints(h) {
+		v1.HandleFunc(muxPath(e.path), e.handler).Methods(e.method)
+	}
+	spec := Spec()
+	v1.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(spec)
+	}).Methods("GET")
+}
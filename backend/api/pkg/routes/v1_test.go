@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSpec(t *testing.T) {
+	doc := Spec()
+	if err := doc.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Components.Schemas["ModelsResource"]; !ok {
+		t.Error("expected the resource schema in the components")
+	}
+
+	// every route of the router is documented
+	r := mux.NewRouter()
+	registerV1(r, nil)
+	pattern := regexp.MustCompile(`{([^:}]+):[^}]+}`)
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || path == V1 {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		path = pattern.ReplaceAllString(path, "{$1}")
+		for _, method := range methods {
+			if _, ok := doc.Paths[path][strings.ToLower(method)]; !ok {
+				t.Errorf("%s %s is not documented", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestV1Routes(t *testing.T) {
+	r := mux.NewRouter()
+	registerV1(r, nil)
+	tests := []struct {
+		method, path, expected string
+	}{
+		{"GET", "/v1/resources/search", "/v1/resources/search"},
+		{"GET", "/v1/resources/12", "/v1/resources/{id:[0-9]+}"},
+		{"PUT", "/v1/resources/12/ratings", "/v1/resources/{id:[0-9]+}/ratings"},
+		{"GET", "/v1/images/gcr.io/kaniko-project/executor:v0.13.0/resources", "/v1/images/{ref:.+}/resources"},
+		{"GET", "/v1/resource/tekton/task/git-clone/yaml", "/v1/resource/{catalog}/{kind}/{name}/yaml"},
+		{"GET", "/v1/resource/tekton/task/git-clone/0.2/yaml", "/v1/resource/{catalog}/{kind}/{name}/{version:[^/]+}/yaml"},
+	}
+	for _, tc := range tests {
+		var match mux.RouteMatch
+		if !r.Match(httptest.NewRequest(tc.method, tc.path, nil), &match) {
+			t.Errorf("%s %s: no route", tc.method, tc.path)
+			continue
+		}
+		if template, _ := match.Route.GetPathTemplate(); template != tc.expected {
+			t.Errorf("%s %s: expected route %s, got %s", tc.method, tc.path, tc.expected, template)
+		}
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	r := mux.NewRouter()
+	called := false
+	r.HandleFunc("/resource/{id}", deprecated("/v1/resources/{id}", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/resource/3", nil))
+	if !called {
+		t.Fatal("expected the legacy handler to be called")
+	}
+	if w.Header().Get("Deprecation") != "true" || w.Header().Get("Link") != `</v1/resources/3>; rel="successor-version"` {
+		t.Errorf("unexpected headers %v", w.Header())
+	}
+}
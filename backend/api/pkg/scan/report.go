@@ -0,0 +1,200 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Scanners whose reports can be imported
+const (
+	Trivy = "trivy"
+	Grype = "grype"
+)
+
+// Severities of a vulnerability
+const (
+	Critical = "critical"
+	High     = "high"
+	Medium   = "medium"
+	Low      = "low"
+	Unknown  = "unknown"
+)
+
+// Counts holds the number of vulnerabilities of each severity
+type Counts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+func (c *Counts) add(severity string) {
+	switch strings.ToLower(severity) {
+	case Critical:
+		c.Critical++
+	case High:
+		c.High++
+	case Medium:
+		c.Medium++
+	case Low, "negligible":
+		c.Low++
+	default:
+		c.Unknown++
+	}
+}
+
+// Add sums the vulnerabilities of two counts
+func (c Counts) Add(o Counts) Counts {
+	return Counts{
+		Critical: c.Critical + o.Critical,
+		High:     c.High + o.High,
+		Medium:   c.Medium + o.Medium,
+		Low:      c.Low + o.Low,
+		Unknown:  c.Unknown + o.Unknown,
+	}
+}
+
+// Report is the summary of a scan of an image
+type Report struct {
+	Scanner string `json:"scanner"`
+	// Image is the image as given to the scanner, Aliases are the tags and
+	// digests the scanner found for it
+	Image   string   `json:"image"`
+	Aliases []string `json:"aliases"`
+	Counts  Counts   `json:"counts"`
+}
+
+// References returns the image and its aliases without duplicates
+func (r *Report) References() []string {
+	seen := map[string]bool{}
+	refs := []string{}
+	for _, ref := range append([]string{r.Image}, r.Aliases...) {
+		if ref != "" && !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+type trivyResult struct {
+	Target          string `json:"Target"`
+	Vulnerabilities []struct {
+		VulnerabilityID string `json:"VulnerabilityID"`
+		PkgName         string `json:"PkgName"`
+		Severity        string `json:"Severity"`
+	} `json:"Vulnerabilities"`
+}
+
+type trivyReport struct {
+	SchemaVersion int    `json:"SchemaVersion"`
+	ArtifactName  string `json:"ArtifactName"`
+	Metadata      struct {
+		RepoTags    []string `json:"RepoTags"`
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []trivyResult `json:"Results"`
+}
+
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Source struct {
+		Type   string `json:"type"`
+		Target struct {
+			UserInput   string   `json:"userInput"`
+			Tags        []string `json:"tags"`
+			RepoDigests []string `json:"repoDigests"`
+		} `json:"target"`
+	} `json:"source"`
+}
+
+// Parse reads a Trivy or Grype JSON report. A vulnerability found several
+// times in the same package is counted once.
+func Parse(b []byte) (*Report, error) {
+	var probe interface{}
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return nil, err
+	}
+	switch v := probe.(type) {
+	case []interface{}:
+		// reports of Trivy before schema version 2 are a list of results
+		var results []trivyResult
+		if err := json.Unmarshal(b, &results); err != nil {
+			return nil, err
+		}
+		return parseTrivy(trivyReport{Results: results}), nil
+	case map[string]interface{}:
+		if _, ok := v["matches"]; ok {
+			var report grypeReport
+			if err := json.Unmarshal(b, &report); err != nil {
+				return nil, err
+			}
+			return parseGrype(report), nil
+		}
+		if _, ok := v["Results"]; ok {
+			var report trivyReport
+			if err := json.Unmarshal(b, &report); err != nil {
+				return nil, err
+			}
+			return parseTrivy(report), nil
+		}
+		if _, ok := v["SchemaVersion"]; ok {
+			// a Trivy report of an image without vulnerabilities
+			var report trivyReport
+			if err := json.Unmarshal(b, &report); err != nil {
+				return nil, err
+			}
+			return parseTrivy(report), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown scan report format, expected a Trivy or Grype JSON report")
+}
+
+func parseTrivy(report trivyReport) *Report {
+	r := &Report{Scanner: Trivy, Image: report.ArtifactName}
+	r.Aliases = append(r.Aliases, report.Metadata.RepoTags...)
+	r.Aliases = append(r.Aliases, report.Metadata.RepoDigests...)
+	seen := map[string]bool{}
+	for _, result := range report.Results {
+		if r.Image == "" {
+			// the target of the OS packages is "alpine:3.9 (alpine 3.9.6)"
+			r.Image = strings.TrimSpace(strings.SplitN(result.Target, " (", 2)[0])
+		}
+		for _, v := range result.Vulnerabilities {
+			key := v.VulnerabilityID + "/" + v.PkgName
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			r.Counts.add(v.Severity)
+		}
+	}
+	return r
+}
+
+func parseGrype(report grypeReport) *Report {
+	r := &Report{Scanner: Grype, Image: report.Source.Target.UserInput}
+	r.Aliases = append(r.Aliases, report.Source.Target.Tags...)
+	r.Aliases = append(r.Aliases, report.Source.Target.RepoDigests...)
+	seen := map[string]bool{}
+	for _, m := range report.Matches {
+		key := m.Vulnerability.ID + "/" + m.Artifact.Name + "@" + m.Artifact.Version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		r.Counts.add(m.Vulnerability.Severity)
+	}
+	return r
+}
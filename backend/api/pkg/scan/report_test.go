@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"testing"
+)
+
+const trivy = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "alpine:3.9",
+  "ArtifactType": "container_image",
+  "Metadata": {
+    "RepoTags": ["alpine:3.9"],
+    "RepoDigests": ["alpine@sha256:414e0518bb9228d35e4cd5165567fb91d26c6a214e9c95899e1e056fcd349011"]
+  },
+  "Results": [
+    {
+      "Target": "alpine:3.9 (alpine 3.9.6)",
+      "Vulnerabilities": [
+        {"VulnerabilityID": "CVE-2021-36159", "PkgName": "apk-tools", "Severity": "CRITICAL"},
+        {"VulnerabilityID": "CVE-2021-36159", "PkgName": "apk-tools", "Severity": "CRITICAL"},
+        {"VulnerabilityID": "CVE-2021-3711", "PkgName": "libcrypto1.1", "Severity": "CRITICAL"},
+        {"VulnerabilityID": "CVE-2021-3712", "PkgName": "libcrypto1.1", "Severity": "HIGH"},
+        {"VulnerabilityID": "CVE-2020-28928", "PkgName": "musl", "Severity": "MEDIUM"}
+      ]
+    }
+  ]
+}`
+
+const trivyV1 = `[
+  {
+    "Target": "ubuntu:18.04 (ubuntu 18.04)",
+    "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2019-18276", "PkgName": "bash", "Severity": "LOW"}
+    ]
+  }
+]`
+
+const grype = `{
+  "matches": [
+    {"vulnerability": {"id": "CVE-2021-36159", "severity": "Critical"}, "artifact": {"name": "apk-tools", "version": "2.10.6-r0"}},
+    {"vulnerability": {"id": "CVE-2020-28928", "severity": "Medium"}, "artifact": {"name": "musl", "version": "1.1.20-r5"}},
+    {"vulnerability": {"id": "CVE-2020-28928", "severity": "Medium"}, "artifact": {"name": "musl-utils", "version": "1.1.20-r5"}},
+    {"vulnerability": {"id": "CVE-2019-0000", "severity": "Negligible"}, "artifact": {"name": "busybox", "version": "1.29.3-r10"}}
+  ],
+  "source": {
+    "type": "image",
+    "target": {"userInput": "alpine:3.9", "tags": ["alpine:3.9"], "repoDigests": []}
+  },
+  "descriptor": {"name": "grype"}
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		report  string
+		scanner string
+		image   string
+		counts  Counts
+	}{
+		{"trivy", trivy, Trivy, "alpine:3.9", Counts{Critical: 2, High: 1, Medium: 1}},
+		{"trivy v1", trivyV1, Trivy, "ubuntu:18.04", Counts{Low: 1}},
+		{"grype", grype, Grype, "alpine:3.9", Counts{Critical: 1, Medium: 2, Low: 1}},
+	}
+	for _, tc := range tests {
+		r, err := Parse([]byte(tc.report))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if r.Scanner != tc.scanner || r.Image != tc.image || r.Counts != tc.counts {
+			t.Errorf("%s: unexpected report %+v", tc.name, r)
+		}
+	}
+	r, _ := Parse([]byte(trivy))
+	if refs := r.References(); len(refs) != 2 {
+		t.Errorf("expected the image and its digest, got %v", refs)
+	}
+	if _, err := Parse([]byte(`{"foo": "bar"}`)); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,230 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// VersionLabel is the label holding the version of a resource
+const VersionLabel = "app.kubernetes.io/version"
+
+// Directions of a PipelineResource
+const (
+	Input  = "input"
+	Output = "output"
+)
+
+// Param is a parameter accepted by a Task or Pipeline
+type Param struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	HasDefault  bool   `json:"has_default"`
+	Description string `json:"description,omitempty"`
+}
+
+// Workspace is a volume a Task or Pipeline expects to be provided
+type Workspace struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MountPath   string `json:"mount_path,omitempty"`
+	Optional    bool   `json:"optional"`
+}
+
+// Result is a value emitted by a Task or Pipeline
+type Result struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PipelineResource is an input or output PipelineResource
+type PipelineResource struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Direction string `json:"direction"`
+	Optional  bool   `json:"optional"`
+}
+
+// Container is a step or sidecar of a Task
+type Container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// Interface is what a consumer of a Task or Pipeline needs to know to use it
+type Interface struct {
+	Kind       string             `json:"kind"`
+	Name       string             `json:"name"`
+	APIVersion string             `json:"api_version"`
+	Version    string             `json:"version,omitempty"`
+	Params     []Param            `json:"params"`
+	Workspaces []Workspace        `json:"workspaces"`
+	Results    []Result           `json:"results"`
+	Resources  []PipelineResource `json:"resources"`
+	Steps      []Container        `json:"steps"`
+	Sidecars   []Container        `json:"sidecars"`
+	// StepTemplate is the container the steps of a Task inherit from
+	StepTemplate *Container `json:"step_template,omitempty"`
+	// Tasks are the names of the Tasks referenced by a Pipeline
+	Tasks []string `json:"tasks,omitempty"`
+}
+
+type document struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Params     []param     `json:"params"`
+		Workspaces []workspace `json:"workspaces"`
+		Results    []Result    `json:"results"`
+		// Resources is a list in a Pipeline and holds inputs and outputs
+		// in a v1beta1 Task
+		Resources json.RawMessage `json:"resources"`
+		Inputs    *struct {
+			Params    []param            `json:"params"`
+			Resources []PipelineResource `json:"resources"`
+		} `json:"inputs"`
+		Outputs *struct {
+			Resources []PipelineResource `json:"resources"`
+		} `json:"outputs"`
+		Steps        []Container `json:"steps"`
+		Sidecars     []Container `json:"sidecars"`
+		StepTemplate *Container  `json:"stepTemplate"`
+		Tasks        []struct {
+			TaskRef *struct {
+				Name string `json:"name"`
+			} `json:"taskRef"`
+		} `json:"tasks"`
+	} `json:"spec"`
+}
+
+type param struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Default     json.RawMessage `json:"default"`
+	Description string          `json:"description"`
+}
+
+// workspace is a workspace as written in Tekton, the mount path is camel cased
+type workspace struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MountPath   string `json:"mountPath"`
+	Optional    bool   `json:"optional"`
+}
+
+// Parse reads the interface of a Task or Pipeline written against any of
+// the Tekton apiVersions supported by the hub
+func Parse(content []byte) (*Interface, error) {
+	var doc document
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != "Task" && doc.Kind != "Pipeline" && doc.Kind != "ClusterTask" {
+		return nil, fmt.Errorf("unsupported kind %q", doc.Kind)
+	}
+	iface := &Interface{
+		Kind:       doc.Kind,
+		Name:       doc.Metadata.Name,
+		APIVersion: doc.APIVersion,
+		Version:    doc.Metadata.Labels[VersionLabel],
+		Params:     []Param{},
+		Workspaces: []Workspace{},
+		Results:    []Result{},
+		Resources:  []PipelineResource{},
+		Steps:      []Container{},
+		Sidecars:   []Container{},
+	}
+
+	params := doc.Spec.Params
+	if doc.Spec.Inputs != nil {
+		params = append(params, doc.Spec.Inputs.Params...)
+		iface.Resources = append(iface.Resources, withDirection(doc.Spec.Inputs.Resources, Input)...)
+	}
+	if doc.Spec.Outputs != nil {
+		iface.Resources = append(iface.Resources, withDirection(doc.Spec.Outputs.Resources, Output)...)
+	}
+	for _, p := range params {
+		iface.Params = append(iface.Params, newParam(p))
+	}
+	resources, err := parseResources(doc.Spec.Resources)
+	if err != nil {
+		return nil, err
+	}
+	iface.Resources = append(iface.Resources, resources...)
+
+	for _, w := range doc.Spec.Workspaces {
+		iface.Workspaces = append(iface.Workspaces, Workspace(w))
+	}
+	if doc.Spec.Results != nil {
+		iface.Results = doc.Spec.Results
+	}
+	if doc.Spec.Steps != nil {
+		iface.Steps = doc.Spec.Steps
+	}
+	if doc.Spec.Sidecars != nil {
+		iface.Sidecars = doc.Spec.Sidecars
+	}
+	iface.StepTemplate = doc.Spec.StepTemplate
+	for _, t := range doc.Spec.Tasks {
+		if t.TaskRef != nil && t.TaskRef.Name != "" {
+			iface.Tasks = append(iface.Tasks, t.TaskRef.Name)
+		}
+	}
+	return iface, nil
+}
+
+func newParam(p param) Param {
+	param := Param{Name: p.Name, Type: p.Type, Description: p.Description}
+	if param.Type == "" {
+		param.Type = "string"
+	}
+	if len(p.Default) > 0 && string(p.Default) != "null" {
+		param.HasDefault = true
+		// string defaults are kept as is, arrays and objects as JSON
+		var s string
+		if err := json.Unmarshal(p.Default, &s); err == nil {
+			param.Default = s
+		} else {
+			param.Default = string(p.Default)
+		}
+	}
+	return param
+}
+
+func withDirection(resources []PipelineResource, direction string) []PipelineResource {
+	for i := range resources {
+		resources[i].Direction = direction
+	}
+	return resources
+}
+
+// parseResources reads the resources of a Pipeline, a list, or of a
+// v1beta1 Task, inputs and outputs
+func parseResources(raw json.RawMessage) ([]PipelineResource, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var list []PipelineResource
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return withDirection(list, Input), nil
+	}
+	var task struct {
+		Inputs  []PipelineResource `json:"inputs"`
+		Outputs []PipelineResource `json:"outputs"`
+	}
+	if err := json.Unmarshal(raw, &task); err != nil {
+		return nil, err
+	}
+	return append(withDirection(task.Inputs, Input), withDirection(task.Outputs, Output)...), nil
+}
+
+// Required checks if a value must be provided for the param
+func (p Param) Required() bool {
+	return !p.HasDefault
+}
@@ -0,0 +1,136 @@
+package spec
+
+import (
+	"testing"
+)
+
+const v1alpha1Task = `apiVersion: tekton.dev/v1alpha1
+kind: Task
+metadata:
+  name: buildah
+spec:
+  inputs:
+    params:
+      - name: BUILDER_IMAGE
+        description: The location of the buildah builder image.
+        default: quay.io/buildah/stable:v1.11.0
+      - name: DOCKERFILE
+        default: ./Dockerfile
+    resources:
+      - name: source
+        type: git
+  outputs:
+    resources:
+      - name: image
+        type: image
+  steps:
+    - name: build
+      image: $(inputs.params.BUILDER_IMAGE)
+`
+
+const v1beta1Task = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: git-clone
+  labels:
+    app.kubernetes.io/version: "0.2"
+spec:
+  params:
+    - name: url
+      description: git url to clone
+    - name: args
+      type: array
+      default: ["--depth", "1"]
+  workspaces:
+    - name: output
+      mountPath: /workspace/output
+      optional: true
+  results:
+    - name: commit
+      description: The precise commit SHA
+  resources:
+    inputs:
+      - name: repo
+        type: git
+  stepTemplate:
+    image: alpine/git:v2.24.3
+  steps:
+    - name: clone
+  sidecars:
+    - name: docker
+      image: docker:dind
+`
+
+const pipeline = `apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: build-deploy
+spec:
+  resources:
+    - name: repo
+      type: git
+  tasks:
+    - name: build
+      taskRef:
+        name: buildah
+`
+
+func TestParseV1alpha1(t *testing.T) {
+	iface, err := Parse([]byte(v1alpha1Task))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(iface.Params) != 2 || iface.Params[1].Name != "DOCKERFILE" || iface.Params[1].Default != "./Dockerfile" || iface.Params[1].Type != "string" {
+		t.Errorf("unexpected params %+v", iface.Params)
+	}
+	if len(iface.Resources) != 2 || iface.Resources[0].Direction != Input || iface.Resources[1].Direction != Output {
+		t.Errorf("unexpected resources %+v", iface.Resources)
+	}
+	if len(iface.Steps) != 1 || iface.Steps[0].Image != "$(inputs.params.BUILDER_IMAGE)" {
+		t.Errorf("unexpected steps %+v", iface.Steps)
+	}
+}
+
+func TestParseV1beta1(t *testing.T) {
+	iface, err := Parse([]byte(v1beta1Task))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iface.Version != "0.2" || iface.APIVersion != "tekton.dev/v1beta1" {
+		t.Errorf("unexpected version %q %q", iface.Version, iface.APIVersion)
+	}
+	if !iface.Params[0].Required() || iface.Params[1].Required() || iface.Params[1].Default != `["--depth","1"]` {
+		t.Errorf("unexpected params %+v", iface.Params)
+	}
+	if len(iface.Workspaces) != 1 || !iface.Workspaces[0].Optional || iface.Workspaces[0].MountPath != "/workspace/output" {
+		t.Errorf("unexpected workspaces %+v", iface.Workspaces)
+	}
+	if len(iface.Results) != 1 || iface.Results[0].Name != "commit" {
+		t.Errorf("unexpected results %+v", iface.Results)
+	}
+	if len(iface.Resources) != 1 || iface.Resources[0].Name != "repo" {
+		t.Errorf("unexpected resources %+v", iface.Resources)
+	}
+	if len(iface.Sidecars) != 1 || iface.Sidecars[0].Image != "docker:dind" {
+		t.Errorf("unexpected sidecars %+v", iface.Sidecars)
+	}
+	if iface.StepTemplate == nil || iface.StepTemplate.Image != "alpine/git:v2.24.3" {
+		t.Errorf("unexpected step template %+v", iface.StepTemplate)
+	}
+}
+
+func TestParsePipeline(t *testing.T) {
+	iface, err := Parse([]byte(pipeline))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(iface.Resources) != 1 || iface.Resources[0].Type != "git" {
+		t.Errorf("unexpected resources %+v", iface.Resources)
+	}
+	if len(iface.Tasks) != 1 || iface.Tasks[0] != "buildah" {
+		t.Errorf("unexpected tasks %v", iface.Tasks)
+	}
+	if _, err := Parse([]byte("kind: ConfigMap\n")); err == nil {
+		t.Error("expected an error for an unsupported kind")
+	}
+}
@@ -0,0 +1,211 @@
+package upload
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/image"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/oci"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/spec"
+)
+
+// BundleImportRequest registers a task or pipeline from a Tekton bundle
+type BundleImportRequest struct {
+	// Reference is the bundle, by tag or digest, e.g.
+	// registry.example.com/tekton/git-clone:0.2
+	Reference string `json:"reference"`
+	// Name selects the object of a bundle holding several of them
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// bundleObject is a task or pipeline pulled from a bundle
+type bundleObject struct {
+	oci.Object
+	// Reference is the bundle reference pinned to the digest
+	Reference string
+	Digest    string
+}
+
+// pullBundle pulls a bundle from the configured registry and returns the
+// task or pipeline it holds, checked against its annotations
+func (u *Uploader) pullBundle(reference string, name string) (*bundleObject, error) {
+	if u.registry == nil {
+		return nil, apierror.New(apierror.BadRequest, "Bundles cannot be imported, no registry is configured")
+	}
+	ref, err := image.Parse(reference)
+	if err != nil {
+		return nil, apierror.New(apierror.BadRequest, "Invalid bundle reference %q", reference)
+	}
+	if ref.Registry != u.registry.Host() {
+		return nil, apierror.New(apierror.BadRequest, "Bundles can only be imported from %s", u.registry.Host())
+	}
+	// the digest pins the bundle when both a tag and a digest are given
+	tag := ref.Digest
+	if tag == "" {
+		tag = ref.Tag
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+	bundle, err := u.registry.Pull(ref.Repository, tag)
+	if err != nil {
+		log.Println(err)
+		return nil, apierror.New(apierror.Upstream, "Unable to pull the bundle %s: %v", reference, err)
+	}
+	objects, err := bundle.Objects()
+	if err != nil {
+		return nil, apierror.New(apierror.ValidationFailed, "Invalid bundle: %v", err)
+	}
+	selected := []oci.Object{}
+	for _, object := range objects {
+		if (object.Kind == "task" || object.Kind == "pipeline") && (name == "" || object.Name == name) {
+			selected = append(selected, object)
+		}
+	}
+	switch {
+	case len(selected) == 0 && name != "":
+		return nil, apierror.New(apierror.NotFound, "The bundle holds no task or pipeline named %q", name)
+	case len(selected) == 0:
+		return nil, apierror.New(apierror.ValidationFailed, "The bundle holds no task or pipeline")
+	case len(selected) > 1:
+		return nil, apierror.New(apierror.BadRequest, "The bundle holds several tasks or pipelines, give the name of the one to import")
+	}
+	object := selected[0]
+	content, err := yaml.YAMLToJSON(object.Content)
+	if err != nil {
+		return nil, apierror.New(apierror.ValidationFailed, "Invalid YAML format")
+	}
+	var parsed resourceObject
+	if err := json.Unmarshal(content, &parsed); err != nil {
+		return nil, apierror.New(apierror.ValidationFailed, "Invalid YAML format")
+	}
+	if strings.ToLower(parsed.Kind) != object.Kind || parsed.Metadata.Name != object.Name {
+		return nil, apierror.New(apierror.ValidationFailed, "The bundle layer of %s %s holds %s %s", object.Kind, object.Name, parsed.Kind, parsed.Metadata.Name)
+	}
+	return &bundleObject{
+		Object:    object,
+		Reference: ref.Name() + "@" + bundle.Digest,
+		Digest:    bundle.Digest,
+	}, nil
+}
+
+// ImportBundle registers the task or pipeline of a bundle, or adds it as a
+// new version of the resource of the user with the same name. The digest of
+// the bundle is recorded with the version, a version label already imported
+// from another digest is rejected as versions are immutable
+func (u *Uploader) ImportBundle(req BundleImportRequest, userID int) (map[string]interface{}, error) {
+	object, err := u.pullBundle(req.Reference, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	content := string(object.Content)
+	resourceID, err := models.GetResourceIDByNameAndType(object.Name, object.Kind)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	exists := err == nil
+	if exists && !models.IsResourceOwner(userID, resourceID) {
+		return nil, apierror.New(apierror.Conflict, "%s %s already exists", object.Kind, object.Name)
+	}
+	iface, err := spec.Parse(object.Content)
+	if err != nil {
+		return nil, apierror.New(apierror.ValidationFailed, "Invalid YAML format")
+	}
+
+	var previous *models.ResourceVersion
+	if exists {
+		byDigest, err := optionalVersion(models.GetResourceVersionByDigest(resourceID, object.Digest))
+		if err != nil {
+			return nil, err
+		}
+		var byLabel *models.ResourceVersion
+		if iface.Version != "" {
+			if byLabel, err = optionalVersion(models.GetResourceVersion(resourceID, iface.Version)); err != nil {
+				return nil, err
+			}
+		}
+		imported, err := importedVersion(iface.Version, byDigest, byLabel)
+		if err != nil {
+			return nil, err
+		}
+		if imported != nil {
+			return map[string]interface{}{"status": true, "message": "Bundle already imported", "resource_id": resourceID, "version_id": imported.ID, "digest": imported.Digest}, nil
+		}
+		latest, err := models.GetLatestResourceVersion(resourceID)
+		if err == nil {
+			previous = &latest
+		} else if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+	validationResponse := u.validation(&content, object.Name, object.Kind, previous)
+	if validationResponse.Status == false {
+		return nil, validationResponse.apiError()
+	}
+
+	if !exists {
+		resource := models.Resource{
+			Name:        object.Name,
+			Description: req.Description,
+			Tags:        req.Tags,
+			Type:        object.Kind,
+			APIVersion:  iface.APIVersion,
+			Quality:     validationResponse.Score,
+		}
+		resourceID, err = models.AddResource(&resource, userID, "", "", "")
+		if err != nil {
+			log.Println(err)
+			return nil, err
+		}
+	}
+	version := models.ResourceVersion{
+		ResourceID: resourceID,
+		Version:    iface.Version,
+		APIVersion: iface.APIVersion,
+		RawPath:    object.Reference,
+		Digest:     object.Digest,
+		Content:    content,
+	}
+	versionID, err := models.AddResourceVersion(&version, iface)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if exists {
+		models.UpdateResourceValidation(resourceID, iface.APIVersion, validationResponse.Score)
+	}
+	return map[string]interface{}{"status": true, "message": "Import Successfull", "resource_id": resourceID, "version_id": versionID, "digest": object.Digest, "diagnostics": validationResponse.Diagnostics, "quality": validationResponse.Score}, nil
+}
+
+// importedVersion applies the rules of the versions imported from bundles,
+// which cannot change: the version already imported from the digest of a
+// bundle is returned, and a label imported from another digest is a
+// conflict. byDigest and byLabel are nil when there is no such version
+func importedVersion(label string, byDigest, byLabel *models.ResourceVersion) (*models.ResourceVersion, error) {
+	if byDigest != nil {
+		return byDigest, nil
+	}
+	if byLabel != nil && byLabel.Digest != "" {
+		return nil, apierror.New(apierror.Conflict, "Version %s was imported from %s, versions cannot change", label, byLabel.Digest)
+	}
+	return nil, nil
+}
+
+// optionalVersion returns nil rather than sql.ErrNoRows for a missing
+// version
+func optionalVersion(v models.ResourceVersion, err error) (*models.ResourceVersion, error) {
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
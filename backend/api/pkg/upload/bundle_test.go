@@ -0,0 +1,125 @@
+package upload
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/oci"
+)
+
+const gitClone = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: git-clone
+  labels:
+    app.kubernetes.io/version: "0.2"
+spec:
+  steps:
+  - name: clone
+    image: alpine
+`
+
+// registry is a local stand-in for the registry bundles are imported from
+type registry map[string][]oci.Tag
+
+func (r registry) Tags(repository string) ([]oci.Tag, error) {
+	tags, ok := r[repository]
+	if !ok {
+		return nil, oci.ErrNameUnknown
+	}
+	return tags, nil
+}
+
+func (r registry) Pulled(http.ResponseWriter, *http.Request, string, oci.Tag) {}
+
+func TestPullBundle(t *testing.T) {
+	task := oci.Object{APIVersion: "v1beta1", Kind: "task", Name: "git-clone", Content: []byte(gitClone)}
+	single, err := oci.NewBundle(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mislabelled, _ := oci.NewBundle(oci.Object{APIVersion: "v1beta1", Kind: "task", Name: "git-fetch", Content: []byte(gitClone)})
+	several, _ := oci.NewBundle(task, oci.Object{APIVersion: "v1beta1", Kind: "task", Name: "git-fetch", Content: []byte(gitClone)})
+	r := mux.NewRouter()
+	oci.NewRegistry(registry{"tekton/git-clone": {
+		{Name: "0.2", Image: single},
+		{Name: "mislabelled", Image: mislabelled},
+		{Name: "several", Image: several},
+	}}).Register(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	u := &Uploader{registry: oci.NewClient(server.URL)}
+	host := u.registry.Host()
+
+	for _, reference := range []string{host + "/tekton/git-clone:0.2", host + "/tekton/git-clone@" + single.Digest} {
+		object, err := u.pullBundle(reference, "")
+		if err != nil {
+			t.Fatalf("%s: %v", reference, err)
+		}
+		if object.Digest != single.Digest || object.Reference != host+"/tekton/git-clone@"+single.Digest {
+			t.Errorf("%s: expected the digest %s, got %s %s", reference, single.Digest, object.Digest, object.Reference)
+		}
+		if object.Kind != "task" || object.Name != "git-clone" || string(object.Content) != gitClone {
+			t.Errorf("%s: unexpected object %+v", reference, object.Object)
+		}
+	}
+	if object, err := u.pullBundle(host+"/tekton/git-clone:several", "git-clone"); err != nil || object.Name != "git-clone" {
+		t.Errorf("expected git-clone to be selected, got %v %v", object, err)
+	}
+
+	tests := []struct {
+		reference, name, code, message string
+	}{
+		{"registry.example.com/tekton/git-clone:0.2", "", apierror.BadRequest, "can only be imported from"},
+		{host + "/tekton/git-clone:0.3", "", apierror.Upstream, "MANIFEST_UNKNOWN"},
+		{host + "/tekton/unknown:0.2", "", apierror.Upstream, "NAME_UNKNOWN"},
+		{host + "/tekton/git-clone:mislabelled", "", apierror.ValidationFailed, "holds Task git-clone"},
+		{host + "/tekton/git-clone:several", "", apierror.BadRequest, "several"},
+		{host + "/tekton/git-clone:0.2", "git-fetch", apierror.NotFound, "no task or pipeline named"},
+	}
+	for _, tc := range tests {
+		_, err := u.pullBundle(tc.reference, tc.name)
+		apiErr, ok := err.(*apierror.Error)
+		if !ok || apiErr.Code != tc.code || !strings.Contains(apiErr.Message, tc.message) {
+			t.Errorf("%s: expected %s %q, got %v", tc.reference, tc.code, tc.message, err)
+		}
+	}
+}
+
+func TestImportedVersion(t *testing.T) {
+	imported := &models.ResourceVersion{ID: 3, Version: "0.2", Digest: "sha256:" + strings.Repeat("a", 64)}
+	synced := &models.ResourceVersion{ID: 2, Version: "0.2"}
+	tests := []struct {
+		name              string
+		byDigest, byLabel *models.ResourceVersion
+		expected          *models.ResourceVersion
+		code              string
+	}{
+		{"new version", nil, nil, nil, ""},
+		// the same bundle imported again returns its version
+		{"same digest", imported, imported, imported, ""},
+		{"same label from another digest", nil, imported, nil, apierror.Conflict},
+		// versions synced from GitHub have no digest, a bundle may replace them
+		{"same label as a synced version", nil, synced, nil, ""},
+	}
+	for _, tc := range tests {
+		v, err := importedVersion("0.2", tc.byDigest, tc.byLabel)
+		if v != tc.expected {
+			t.Errorf("%s: expected the version %v, got %v", tc.name, tc.expected, v)
+		}
+		code := ""
+		if apiErr, ok := err.(*apierror.Error); ok {
+			code = apiErr.Code
+		} else if err != nil {
+			code = err.Error()
+		}
+		if code != tc.code {
+			t.Errorf("%s: expected %q, got %v", tc.name, tc.code, err)
+		}
+	}
+}
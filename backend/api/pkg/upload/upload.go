@@ -1,7 +1,6 @@
 package upload
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -13,14 +12,22 @@ import (
 
 	"github.com/ghodss/yaml"
 	"github.com/google/go-github/github"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/apierror"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/app"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/bundle"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/diff"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/models"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/oci"
 	"github.com/redhat-developer/tekton-hub/backend/api/pkg/polling"
-	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/redhat-developer/tekton-hub/backend/api/pkg/spec"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/diagnostic"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/policy"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/validator"
 	"golang.org/x/oauth2"
 )
 
-// NewUploadRequestObject represents new task/pipelines
+// NewUploadRequestObject represents new task/pipelines, they are uploaded
+// for the user of the token and UserID is optional
 type NewUploadRequestObject struct {
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
@@ -47,15 +54,51 @@ type CodeResult struct {
 	TextMatches interface{} `json:"text_matches,omitempty"`
 }
 
+// resourceObject holds the fields of a Task or Pipeline that upload needs,
+// independent of the Tekton apiVersion it was written against
+type resourceObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Tasks []struct {
+			TaskRef struct {
+				Name string `json:"name"`
+			} `json:"taskRef"`
+		} `json:"tasks"`
+	} `json:"spec"`
+}
+
 type Uploader struct {
-	app app.Config
-	gh  *github.Client
+	app       app.Config
+	gh        *github.Client
+	validator *validator.Validator
+	// semver is the level of the compatibility check of new versions
+	semver policy.Level
+	// registry is the registry bundles are imported from, nil if none is
+	// configured
+	registry *oci.Client
 }
 
 func New(app app.Config) *Uploader {
+	// BUNDLE_REGISTRY is the URL of the registry bundles are imported
+	// from, e.g. https://registry.example.com, BUNDLE_REGISTRY_TOKEN its
+	// bearer token if it requires one, or the password of
+	// BUNDLE_REGISTRY_USERNAME for its token service
+	var registry *oci.Client
+	if url := os.Getenv("BUNDLE_REGISTRY"); url != "" {
+		registry = oci.NewClient(url,
+			oci.WithUsername(os.Getenv("BUNDLE_REGISTRY_USERNAME")),
+			oci.WithToken(os.Getenv("BUNDLE_REGISTRY_TOKEN")))
+	}
 	return &Uploader{
-		app: app,
-		gh:  app.GitHub().Client,
+		app:       app,
+		gh:        app.GitHub().Client,
+		validator: app.Validation().Validator,
+		semver:    app.Validation().Semver,
+		registry:  registry,
 	}
 }
 
@@ -84,10 +127,10 @@ func (u *Uploader) getLatestCommit(owner string, repositoryName string) string {
 }
 
 // NewUpload handles uploading of new task/pipeline
-func (u *Uploader) NewUpload(name, description, objectType string, tags []string, github string, userID int) interface{} {
+func (u *Uploader) NewUpload(name, description, objectType string, tags []string, github string, userID int) (map[string]interface{}, error) {
 	isSameResource := models.CheckSameResourceUpload(userID, name)
 	if isSameResource {
-		return map[string]interface{}{"status": false, "message": objectType + " already exists"}
+		return nil, apierror.New(apierror.Conflict, "%s already exists", objectType)
 	}
 	// Get owner and repository name from github link
 	owner, repositoryName := u.GetGithubOwner(github)
@@ -95,41 +138,41 @@ func (u *Uploader) NewUpload(name, description, objectType string, tags []string
 	paths, err := u.search(owner, repositoryName, objectType, name, userID)
 	if err != nil {
 		log.Println(err)
-		return map[string]interface{}{"status": false, "message": "The listed users and repositories cannot be searched either because the resources do not exist or you do not have permission to view them."}
+		return nil, apierror.New(apierror.NotFound, "The listed users and repositories cannot be searched either because the resources do not exist or you do not have permission to view them.")
 	}
 	// Check for field name and kind
 	var content *string
 	// var SHA string
 	isTaskPresent := false
-	var resourcePath string
+	var resourcePath, apiVersion string
 	for _, path := range paths {
 		content = u.getObjectContent(path, owner, repositoryName)
 		taskJSON, err := yaml.YAMLToJSON([]byte(*content))
 		if err != nil {
 			log.Println(err)
-			return map[string]interface{}{"status": false, "message": "Invalid YAML format"}
+			return nil, apierror.New(apierror.ValidationFailed, "Invalid YAML format")
 		}
-		var objmap map[string]interface{}
-		if err := json.Unmarshal(taskJSON, &objmap); err != nil {
+		var object resourceObject
+		if err := json.Unmarshal(taskJSON, &object); err != nil {
 			log.Println(err)
-			return map[string]interface{}{"status": false, "message": "Invalid YAML format"}
+			return nil, apierror.New(apierror.ValidationFailed, "Invalid YAML format")
 		}
-		nameMap := objmap["metadata"].(map[string]interface{})
 		// Change here for pipeline
-		if objmap["kind"] == "Task" && nameMap["name"] == name {
+		if object.Kind == "Task" && object.Metadata.Name == name {
 			isTaskPresent = true
 			resourcePath = path
+			apiVersion = object.APIVersion
 			break
 		}
 	}
 	if isTaskPresent == false {
-		return map[string]interface{}{"status": false, "message": "Task with the given name doesn't exist"}
+		return nil, apierror.New(apierror.NotFound, "Task with the given name doesn't exist")
 	}
 	// Perform lint validation and schema validation here
-	validationResponse := u.validation(content, name, objectType)
+	validationResponse := u.validation(content, name, objectType, nil)
 	log.Println(validationResponse.Status, validationResponse.Message)
 	if validationResponse.Status == false {
-		return map[string]interface{}{"status": validationResponse.Status, "message": validationResponse.Message}
+		return nil, validationResponse.apiError()
 	}
 	// Add Task details to DB
 	resource := models.Resource{
@@ -138,18 +181,24 @@ func (u *Uploader) NewUpload(name, description, objectType string, tags []string
 		Description: description,
 		Tags:        tags,
 		Type:        objectType,
+		APIVersion:  apiVersion,
+		Quality:     validationResponse.Score,
 	}
 	rawResourcePath := fmt.Sprintf("https://raw.githubusercontent.com/%v/%v/%v/%v", owner, repositoryName, "master", resourcePath)
 	resourceID, err := models.AddResource(&resource, userID, owner, repositoryName, resourcePath)
 	if err != nil {
 		log.Println(err)
-		return map[string]interface{}{"status": false, "message": err}
+		return nil, err
 	}
 
 	// Add a raw path
 	models.AddResourceRawPath(rawResourcePath, resourceID, objectType)
 
-	return map[string]interface{}{"status": true, "message": "Upload Successfull"}
+	if _, _, err := u.addVersion(resourceID, rawResourcePath, content); err != nil {
+		log.Println(err)
+	}
+
+	return map[string]interface{}{"status": true, "message": "Upload Successfull", "resource_id": resourceID, "diagnostics": validationResponse.Diagnostics, "quality": validationResponse.Score}, nil
 }
 
 func (u *Uploader) doesResourceExist(paths []string, owner string, repositoryName string, resourceName string, objectType string) (bool, string, *string) {
@@ -158,13 +207,13 @@ func (u *Uploader) doesResourceExist(paths []string, owner string, repositoryNam
 	var content *string
 	for _, path := range paths {
 		content = u.getObjectContent(path, owner, repositoryName)
-		var pipeline v1alpha1.Pipeline
-		err := yaml.Unmarshal([]byte(*content), &pipeline)
+		var object resourceObject
+		err := yaml.Unmarshal([]byte(*content), &object)
 		if err != nil {
 			log.Println("Invalid Resource schema")
 			return false, "", nil
 		}
-		if strings.ToLower(pipeline.TypeMeta.Kind) == objectType && pipeline.ObjectMeta.Name == resourceName {
+		if strings.ToLower(object.Kind) == objectType && object.Metadata.Name == resourceName {
 			isResourcePresent = true
 			resourcePath = path
 			break
@@ -174,11 +223,11 @@ func (u *Uploader) doesResourceExist(paths []string, owner string, repositoryNam
 }
 
 // NewUploadPipeline handles uploading of new task/pipeline
-func (u *Uploader) NewUploadPipeline(name string, description string, objectType string, tags []string, github string, userID int) interface{} {
+func (u *Uploader) NewUploadPipeline(name string, description string, objectType string, tags []string, github string, userID int) (map[string]interface{}, error) {
 	log.Println(objectType)
 	isSameResource := models.CheckSameResourceUpload(userID, name)
 	if isSameResource {
-		return map[string]interface{}{"status": false, "message": objectType + " already exists"}
+		return nil, apierror.New(apierror.Conflict, "%s already exists", objectType)
 	}
 	// Get owner and repository name from github link
 	owner, repositoryName := u.GetGithubOwner(github)
@@ -186,7 +235,7 @@ func (u *Uploader) NewUploadPipeline(name string, description string, objectType
 	paths, err := u.search(owner, repositoryName, objectType, name, userID)
 	if err != nil {
 		log.Println("Invalid owner and repository name")
-		return map[string]interface{}{"status": false, "message": "The listed users and repositories cannot be searched either because the resources do not exist or you do not have permission to view them."}
+		return nil, apierror.New(apierror.NotFound, "The listed users and repositories cannot be searched either because the resources do not exist or you do not have permission to view them.")
 	}
 	// Check for field name and kind
 	var content *string
@@ -196,17 +245,17 @@ func (u *Uploader) NewUploadPipeline(name string, description string, objectType
 	// Check if the resource exists
 	isPipelinePresent, resourcePath, content = u.doesResourceExist(paths, owner, repositoryName, name, objectType)
 	if isPipelinePresent == false && content == nil {
-		return map[string]interface{}{"status": false, "message": "Invalid Pipeline schema"}
+		return nil, apierror.New(apierror.ValidationFailed, "Invalid Pipeline schema")
 	}
 	if isPipelinePresent == false {
-		return map[string]interface{}{"status": false, "message": name + ": Pipeline with the given name doesn't exist"}
+		return nil, apierror.New(apierror.NotFound, "%s: Pipeline with the given name doesn't exist", name)
 	}
 	log.Println(resourcePath)
-	var pipeline v1alpha1.Pipeline
+	var pipeline resourceObject
 	err = yaml.Unmarshal([]byte(*content), &pipeline)
 	if err != nil {
 		fmt.Println("Invalid Pipeline schema")
-		return err
+		return nil, apierror.New(apierror.ValidationFailed, "Invalid Pipeline schema")
 	}
 	var rawTaskPaths []string
 	for _, pipelineTask := range pipeline.Spec.Tasks {
@@ -214,25 +263,25 @@ func (u *Uploader) NewUploadPipeline(name string, description string, objectType
 		paths, err := u.search(owner, repositoryName, "Task", pipelineTask.TaskRef.Name, userID)
 		if err != nil {
 			fmt.Println("Invalid")
-			return map[string]interface{}{"status": false, "message": pipelineTask.TaskRef.Name + ": Task with the given name doesn't exist"}
+			return nil, apierror.New(apierror.NotFound, "%s: Task with the given name doesn't exist", pipelineTask.TaskRef.Name)
 		}
 		isTaskPresent := false
 		var taskPath string
 		isTaskPresent, taskPath, _ = u.doesResourceExist(paths, owner, repositoryName, pipelineTask.TaskRef.Name, "task")
 		if isTaskPresent == false {
-			return map[string]interface{}{"status": false, "message": pipelineTask.TaskRef.Name + ": Task with the 	given name doesn't exist"}
+			return nil, apierror.New(apierror.NotFound, "%s: Task with the given name doesn't exist", pipelineTask.TaskRef.Name)
 		} else if isTaskPresent == false && taskPath == "" {
-			return map[string]interface{}{"status": false, "message": "Invalid Task schema"}
+			return nil, apierror.New(apierror.ValidationFailed, "Invalid Task schema")
 		}
 		rawTaskPath := fmt.Sprintf("https://raw.githubusercontent.com/%v/%v/%v/%v", owner, repositoryName, "master", taskPath)
 		rawTaskPaths = append(rawTaskPaths, rawTaskPath)
 	}
 	log.Println(rawTaskPaths)
 	// Perform lint validation and schema validation here
-	validationResponse := u.validation(content, name, objectType)
+	validationResponse := u.validation(content, name, objectType, nil)
 	log.Println(validationResponse.Status, validationResponse.Message)
 	if validationResponse.Status == false {
-		return map[string]interface{}{"status": validationResponse.Status, "message": validationResponse.Message}
+		return nil, validationResponse.apiError()
 	}
 	// Add Pipeline details to DB
 	resource := models.Resource{
@@ -241,12 +290,14 @@ func (u *Uploader) NewUploadPipeline(name string, description string, objectType
 		Description: description,
 		Tags:        tags,
 		Type:        objectType,
+		APIVersion:  pipeline.APIVersion,
+		Quality:     validationResponse.Score,
 	}
 	rawResourcePath := fmt.Sprintf("https://raw.githubusercontent.com/%v/%v/%v/%v", owner, repositoryName, "master", resourcePath)
 	resourceID, err := models.AddResource(&resource, userID, owner, repositoryName, resourcePath)
 	if err != nil {
 		log.Println(err)
-		return map[string]interface{}{"status": false, "message": err}
+		return nil, err
 	}
 
 	// Add a raw path for resource
@@ -256,33 +307,156 @@ func (u *Uploader) NewUploadPipeline(name string, description string, objectType
 	for _, rawPath := range rawTaskPaths {
 		models.AddResourceRawPath(rawPath, resourceID, "task")
 	}
-	return map[string]interface{}{"status": true, "message": "Upload Successfull"}
+
+	if _, _, err := u.addVersion(resourceID, rawResourcePath, content); err != nil {
+		log.Println(err)
+	}
+	return map[string]interface{}{"status": true, "message": "Upload Successfull", "resource_id": resourceID, "diagnostics": validationResponse.Diagnostics, "quality": validationResponse.Score}, nil
 }
 
-// ValidationResponse represents response from validation service
+// ValidationResponse represents the result of validating a resource
 type ValidationResponse struct {
-	Status  bool   `json:"status"`
-	Message string `json:"message"`
+	Status      bool            `json:"status"`
+	Message     string          `json:"message"`
+	Diagnostics diagnostic.List `json:"diagnostics"`
+	Score       int             `json:"score"`
 }
 
-func (u *Uploader) validation(content *string, name string, objectType string) ValidationResponse {
-	url := os.Getenv("VALIDATION_API")
-	url = fmt.Sprintf(url+"/validate/%v/%v", objectType, name)
-	log.Println(url)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer([]byte(*content)))
-	req.Header.Set("Content-Type", "application/text")
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// validation checks the content of a resource. A new version of an existing
+// resource is also checked for compatibility with the previous version.
+func (u *Uploader) validation(content *string, name string, objectType string, previous *models.ResourceVersion) ValidationResponse {
+	result := u.validator.Validate(objectType, []byte(*content))
+	if previous != nil && u.semver != policy.Disable {
+		result.Diagnostics = append(result.Diagnostics, u.compatibility(previous, content)...)
+		result.Diagnostics.Sort()
+	}
+	if !result.Valid() {
+		return ValidationResponse{false, result.Diagnostics.String(), result.Diagnostics, result.Score}
+	}
+	return ValidationResponse{true, "Success", result.Diagnostics, result.Score}
+}
+
+// apiError returns the error of a failed validation with its diagnostics
+func (v ValidationResponse) apiError() *apierror.Error {
+	return apierror.New(apierror.ValidationFailed, "%s", v.Message).WithDetails(v.Diagnostics)
+}
+
+// compatibility compares the interface of a new version to the previous
+// version, see diff.CheckVersion
+func (u *Uploader) compatibility(previous *models.ResourceVersion, content *string) diagnostic.List {
+	from, err := spec.Parse([]byte(previous.Content))
 	if err != nil {
 		log.Println(err)
+		return nil
 	}
-	defer resp.Body.Close()
-	validationResponse := ValidationResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&validationResponse)
+	// an invalid resource is already reported by the validator
+	to, err := spec.Parse([]byte(*content))
+	if err != nil {
+		return nil
+	}
+	diagnostics := diff.CheckVersion(from.Version, to.Version, diff.Compare(from, to), []byte(*content))
+	if u.semver == policy.Warning {
+		for i := range diagnostics {
+			diagnostics[i].Severity = diagnostic.Warning
+		}
+	}
+	return diagnostics
+}
+
+// addVersion records the content of a resource as a new version, indexes
+// its interface and returns the id of the version
+func (u *Uploader) addVersion(resourceID int, rawPath string, content *string) (*spec.Interface, int, error) {
+	iface, err := spec.Parse([]byte(*content))
+	if err != nil {
+		return nil, 0, err
+	}
+	version := models.ResourceVersion{
+		ResourceID: resourceID,
+		Version:    iface.Version,
+		APIVersion: iface.APIVersion,
+		RawPath:    rawPath,
+		Content:    *content,
+	}
+	versionID, err := models.AddResourceVersion(&version, iface)
+	return iface, versionID, err
+}
+
+// Sync fetches the YAML of a resource from GitHub again and records it as
+// a new version if it changed since the last upload or sync
+func (u *Uploader) Sync(resourceID int) (map[string]interface{}, error) {
+	resource := models.GetResourceByID(resourceID)
+	if resource.ID == 0 {
+		return nil, apierror.New(apierror.NotFound, "Resource doesn't exist")
+	}
+	links := models.GetResourceRawLinks(resourceID)
+	rawPaths := links.Tasks
+	if resource.Type == "pipeline" {
+		rawPaths = links.Pipelines
+	}
+	if len(rawPaths) == 0 {
+		return nil, apierror.New(apierror.NotFound, "Resource has no YAML file")
+	}
+	rawPath := strings.TrimSpace(rawPaths[0])
+	owner, repositoryName, ref, path, err := bundle.ParseRawPath(rawPath)
+	if err != nil {
+		log.Println(err)
+		return nil, apierror.New(apierror.Internal, "Invalid YAML file path")
+	}
+	desc, err := polling.GetFileContent(context.Background(), u.gh, owner, repositoryName, path, &github.RepositoryContentGetOptions{Ref: ref})
 	if err != nil {
 		log.Println(err)
+		return nil, apierror.New(apierror.Upstream, "Unable to fetch the YAML file from GitHub")
+	}
+	content, err := desc.GetContent()
+	if err != nil {
+		log.Println(err)
+		return nil, apierror.New(apierror.Upstream, "Unable to fetch the YAML file from GitHub")
+	}
+	latest, err := models.GetLatestResourceVersion(resourceID)
+	if err == nil && latest.Content == content {
+		return map[string]interface{}{"status": true, "message": "Resource is up to date"}, nil
+	}
+
+	var previous *models.ResourceVersion
+	if err == nil {
+		previous = &latest
+	}
+	validationResponse := u.validation(&content, resource.Name, resource.Type, previous)
+	if validationResponse.Status == false {
+		return nil, validationResponse.apiError()
+	}
+	iface, versionID, err := u.addVersion(resourceID, rawPath, &content)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	models.UpdateResourceValidation(resourceID, iface.APIVersion, validationResponse.Score)
+	return map[string]interface{}{"status": true, "message": "Sync Successfull", "version_id": versionID, "diagnostics": validationResponse.Diagnostics, "quality": validationResponse.Score}, nil
+}
+
+// SyncMissingVersions syncs the resources which have no stored version so
+// that the features reading the versions cover the whole catalog. Only one
+// replica runs it, it returns the number of resources synced
+func (u *Uploader) SyncMissingVersions() (int, error) {
+	synced := 0
+	var err error
+	_, lockErr := models.WithLock(models.VersionBackfillLock, func() {
+		var ids []int
+		if ids, err = models.GetResourceIDsWithoutVersion(); err != nil {
+			return
+		}
+		for _, id := range ids {
+			if _, syncErr := u.Sync(id); syncErr != nil {
+				log.Printf("resource %d: %v", id, syncErr)
+				continue
+			}
+			synced++
+		}
+	})
+	if lockErr != nil {
+		return 0, lockErr
 	}
-	return validationResponse
+	return synced, err
 }
 
 func (u *Uploader) createTaskFiles(taskID int, name string, content *string) {
@@ -2,19 +2,11 @@ module github.com/redhat-developer/tekton-hub/backend/validation
 
 require (
 	github.com/ghodss/yaml v1.0.0
-	github.com/google/go-cmp v0.3.1 // indirect
-	github.com/gorilla/mux v1.7.3
-	github.com/mattbaird/jsonpatch v0.0.0-20171005235357-81af80346b1a // indirect
-	github.com/tektoncd/pipeline v0.9.2
-	go.uber.org/zap v1.13.0 // indirect
-	golang.org/x/oauth2 v0.0.0-20191202225959-858c2ad4c8b6 // indirect
-	golang.org/x/time v0.0.0-20191024005414-555d28b269f0 // indirect
-	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
-	gopkg.in/yaml.v2 v2.2.7 // indirect
-	k8s.io/api v0.17.0 // indirect
-	k8s.io/client-go v0.0.0-20190805141520-2fe0317bcee0 // indirect
-	k8s.io/utils v0.0.0-20191217005138-9e5e9d854fcc // indirect
-	knative.dev/pkg v0.0.0-20191216211902-b26ddf762bc9 // indirect
+	github.com/gorilla/mux v1.8.0
+	github.com/tektoncd/pipeline v0.44.0
+	gopkg.in/yaml.v3 v3.0.1
+	k8s.io/apimachinery v0.25.4
+	knative.dev/pkg v0.0.0-20221123011842-b78020c16606
 )
 
 go 1.13
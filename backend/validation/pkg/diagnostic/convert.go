@@ -0,0 +1,102 @@
+package diagnostic
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/lint"
+	"knative.dev/pkg/apis"
+)
+
+// Schema is the rule of the diagnostics reported by Tekton's validation
+const Schema = "schema"
+
+// fieldPaths matches the paths Tekton appends to a field error message
+var fieldPaths = regexp.MustCompile(`^[\w\-./\[\]]+(, [\w\-./\[\]]+)*$`)
+
+// FromLint converts the problems found by the linter, the path of each
+// problem is looked up in index
+func FromLint(result lint.Result, index *Index) List {
+	list := List{}
+	for _, p := range result {
+		list = append(list, Diagnostic{
+			Rule:     p.Rule,
+			Severity: Severity(p.Level),
+			Path:     index.PathAt(p.Line, p.Column),
+			Line:     p.Line,
+			Column:   p.Column,
+			Message:  p.Message,
+		})
+	}
+	return list
+}
+
+// FromSchema converts an error returned by Tekton's validation. Field errors
+// give one diagnostic per field, other errors a single one without position.
+func FromSchema(err error, index *Index) List {
+	list := List{}
+	if err == nil {
+		return list
+	}
+	fe, ok := err.(*apis.FieldError)
+	if !ok {
+		return append(list, Diagnostic{Rule: Schema, Severity: Error, Message: err.Error()})
+	}
+	levels := map[apis.DiagnosticLevel]Severity{apis.ErrorLevel: Error, apis.WarningLevel: Warning}
+	for _, level := range []apis.DiagnosticLevel{apis.ErrorLevel, apis.WarningLevel} {
+		filtered := fe.Filter(level)
+		if filtered == nil {
+			continue
+		}
+		for _, f := range splitFieldErrors(filtered.Error()) {
+			if len(f.paths) == 0 {
+				list = append(list, Diagnostic{Rule: Schema, Severity: levels[level], Message: f.message})
+				continue
+			}
+			for _, path := range f.paths {
+				line, column := index.Locate(path)
+				list = append(list, Diagnostic{
+					Rule:     Schema,
+					Severity: levels[level],
+					Path:     path,
+					Line:     line,
+					Column:   column,
+					Message:  f.message,
+				})
+			}
+		}
+	}
+	return list
+}
+
+type fieldError struct {
+	message string
+	paths   []string
+}
+
+// splitFieldErrors reads back the errors merged by FieldError.Error(), each
+// one is written as "message: path, path" followed by optional details
+func splitFieldErrors(s string) []fieldError {
+	var errs []fieldError
+	for _, line := range strings.Split(s, "\n") {
+		i := strings.LastIndex(line, ": ")
+		if i != -1 {
+			paths := line[i+2:]
+			if paths == "" {
+				errs = append(errs, fieldError{message: line[:i]})
+				continue
+			}
+			if fieldPaths.MatchString(paths) {
+				errs = append(errs, fieldError{message: line[:i], paths: strings.Split(paths, ", ")})
+				continue
+			}
+		}
+		if len(errs) == 0 {
+			errs = append(errs, fieldError{message: line})
+			continue
+		}
+		// details of the previous error
+		errs[len(errs)-1].message += ": " + line
+	}
+	return errs
+}
@@ -0,0 +1,65 @@
+package diagnostic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity tells if a diagnostic blocks the upload of a resource
+type Severity string
+
+// Severities of a diagnostic
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Diagnostic is a single problem found while validating a resource
+type Diagnostic struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Path == "" {
+		return fmt.Sprintf("%d:%d %s %s (%s)", d.Line, d.Column, d.Severity, d.Message, d.Rule)
+	}
+	return fmt.Sprintf("%d:%d %s %s: %s (%s)", d.Line, d.Column, d.Severity, d.Path, d.Message, d.Rule)
+}
+
+// List holds the diagnostics of a resource
+type List []Diagnostic
+
+// HasErrors checks if any of the diagnostics has error severity
+func (l List) HasErrors() bool {
+	for _, d := range l {
+		if d.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort orders the diagnostics by position, diagnostics without a position
+// come first
+func (l List) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if l[i].Line != l[j].Line {
+			return l[i].Line < l[j].Line
+		}
+		return l[i].Column < l[j].Column
+	})
+}
+
+func (l List) String() string {
+	lines := make([]string, len(l))
+	for i, d := range l {
+		lines[i] = d.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,91 @@
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/lint"
+	"knative.dev/pkg/apis"
+)
+
+const task = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: echo
+spec:
+  params:
+    - name: message
+      type: string
+  steps:
+    - name: echo
+      image: alpine
+    - name: print
+      image: ubuntu
+`
+
+func TestLocate(t *testing.T) {
+	index := NewIndex([]byte(task))
+	tests := []struct {
+		path         string
+		line, column int
+	}{
+		{"spec", 5, 1},
+		{"spec.steps[1].image", 13, 7},
+		{"spec.steps[1]", 12, 7},
+		{"spec.params[message].type", 8, 7},
+		{"spec.steps[1].script", 12, 7},
+		{"spec.workspaces", 5, 1},
+	}
+	for _, tc := range tests {
+		line, column := index.Locate(tc.path)
+		if line != tc.line || column != tc.column {
+			t.Errorf("Locate(%q) = %d:%d, expected %d:%d", tc.path, line, column, tc.line, tc.column)
+		}
+	}
+}
+
+func TestPathAt(t *testing.T) {
+	index := NewIndex([]byte(task))
+	if path := index.PathAt(11, 14); path != "spec.steps[0].image" {
+		t.Errorf("unexpected path %q", path)
+	}
+	if path := index.PathAt(1, 1); path != "apiVersion" {
+		t.Errorf("unexpected path %q", path)
+	}
+}
+
+func TestFromSchema(t *testing.T) {
+	index := NewIndex([]byte(task))
+	err := apis.ErrMissingField("image").ViaFieldIndex("steps", 1).ViaField("spec").
+		Also(apis.ErrGeneric("deprecated field", "resources").ViaField("spec").At(apis.WarningLevel)).
+		Also(apis.ErrMultipleOneOf("spec.steps[0].script", "spec.steps[0].image"))
+	list := FromSchema(err, index)
+	list.Sort()
+	expected := []string{
+		"5:1 warning spec.resources: deprecated field (schema)",
+		"10:7 error spec.steps[0].script: expected exactly one, got both (schema)",
+		"11:7 error spec.steps[0].image: expected exactly one, got both (schema)",
+		"13:7 error spec.steps[1].image: missing field(s) (schema)",
+	}
+	if len(list) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d:\n%s", len(expected), len(list), list)
+	}
+	for i, d := range list {
+		if d.String() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], d.String())
+		}
+	}
+	if !list.HasErrors() {
+		t.Error("expected errors")
+	}
+}
+
+func TestFromLint(t *testing.T) {
+	content := []byte("a:\n  b: 1 \n")
+	list := FromLint(lint.Lint(content, nil), NewIndex(content))
+	if len(list) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(list))
+	}
+	if d := list[0]; d.Path != "a.b" || d.Rule != lint.TrailingSpaces || d.Severity != Error {
+		t.Errorf("unexpected diagnostic %v", d)
+	}
+}
@@ -0,0 +1,145 @@
+package diagnostic
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Index maps the YAML paths of a document to their position in the file.
+// Paths are written the way Tekton reports them, e.g. spec.steps[0].image
+// or spec.params[url].description where a key selects the item of a list
+// by its name.
+type Index struct {
+	root    *yaml.Node
+	entries []entry
+}
+
+type entry struct {
+	path   string
+	line   int
+	column int
+}
+
+// NewIndex parses the first document of content, an empty index is returned
+// if it is not valid YAML
+func NewIndex(content []byte) *Index {
+	index := &Index{}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return index
+	}
+	index.root = doc.Content[0]
+	index.add("", index.root)
+	return index
+}
+
+func (x *Index) add(path string, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			child := join(path, key.Value)
+			x.entries = append(x.entries, entry{child, key.Line, key.Column})
+			x.add(child, node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			child := path + "[" + strconv.Itoa(i) + "]"
+			x.entries = append(x.entries, entry{child, item.Line, item.Column})
+			x.add(child, item)
+		}
+	}
+}
+
+// Locate returns the position of path. When path does not exist, e.g. for
+// a missing field, the position of its closest existing parent is returned.
+func (x *Index) Locate(path string) (line, column int) {
+	if x.root == nil {
+		return 0, 0
+	}
+	node, key := x.root, (*yaml.Node)(nil)
+	for _, segment := range split(path) {
+		next, nextKey := child(node, segment)
+		if next == nil {
+			break
+		}
+		node, key = next, nextKey
+	}
+	if key != nil {
+		return key.Line, key.Column
+	}
+	return node.Line, node.Column
+}
+
+// PathAt returns the path of the innermost field which starts at or
+// before the given position
+func (x *Index) PathAt(line, column int) string {
+	path := ""
+	for _, e := range x.entries {
+		if e.line > line || (e.line == line && e.column > column) {
+			break
+		}
+		path = e.path
+	}
+	return path
+}
+
+// child returns the node selected by segment and the key node of the
+// mapping entry holding it
+func child(node *yaml.Node, segment string) (*yaml.Node, *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				return node.Content[i+1], node.Content[i]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(segment); err == nil {
+			if i >= 0 && i < len(node.Content) {
+				return node.Content[i], nil
+			}
+			return nil, nil
+		}
+		for _, item := range node.Content {
+			if name, _ := child(item, "name"); name != nil && name.Value == segment {
+				return item, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// split breaks spec.steps[0].image into spec, steps, 0 and image
+func split(path string) []string {
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		for part != "" {
+			open := strings.Index(part, "[")
+			if open == -1 {
+				segments = append(segments, part)
+				break
+			}
+			if open > 0 {
+				segments = append(segments, part[:open])
+			}
+			end := strings.Index(part[open:], "]")
+			if end == -1 {
+				segments = append(segments, part[open+1:])
+				break
+			}
+			segments = append(segments, part[open+1:open+end])
+			part = part[open+end+1:]
+		}
+	}
+	return segments
+}
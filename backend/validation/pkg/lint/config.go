@@ -0,0 +1,130 @@
+package lint
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+)
+
+// Level is the severity of a problem reported by a rule
+type Level string
+
+// Levels a rule can be configured with
+const (
+	Error   Level = "error"
+	Warning Level = "warning"
+	Disable Level = "disable"
+)
+
+// Names of the rules, they match the rules of yamllint
+const (
+	Braces               = "braces"
+	Brackets             = "brackets"
+	Colons               = "colons"
+	Commas               = "commas"
+	Comments             = "comments"
+	CommentsIndentation  = "comments-indentation"
+	DocumentEnd          = "document-end"
+	DocumentStart        = "document-start"
+	EmptyLines           = "empty-lines"
+	EmptyValues          = "empty-values"
+	Hyphens              = "hyphens"
+	Indentation          = "indentation"
+	KeyDuplicates        = "key-duplicates"
+	KeyOrdering          = "key-ordering"
+	LineLength           = "line-length"
+	NewLineAtEndOfFile   = "new-line-at-end-of-file"
+	NewLines             = "new-lines"
+	OctalValues          = "octal-values"
+	QuotedStrings        = "quoted-strings"
+	TrailingSpaces       = "trailing-spaces"
+	Truthy               = "truthy"
+	Syntax               = "syntax"
+	defaultMaxLineLength = 80
+)
+
+// Config holds the level of each rule
+type Config struct {
+	Rules map[string]Level
+}
+
+// DefaultConfig returns the rule set of the hub, it is the same as the
+// .yamllint file shipped with the validation service
+func DefaultConfig() *Config {
+	return &Config{Rules: map[string]Level{
+		Braces:              Error,
+		Brackets:            Error,
+		Colons:              Error,
+		Commas:              Error,
+		Comments:            Warning,
+		CommentsIndentation: Warning,
+		DocumentEnd:         Disable,
+		DocumentStart:       Disable,
+		EmptyLines:          Error,
+		EmptyValues:         Error,
+		Hyphens:             Error,
+		Indentation:         Error,
+		KeyDuplicates:       Error,
+		KeyOrdering:         Disable,
+		LineLength:          Disable,
+		NewLineAtEndOfFile:  Disable,
+		NewLines:            Error,
+		OctalValues:         Error,
+		QuotedStrings:       Disable,
+		TrailingSpaces:      Error,
+		Truthy:              Warning,
+	}}
+}
+
+// LoadConfig reads the rule levels from a yamllint configuration file. Only
+// the level of the rules is taken into account, rule options are fixed
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(b)
+}
+
+// ParseConfig parses the rule levels of a yamllint configuration
+func ParseConfig(b []byte) (*Config, error) {
+	var file struct {
+		Rules map[string]interface{} `json:"rules"`
+	}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+	config := DefaultConfig()
+	for rule, value := range file.Rules {
+		if _, ok := config.Rules[rule]; !ok {
+			return nil, fmt.Errorf("unknown rule %q", rule)
+		}
+		switch v := value.(type) {
+		case string:
+			if v == "disable" {
+				config.Rules[rule] = Disable
+			} else {
+				config.Rules[rule] = Error
+			}
+		case map[string]interface{}:
+			config.Rules[rule] = Error
+			if level, ok := v["level"].(string); ok && Level(level) == Warning {
+				config.Rules[rule] = Warning
+			}
+		default:
+			return nil, fmt.Errorf("invalid configuration of rule %q", rule)
+		}
+	}
+	return config, nil
+}
+
+func (c *Config) level(rule string) Level {
+	if level, ok := c.Rules[rule]; ok {
+		return level
+	}
+	if rule == Syntax {
+		return Error
+	}
+	return Disable
+}
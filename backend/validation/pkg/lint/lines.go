@@ -0,0 +1,395 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// line is a physical line of the file along with the tokens relevant for
+// the character level rules
+type line struct {
+	number  int
+	text    string
+	indent  int
+	blank   bool
+	block   bool
+	comment int
+	colons  []int
+	commas  []int
+	opens   []int
+	closes  []int
+	hyphens []int
+}
+
+// commentOnly checks if the line holds nothing but a comment
+func (ln *line) commentOnly() bool {
+	return !ln.block && ln.comment == ln.indent
+}
+
+// scanner tokenizes lines while keeping track of state spanning lines
+type scanner struct {
+	flow  int
+	quote byte
+}
+
+var blockIndicator = regexp.MustCompile(`^[|>][-+0-9]*\s*(#.*)?$`)
+
+func splitLines(content string) []*line {
+	texts := strings.Split(content, "\n")
+	if strings.HasSuffix(content, "\n") {
+		texts = texts[:len(texts)-1]
+	}
+	s := &scanner{}
+	lines := make([]*line, len(texts))
+	blockParent := -1
+	for i, text := range texts {
+		text = strings.TrimSuffix(text, "\r")
+		ln := &line{number: i + 1, text: text, comment: -1}
+		ln.indent = len(text) - len(strings.TrimLeft(text, " "))
+		ln.blank = strings.TrimSpace(text) == ""
+		lines[i] = ln
+
+		if blockParent >= 0 {
+			if ln.blank || ln.indent > blockParent {
+				ln.block = true
+				continue
+			}
+			blockParent = -1
+		}
+		if ln.blank {
+			continue
+		}
+		blockParent = s.scan(ln)
+	}
+	return lines
+}
+
+// scan records the position of tokens on the line. It returns the column of
+// the parent node if the line starts a block scalar, otherwise -1
+func (s *scanner) scan(ln *line) int {
+	text := ln.text
+	n := len(text)
+	i := ln.indent
+	if s.quote != 0 {
+		i = s.skipQuote(text, i)
+	}
+	valueStart := s.quote == 0
+	parent, keyStart := ln.indent-1, ln.indent
+
+	for i < n {
+		c := text[i]
+		if c == ' ' || c == '\t' {
+			i++
+			continue
+		}
+		if c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t') {
+			ln.comment = i
+			break
+		}
+
+		if s.flow > 0 {
+			switch c {
+			case '"', '\'':
+				s.quote = c
+				i = s.skipQuote(text, i+1)
+				continue
+			case '[', '{':
+				ln.opens = append(ln.opens, i)
+				s.flow++
+			case ']', '}':
+				ln.closes = append(ln.closes, i)
+				s.flow--
+				valueStart = false
+			case ',':
+				ln.commas = append(ln.commas, i)
+			case ':':
+				if i+1 == n || strings.IndexByte(" \t,]}", text[i+1]) >= 0 || (i > 0 && text[i-1] == '"') {
+					ln.colons = append(ln.colons, i)
+				}
+			}
+			i++
+			continue
+		}
+
+		if valueStart {
+			switch {
+			case c == '-' && (i+1 == n || text[i+1] == ' '):
+				ln.hyphens = append(ln.hyphens, i)
+				parent = i
+				i++
+				continue
+			case c == '?' && (i+1 == n || text[i+1] == ' '):
+				i++
+				continue
+			case c == '[' || c == '{':
+				ln.opens = append(ln.opens, i)
+				s.flow++
+				i++
+				continue
+			case c == '"' || c == '\'':
+				keyStart = i
+				s.quote = c
+				i = s.skipQuote(text, i+1)
+				valueStart = false
+				continue
+			case c == '|' || c == '>':
+				if blockIndicator.MatchString(text[i:]) {
+					if idx := strings.Index(text[i:], "#"); idx >= 0 {
+						ln.comment = i + idx
+					}
+					return parent
+				}
+			case c == '&' || c == '!':
+				for i < n && text[i] != ' ' {
+					i++
+				}
+				continue
+			}
+			keyStart = i
+			valueStart = false
+		}
+
+		if c == ':' && (i+1 == n || text[i+1] == ' ' || text[i+1] == '\t') {
+			ln.colons = append(ln.colons, i)
+			parent = keyStart
+			valueStart = true
+		}
+		i++
+	}
+	return -1
+}
+
+// skipQuote returns the index following the closing quote, the quote is kept
+// open if it does not end on this line
+func (s *scanner) skipQuote(text string, i int) int {
+	for i < len(text) {
+		switch {
+		case s.quote == '"' && text[i] == '\\':
+			i += 2
+			continue
+		case s.quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i += 2
+			continue
+		case text[i] == s.quote:
+			s.quote = 0
+			return i + 1
+		}
+		i++
+	}
+	return i
+}
+
+func countSpaces(text string, i int) int {
+	count := 0
+	for i+count < len(text) && text[i+count] == ' ' {
+		count++
+	}
+	return count
+}
+
+func countSpacesBefore(text string, i int) int {
+	count := 0
+	for i-count-1 >= 0 && text[i-count-1] == ' ' {
+		count++
+	}
+	return count
+}
+
+// followedByContent checks if there is a token after the spaces at i
+func followedByContent(text string, i int) bool {
+	i += countSpaces(text, i)
+	return i < len(text) && text[i] != '#'
+}
+
+// precededByContent checks if there is a token before the spaces ending at i
+func precededByContent(text string, i int) bool {
+	return strings.TrimSpace(text[:i]) != ""
+}
+
+func (l *linter) checkLines(content string) {
+	lines := splitLines(content)
+
+	if strings.Contains(content, "\r\n") && len(lines) > 0 {
+		l.report(NewLines, 1, len(lines[0].text)+1, "wrong new line character: expected \\n")
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		last := lines[len(lines)-1]
+		l.report(NewLineAtEndOfFile, last.number, len(last.text)+1, "no new line character at the end of file")
+	}
+	l.checkEmptyLines(lines)
+	l.checkDocumentMarkers(lines)
+
+	prevIndent := 0
+	for i, ln := range lines {
+		if trimmed := strings.TrimRight(ln.text, " \t"); trimmed != ln.text {
+			l.report(TrailingSpaces, ln.number, len(trimmed)+1, "trailing spaces")
+		}
+		l.checkLineLength(ln)
+		if ln.blank || ln.block {
+			continue
+		}
+
+		l.checkColons(ln)
+		l.checkCommas(ln)
+		l.checkFlowSpaces(ln)
+		for _, h := range ln.hyphens {
+			if followedByContent(ln.text, h+1) && countSpaces(ln.text, h+1) > 1 {
+				l.report(Hyphens, ln.number, h+2, "too many spaces after hyphen")
+			}
+		}
+		if ln.comment >= 0 {
+			l.checkComment(ln)
+		}
+
+		if ln.commentOnly() {
+			nextIndent := 0
+			for _, next := range lines[i+1:] {
+				if !next.blank && !next.commentOnly() {
+					nextIndent = next.indent
+					break
+				}
+			}
+			maxIndent := prevIndent
+			if nextIndent > maxIndent {
+				maxIndent = nextIndent
+			}
+			if ln.indent != nextIndent && ln.indent != maxIndent {
+				l.report(CommentsIndentation, ln.number, ln.indent+1, "comment not indented like content")
+			}
+			continue
+		}
+		prevIndent = ln.indent
+	}
+}
+
+func (l *linter) checkEmptyLines(lines []*line) {
+	blanks := 0
+	for i, ln := range lines {
+		if !ln.blank {
+			blanks = 0
+			continue
+		}
+		blanks++
+		if i+1 < len(lines) && lines[i+1].blank {
+			continue
+		}
+		switch {
+		case blanks == i+1:
+			l.report(EmptyLines, ln.number, 1, "too many blank lines (%d > 0)", blanks)
+		case i+1 == len(lines):
+			l.report(EmptyLines, ln.number, 1, "too many blank lines (%d > 0)", blanks)
+		case blanks > 2:
+			l.report(EmptyLines, ln.number, 1, "too many blank lines (%d > 2)", blanks)
+		}
+	}
+}
+
+func (l *linter) checkDocumentMarkers(lines []*line) {
+	var first, last *line
+	for _, ln := range lines {
+		if ln.blank || ln.commentOnly() || strings.HasPrefix(ln.text, "%") {
+			continue
+		}
+		if first == nil {
+			first = ln
+		}
+		last = ln
+	}
+	if first == nil {
+		return
+	}
+	if first.text != "---" && !strings.HasPrefix(first.text, "--- ") {
+		l.report(DocumentStart, first.number, 1, "missing document start \"---\"")
+	}
+	if strings.TrimSpace(last.text) != "..." {
+		l.report(DocumentEnd, last.number+1, 1, "missing document end \"...\"")
+	}
+}
+
+func (l *linter) checkLineLength(ln *line) {
+	if len(ln.text) <= defaultMaxLineLength {
+		return
+	}
+	// allow long lines made of a single word such as URLs
+	word := strings.TrimSpace(ln.text)
+	word = strings.TrimLeft(strings.TrimPrefix(strings.TrimPrefix(word, "- "), "#"), " ")
+	if !strings.Contains(word, " ") {
+		return
+	}
+	l.report(LineLength, ln.number, defaultMaxLineLength+1, "line too long (%d > %d characters)", len(ln.text), defaultMaxLineLength)
+}
+
+func (l *linter) checkColons(ln *line) {
+	for _, c := range ln.colons {
+		if countSpacesBefore(ln.text, c) > 0 && precededByContent(ln.text, c) {
+			l.report(Colons, ln.number, c+1, "too many spaces before colon")
+		}
+		if followedByContent(ln.text, c+1) && countSpaces(ln.text, c+1) > 1 {
+			l.report(Colons, ln.number, c+2, "too many spaces after colon")
+		}
+	}
+}
+
+func (l *linter) checkCommas(ln *line) {
+	for _, c := range ln.commas {
+		if countSpacesBefore(ln.text, c) > 0 && precededByContent(ln.text, c) {
+			l.report(Commas, ln.number, c+1, "too many spaces before comma")
+		}
+		if !followedByContent(ln.text, c+1) {
+			continue
+		}
+		switch spaces := countSpaces(ln.text, c+1); {
+		case spaces == 0:
+			l.report(Commas, ln.number, c+2, "too few spaces after comma")
+		case spaces > 1:
+			l.report(Commas, ln.number, c+2, "too many spaces after comma")
+		}
+	}
+}
+
+// checkFlowSpaces checks the spaces inside braces and brackets
+func (l *linter) checkFlowSpaces(ln *line) {
+	text := ln.text
+	names := map[byte]string{'{': "braces", '}': "braces", '[': "brackets", ']': "brackets"}
+	rules := map[byte]string{'{': Braces, '}': Braces, '[': Brackets, ']': Brackets}
+	for _, o := range ln.opens {
+		if !followedByContent(text, o+1) {
+			continue
+		}
+		spaces := countSpaces(text, o+1)
+		if spaces == 0 {
+			continue
+		}
+		if next := text[o+1+spaces]; next == '}' || next == ']' {
+			l.report(rules[text[o]], ln.number, o+2, "too many spaces inside empty %s", names[text[o]])
+		} else {
+			l.report(rules[text[o]], ln.number, o+2, "too many spaces inside %s", names[text[o]])
+		}
+	}
+	for _, c := range ln.closes {
+		spaces := countSpacesBefore(text, c)
+		if spaces == 0 || !precededByContent(text, c) {
+			continue
+		}
+		if prev := text[c-spaces-1]; prev == '{' || prev == '[' {
+			continue
+		}
+		l.report(rules[text[c]], ln.number, c-spaces+1, "too many spaces inside %s", names[text[c]])
+	}
+}
+
+func (l *linter) checkComment(ln *line) {
+	text := ln.text
+	c := ln.comment
+	start := c
+	for start < len(text) && text[start] == '#' {
+		start++
+	}
+	shebang := ln.number == 1 && strings.HasPrefix(text, "#!")
+	if start < len(text) && text[start] != ' ' && !shebang {
+		l.report(Comments, ln.number, c+2, "missing starting space in comment")
+	}
+	if precededByContent(text, c) && countSpacesBefore(text, c) < 2 {
+		l.report(Comments, ln.number, c+1, "too few spaces before comment")
+	}
+}
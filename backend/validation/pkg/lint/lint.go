@@ -0,0 +1,78 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem is a single diagnostic reported by a rule
+type Problem struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Rule    string `json:"rule"`
+	Level   Level  `json:"level"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d %s %s (%s)", p.Line, p.Column, p.Level, p.Message, p.Rule)
+}
+
+// Result is the outcome of linting a file
+type Result []Problem
+
+// HasErrors checks if any of the problems has error level
+func (r Result) HasErrors() bool {
+	for _, p := range r {
+		if p.Level == Error {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Result) String() string {
+	lines := make([]string, len(r))
+	for i, p := range r {
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+type linter struct {
+	config   *Config
+	problems Result
+}
+
+func (l *linter) report(rule string, line, column int, format string, args ...interface{}) {
+	level := l.config.level(rule)
+	if level == Disable {
+		return
+	}
+	l.problems = append(l.problems, Problem{
+		Line:    line,
+		Column:  column,
+		Rule:    rule,
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Lint checks the content of a YAML file against the rules of the config
+// and returns the problems ordered by position
+func Lint(content []byte, config *Config) Result {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	l := &linter{config: config, problems: Result{}}
+	l.checkLines(string(content))
+	l.checkNodes(content)
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		if l.problems[i].Line != l.problems[j].Line {
+			return l.problems[i].Line < l.problems[j].Line
+		}
+		return l.problems[i].Column < l.problems[j].Column
+	})
+	return l.problems
+}
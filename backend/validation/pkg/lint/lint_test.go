@@ -0,0 +1,91 @@
+package lint
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{"valid", "a: 1\nb:\n  c: [x, y]\n  d: {e: f}\n", nil},
+		{"trailing spaces", "a: 1 \n", []string{"1:5 error trailing spaces (trailing-spaces)"}},
+		{"duplicate key", "a: 1\na: 2\n", []string{"2:1 error duplication of key \"a\" in mapping (key-duplicates)"}},
+		{"truthy", "a: yes\n", []string{"1:4 warning truthy value should be one of [false, true] (truthy)"}},
+		{"quoted truthy", "a: \"yes\"\n", nil},
+		{"empty value", "a:\nb: 1\n", []string{"1:3 error empty value in block mapping (empty-values)"}},
+		{"octal", "a: 0755\n", []string{"1:4 error forbidden implicit octal value \"0755\" (octal-values)"}},
+		{"colons", "a : 1\nb:  2\n", []string{
+			"1:3 error too many spaces before colon (colons)",
+			"2:3 error too many spaces after colon (colons)",
+		}},
+		{"commas", "a: [x ,y]\n", []string{
+			"1:7 error too many spaces before comma (commas)",
+			"1:8 error too few spaces after comma (commas)",
+		}},
+		{"brackets", "a: [ x ]\nb: { c: d }\n", []string{
+			"1:5 error too many spaces inside brackets (brackets)",
+			"1:7 error too many spaces inside brackets (brackets)",
+			"2:5 error too many spaces inside braces (braces)",
+			"2:10 error too many spaces inside braces (braces)",
+		}},
+		{"hyphens", "a:\n-   x\n", []string{"2:2 error too many spaces after hyphen (hyphens)"}},
+		{"comments", "a: 1 #x\n", []string{
+			"1:6 warning too few spaces before comment (comments)",
+			"1:7 warning missing starting space in comment (comments)",
+		}},
+		{"comments indentation", "a:\n     # comment\n  b: 1\n", []string{"2:6 warning comment not indented like content (comments-indentation)"}},
+		{"empty lines", "\na: 1\n\n\n\nb: 2\n\n", []string{
+			"1:1 error too many blank lines (1 > 0) (empty-lines)",
+			"5:1 error too many blank lines (3 > 2) (empty-lines)",
+			"7:1 error too many blank lines (1 > 0) (empty-lines)",
+		}},
+		{"new lines", "a: 1\r\n", []string{"1:5 error wrong new line character: expected \\n (new-lines)"}},
+		{"indentation", "a:\n  b:\n      c: 1\n", []string{"3:7 error wrong indentation: expected 4 but found 6 (indentation)"}},
+		{"sequence indentation", "a:\n- x\nb:\n  - y\n", nil},
+		{"syntax", "a: b\n c: d: e\n", []string{"2:1 error syntax error: mapping values are not allowed in this context (syntax)"}},
+		{"block scalar", "script: |\n  #!/bin/sh\n  echo [ x ] ,y  #a\nb: 1\n", nil},
+		{"urls", "image: gcr.io/foo:v1\nurl: http://example.com\n", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Lint([]byte(test.content), nil)
+			if len(result) != len(test.expected) {
+				t.Fatalf("Expected: %v , Got: %v", test.expected, result)
+			}
+			for i, problem := range result {
+				if problem.String() != test.expected[i] {
+					t.Errorf("Expected: %v , Got: %v", test.expected[i], problem.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLintTasks(t *testing.T) {
+	for _, file := range []string{"../../test/valid_task.yaml", "../../resources/pipeline.yaml"} {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := Lint(content, nil); result.HasErrors() {
+			t.Errorf("%s: unexpected errors:\n%v", file, result)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	config, err := LoadConfig("../../.yamllint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := DefaultConfig()
+	for rule, level := range expected.Rules {
+		if config.Rules[rule] != level {
+			t.Errorf("%s Expected: %v , Got: %v", rule, level, config.Rules[rule])
+		}
+	}
+}
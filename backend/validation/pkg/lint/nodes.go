@@ -0,0 +1,146 @@
+package lint
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	syntaxError   = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	implicitOctal = regexp.MustCompile(`^0[0-7]+$`)
+	explicitOctal = regexp.MustCompile(`^0o[0-7]+$`)
+	truthyValues  = map[string]bool{
+		"YES": true, "Yes": true, "yes": true, "NO": true, "No": true, "no": true,
+		"TRUE": true, "True": true, "FALSE": true, "False": true,
+		"ON": true, "On": true, "on": true, "OFF": true, "Off": true, "off": true,
+	}
+)
+
+func (l *linter) checkNodes(content []byte) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	indent := &indentation{}
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			l.reportSyntax(err)
+			return
+		}
+		for _, root := range doc.Content {
+			if isBlock(root) && root.Column != 1 {
+				l.report(Indentation, root.Line, root.Column, "wrong indentation: expected 0 but found %d", root.Column-1)
+			}
+			l.walk(root, false, indent)
+		}
+	}
+}
+
+func (l *linter) reportSyntax(err error) {
+	match := syntaxError.FindStringSubmatch(err.Error())
+	if match == nil {
+		l.report(Syntax, 1, 1, "syntax error: %s", err)
+		return
+	}
+	line, _ := strconv.Atoi(match[1])
+	l.report(Syntax, line, 1, "syntax error: %s", match[2])
+}
+
+// indentation holds the indentation width found first in the file which
+// all other indentations must be consistent with
+type indentation struct {
+	width int
+}
+
+func (l *linter) walk(node *yaml.Node, isKey bool, indent *indentation) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		l.checkMapping(node, indent)
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			l.walk(item, false, indent)
+		}
+	case yaml.ScalarNode:
+		l.checkScalar(node, isKey)
+	}
+}
+
+func (l *linter) checkMapping(node *yaml.Node, indent *indentation) {
+	seen := map[string]bool{}
+	previous := ""
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		if key.Kind == yaml.ScalarNode && key.Value != "<<" {
+			if seen[key.Value] {
+				l.report(KeyDuplicates, key.Line, key.Column, "duplication of key \"%s\" in mapping", key.Value)
+			}
+			seen[key.Value] = true
+			if previous > key.Value {
+				l.report(KeyOrdering, key.Line, key.Column, "wrong ordering of key \"%s\" in mapping", key.Value)
+			}
+			previous = key.Value
+		}
+
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" && value.Value == "" {
+			if node.Style&yaml.FlowStyle != 0 {
+				l.report(EmptyValues, value.Line, value.Column, "empty value in flow mapping")
+			} else {
+				l.report(EmptyValues, value.Line, value.Column, "empty value in block mapping")
+			}
+		}
+
+		if isBlock(value) && value.Line > key.Line {
+			l.checkIndentation(indent, key, value)
+		}
+
+		l.walk(key, true, indent)
+		l.walk(value, false, indent)
+	}
+}
+
+// checkIndentation checks the indentation of a block collection relative to
+// its key. Sequences may be indented or not
+func (l *linter) checkIndentation(indent *indentation, key, value *yaml.Node) {
+	offset := value.Column - key.Column
+	if value.Kind == yaml.SequenceNode && offset == 0 {
+		return
+	}
+	if indent.width == 0 && offset > 0 {
+		indent.width = offset
+		return
+	}
+	if offset != indent.width {
+		l.report(Indentation, value.Line, value.Column, "wrong indentation: expected %d but found %d",
+			key.Column-1+indent.width, value.Column-1)
+	}
+}
+
+func (l *linter) checkScalar(node *yaml.Node, isKey bool) {
+	// only plain scalars are subject to the rules
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle|yaml.TaggedStyle) != 0 {
+		return
+	}
+	if truthyValues[node.Value] {
+		l.report(Truthy, node.Line, node.Column, "truthy value should be one of [false, true]")
+	}
+	if implicitOctal.MatchString(node.Value) {
+		l.report(OctalValues, node.Line, node.Column, "forbidden implicit octal value \"%s\"", node.Value)
+	}
+	if explicitOctal.MatchString(node.Value) {
+		l.report(OctalValues, node.Line, node.Column, "forbidden explicit octal value \"%s\"", node.Value)
+	}
+	if !isKey && node.Tag == "!!str" {
+		l.report(QuotedStrings, node.Line, node.Column, "string value is not quoted")
+	}
+}
+
+func isBlock(node *yaml.Node) bool {
+	return (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) && node.Style&yaml.FlowStyle == 0
+}
@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+)
+
+// Level is the severity a policy is enforced with
+type Level string
+
+// Levels a policy can be configured with
+const (
+	Error   Level = "error"
+	Warning Level = "warning"
+	Disable Level = "disable"
+)
+
+// Names of the policies
+const (
+	ImageReference   = "image-reference"
+	Privileged       = "privileged"
+	RunAsRoot        = "run-as-root"
+	ImplicitRoot     = "implicit-root"
+	HostPath         = "host-path"
+	Description      = "description"
+	ParamDescription = "param-description"
+	VersionLabel     = "version-label"
+	HardcodedSecret  = "hardcoded-secret"
+)
+
+// Config holds the level of each policy
+type Config struct {
+	Rules map[string]Level
+}
+
+// DefaultConfig returns the policies of the hub. Policies which make a
+// resource unsafe to run are errors, the others only lower its quality.
+func DefaultConfig() *Config {
+	return &Config{Rules: map[string]Level{
+		ImageReference:   Warning,
+		Privileged:       Error,
+		RunAsRoot:        Error,
+		ImplicitRoot:     Warning,
+		HostPath:         Error,
+		Description:      Warning,
+		ParamDescription: Warning,
+		VersionLabel:     Warning,
+		HardcodedSecret:  Error,
+	}}
+}
+
+// LoadConfig reads the policy levels from a file
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(b)
+}
+
+// ParseConfig parses the policy levels, e.g.
+//
+//	rules:
+//	  image-reference: error
+//	  version-label: disable
+//
+// Policies which are not listed keep their default level
+func ParseConfig(b []byte) (*Config, error) {
+	var file struct {
+		Rules map[string]Level `json:"rules"`
+	}
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+	config := DefaultConfig()
+	for rule, level := range file.Rules {
+		if _, ok := config.Rules[rule]; !ok {
+			return nil, fmt.Errorf("unknown policy %q", rule)
+		}
+		switch level {
+		case Error, Warning, Disable:
+			config.Rules[rule] = level
+		default:
+			return nil, fmt.Errorf("invalid level %q of policy %q", level, rule)
+		}
+	}
+	return config, nil
+}
+
+func (c *Config) level(rule string) Level {
+	if level, ok := c.Rules[rule]; ok {
+		return level
+	}
+	return Disable
+}
@@ -0,0 +1,229 @@
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/diagnostic"
+)
+
+// versionLabel is the label holding the version of a resource on the hub
+const versionLabel = "app.kubernetes.io/version"
+
+// secretName matches the names of env variables which hold a secret
+var secretName = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api[_-]?key|credential|private[_-]?key)`)
+
+// resource holds the fields of a Task or Pipeline the policies look at,
+// they are the same in all the supported apiVersions
+type resource struct {
+	Metadata struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Description string  `json:"description"`
+		Params      []param `json:"params"`
+		Inputs      *struct {
+			Params []param `json:"params"`
+		} `json:"inputs"`
+		Steps        []container `json:"steps"`
+		StepTemplate *container  `json:"stepTemplate"`
+		Sidecars     []container `json:"sidecars"`
+		Volumes      []struct {
+			Name     string      `json:"name"`
+			HostPath interface{} `json:"hostPath"`
+		} `json:"volumes"`
+	} `json:"spec"`
+}
+
+type param struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	Env   []struct {
+		Name      string      `json:"name"`
+		Value     string      `json:"value"`
+		ValueFrom interface{} `json:"valueFrom"`
+	} `json:"env"`
+	SecurityContext *securityContext `json:"securityContext"`
+}
+
+type securityContext struct {
+	Privileged   *bool  `json:"privileged"`
+	RunAsUser    *int64 `json:"runAsUser"`
+	RunAsNonRoot *bool  `json:"runAsNonRoot"`
+}
+
+type checker struct {
+	config      *Config
+	index       *diagnostic.Index
+	diagnostics diagnostic.List
+	// reported holds the rules reported by path, a stepTemplate is only
+	// reported once for all its steps
+	reported map[string]bool
+}
+
+func (c *checker) report(rule, path, format string, args ...interface{}) {
+	level := c.config.level(rule)
+	if level == Disable || c.reported[rule+" "+path] {
+		return
+	}
+	c.reported[rule+" "+path] = true
+	line, column := c.index.Locate(path)
+	c.diagnostics = append(c.diagnostics, diagnostic.Diagnostic{
+		Rule:     rule,
+		Severity: diagnostic.Severity(level),
+		Path:     path,
+		Line:     line,
+		Column:   column,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Check enforces the hub policies on the content of a resource, the
+// position of each violation is looked up in index
+func Check(content []byte, index *diagnostic.Index, config *Config) (diagnostic.List, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	var r resource
+	if err := yaml.Unmarshal(content, &r); err != nil {
+		return nil, err
+	}
+	c := &checker{config: config, index: index, diagnostics: diagnostic.List{}, reported: map[string]bool{}}
+
+	if _, ok := r.Metadata.Labels[versionLabel]; !ok {
+		c.report(VersionLabel, "metadata.labels", "missing label %q", versionLabel)
+	}
+	if strings.TrimSpace(r.Spec.Description) == "" {
+		c.report(Description, "spec.description", "missing description")
+	}
+	c.checkParams("spec.params", r.Spec.Params)
+	if r.Spec.Inputs != nil {
+		c.checkParams("spec.inputs.params", r.Spec.Inputs.Params)
+	}
+	if r.Spec.StepTemplate != nil {
+		c.checkContainer("spec.stepTemplate", *r.Spec.StepTemplate)
+	}
+	for i, step := range r.Spec.Steps {
+		path := fmt.Sprintf("spec.steps[%d]", i)
+		c.checkContainer(path, step)
+		c.checkUser(path, step, r.Spec.StepTemplate)
+	}
+	for i, sidecar := range r.Spec.Sidecars {
+		path := fmt.Sprintf("spec.sidecars[%d]", i)
+		c.checkContainer(path, sidecar)
+		// the stepTemplate does not apply to sidecars
+		c.checkUser(path, sidecar, nil)
+	}
+	for i, volume := range r.Spec.Volumes {
+		if volume.HostPath != nil {
+			c.report(HostPath, fmt.Sprintf("spec.volumes[%d].hostPath", i), "volume %q uses a hostPath", volume.Name)
+		}
+	}
+	return c.diagnostics, nil
+}
+
+func (c *checker) checkParams(path string, params []param) {
+	for i, p := range params {
+		if strings.TrimSpace(p.Description) == "" {
+			c.report(ParamDescription, fmt.Sprintf("%s[%d]", path, i), "param %q has no description", p.Name)
+		}
+	}
+}
+
+func (c *checker) checkContainer(path string, container container) {
+	if container.Image != "" {
+		if problem := checkImage(container.Image); problem != "" {
+			c.report(ImageReference, path+".image", "image %q %s", container.Image, problem)
+		}
+	}
+	if sc := container.SecurityContext; sc != nil {
+		if sc.Privileged != nil && *sc.Privileged {
+			c.report(Privileged, path+".securityContext.privileged", "container must not be privileged")
+		}
+	}
+	for i, env := range container.Env {
+		if env.ValueFrom == nil && env.Value != "" && !strings.Contains(env.Value, "$(") && secretName.MatchString(env.Name) {
+			c.report(HardcodedSecret, fmt.Sprintf("%s.env[%d].value", path, i), "env %q holds a hardcoded secret, use a secret reference instead", env.Name)
+		}
+	}
+}
+
+// checkUser checks the user a container runs as, given by the fields of its
+// security context or else of the stepTemplate
+func (c *checker) checkUser(path string, current container, template *container) {
+	var runAsUser *int64
+	var runAsNonRoot *bool
+	userPath, nonRootPath := "", ""
+	for _, source := range []struct {
+		path      string
+		container *container
+	}{{"spec.stepTemplate", template}, {path, &current}} {
+		if source.container == nil || source.container.SecurityContext == nil {
+			continue
+		}
+		sc := source.container.SecurityContext
+		if sc.RunAsUser != nil {
+			runAsUser, userPath = sc.RunAsUser, source.path+".securityContext.runAsUser"
+		}
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot, nonRootPath = sc.RunAsNonRoot, source.path+".securityContext.runAsNonRoot"
+		}
+	}
+	switch {
+	case runAsUser != nil && *runAsUser == 0:
+		c.report(RunAsRoot, userPath, "container must not run as root")
+	case runAsUser != nil:
+	case runAsNonRoot != nil && !*runAsNonRoot:
+		c.report(RunAsRoot, nonRootPath, "container must not be allowed to run as root")
+	case runAsNonRoot == nil:
+		c.report(ImplicitRoot, path, "container runs as the user of its image, which may be root, set runAsNonRoot or a non-root runAsUser")
+	}
+}
+
+// checkImage returns why an image reference is not pinned, images set from
+// params can not be checked
+func checkImage(image string) string {
+	if strings.Contains(image, "$(") || strings.Contains(image, "@") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	if i == -1 {
+		return "has no tag or digest"
+	}
+	if name[i+1:] == "latest" {
+		return "uses the latest tag"
+	}
+	return ""
+}
+
+// Score rates the quality of a resource from 0 to 100 as the share of the
+// enabled policies it complies with
+func Score(diagnostics diagnostic.List, config *Config) int {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	enabled := 0
+	for _, level := range config.Rules {
+		if level != Disable {
+			enabled++
+		}
+	}
+	if enabled == 0 {
+		return 100
+	}
+	violated := map[string]bool{}
+	for _, d := range diagnostics {
+		if config.level(d.Rule) != Disable {
+			violated[d.Rule] = true
+		}
+	}
+	return 100 * (enabled - len(violated)) / enabled
+}
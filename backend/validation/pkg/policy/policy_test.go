@@ -0,0 +1,198 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/diagnostic"
+)
+
+const compliant = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: echo
+  labels:
+    app.kubernetes.io/version: "0.1"
+spec:
+  description: Prints a message
+  params:
+    - name: message
+      description: The message to print
+  stepTemplate:
+    securityContext:
+      runAsNonRoot: true
+  steps:
+    - name: echo
+      image: alpine:3.11
+      env:
+        - name: TOKEN
+          valueFrom:
+            secretKeyRef:
+              name: token
+              key: token
+`
+
+const violating = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: echo
+spec:
+  params:
+    - name: message
+  steps:
+    - name: echo
+      image: alpine
+      securityContext:
+        privileged: true
+        runAsUser: 0
+      env:
+        - name: API_KEY
+          value: abc
+    - name: print
+      image: ubuntu:latest
+  volumes:
+    - name: docker
+      hostPath:
+        path: /var/run/docker.sock
+`
+
+func TestCheck(t *testing.T) {
+	diagnostics, err := Check([]byte(compliant), diagnostic.NewIndex([]byte(compliant)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diagnostics) != 0 {
+		t.Errorf("expected no violations, got:\n%s", diagnostics)
+	}
+	if score := Score(diagnostics, nil); score != 100 {
+		t.Errorf("expected score 100, got %d", score)
+	}
+
+	diagnostics, err = Check([]byte(violating), diagnostic.NewIndex([]byte(violating)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnostics.Sort()
+	expected := []string{
+		"3:1 warning metadata.labels: missing label \"app.kubernetes.io/version\" (version-label)",
+		"5:1 warning spec.description: missing description (description)",
+		"7:7 warning spec.params[0]: param \"message\" has no description (param-description)",
+		"10:7 warning spec.steps[0].image: image \"alpine\" has no tag or digest (image-reference)",
+		"12:9 error spec.steps[0].securityContext.privileged: container must not be privileged (privileged)",
+		"13:9 error spec.steps[0].securityContext.runAsUser: container must not run as root (run-as-root)",
+		"16:11 error spec.steps[0].env[0].value: env \"API_KEY\" holds a hardcoded secret, use a secret reference instead (hardcoded-secret)",
+		"17:7 warning spec.steps[1]: container runs as the user of its image, which may be root, set runAsNonRoot or a non-root runAsUser (implicit-root)",
+		"18:7 warning spec.steps[1].image: image \"ubuntu:latest\" uses the latest tag (image-reference)",
+		"21:7 error spec.volumes[0].hostPath: volume \"docker\" uses a hostPath (host-path)",
+	}
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("expected %d violations, got:\n%s", len(expected), diagnostics)
+	}
+	for i, d := range diagnostics {
+		if d.String() != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], d.String())
+		}
+	}
+	if score := Score(diagnostics, nil); score != 0 {
+		t.Errorf("expected score 0, got %d", score)
+	}
+}
+
+func TestCheckUser(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []string
+	}{
+		// inherited from the stepTemplate, reported once
+		{`
+  stepTemplate:
+    securityContext:
+      runAsUser: 0
+  steps:
+    - name: a
+    - name: b`, []string{"spec.stepTemplate.securityContext.runAsUser run-as-root"}},
+		// a step overrides the stepTemplate
+		{`
+  stepTemplate:
+    securityContext:
+      runAsUser: 0
+  steps:
+    - name: a
+      securityContext:
+        runAsUser: 1000`, nil},
+		{`
+  steps:
+    - name: a
+      securityContext:
+        runAsNonRoot: false`, []string{"spec.steps[0].securityContext.runAsNonRoot run-as-root"}},
+		// runAsNonRoot does not allow an explicit root user
+		{`
+  steps:
+    - name: a
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 0`, []string{"spec.steps[0].securityContext.runAsUser run-as-root"}},
+		// the stepTemplate does not apply to sidecars
+		{`
+  stepTemplate:
+    securityContext:
+      runAsNonRoot: true
+  steps:
+    - name: a
+  sidecars:
+    - name: b`, []string{"spec.sidecars[0] implicit-root"}},
+	}
+	for _, tc := range tests {
+		content := []byte("kind: Task\nspec:" + tc.spec + "\n")
+		diagnostics, err := Check(content, diagnostic.NewIndex(content), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, d := range diagnostics {
+			if d.Rule == RunAsRoot || d.Rule == ImplicitRoot {
+				got = append(got, d.Path+" "+d.Rule)
+			}
+		}
+		if len(got) != len(tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.spec, tc.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.expected[i] {
+				t.Errorf("%s: expected %v, got %v", tc.spec, tc.expected, got)
+			}
+		}
+	}
+}
+
+func TestCheckImage(t *testing.T) {
+	tests := map[string]string{
+		"gcr.io/kaniko-project/executor:v0.13.0":                                         "",
+		"alpine@sha256:c19173c5ada610a5989151111163d28a67368362762534d8a8121ce95cf2bd5a": "",
+		"$(params.image)":         "",
+		"localhost:5000/app":      "has no tag or digest",
+		"localhost:5000/app:1.0":  "",
+		"docker.io/library/hello": "has no tag or digest",
+	}
+	for image, expected := range tests {
+		if problem := checkImage(image); problem != expected {
+			t.Errorf("checkImage(%q) = %q, expected %q", image, problem, expected)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte("rules:\n  image-reference: error\n  version-label: disable\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Rules[ImageReference] != Error || config.Rules[VersionLabel] != Disable || config.Rules[Privileged] != Error {
+		t.Errorf("unexpected config %v", config.Rules)
+	}
+	if _, err := ParseConfig([]byte("rules:\n  unknown: error\n")); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+	if _, err := ParseConfig([]byte("rules:\n  privileged: fatal\n")); err == nil {
+		t.Error("expected an error for an invalid level")
+	}
+}
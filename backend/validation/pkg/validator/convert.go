@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"github.com/ghodss/yaml"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// Supported apiVersions of Tekton resources
+const (
+	v1alpha1APIVersion = "tekton.dev/v1alpha1"
+	v1beta1APIVersion  = "tekton.dev/v1beta1"
+	v1APIVersion       = "tekton.dev/v1"
+
+	supportedAPIVersions = v1alpha1APIVersion + ", " + v1beta1APIVersion + ", " + v1APIVersion
+)
+
+// v1alpha1TaskSpec holds the fields of a v1alpha1 Task which were removed
+// in v1beta1
+type v1alpha1TaskSpec struct {
+	Spec struct {
+		Inputs *struct {
+			Params    []v1beta1.ParamSpec    `json:"params,omitempty"`
+			Resources []v1beta1.TaskResource `json:"resources,omitempty"`
+		} `json:"inputs,omitempty"`
+		Outputs *struct {
+			Resources []v1beta1.TaskResource `json:"resources,omitempty"`
+		} `json:"outputs,omitempty"`
+	} `json:"spec"`
+}
+
+// convertV1alpha1Task reads a v1alpha1 Task as a v1beta1 Task. Inputs and
+// outputs are moved to params and resources as done by Tekton when
+// v1alpha1 was still served
+func convertV1alpha1Task(b []byte) (*v1beta1.Task, error) {
+	var task v1beta1.Task
+	if err := yaml.Unmarshal(b, &task); err != nil {
+		return nil, err
+	}
+	var legacy v1alpha1TaskSpec
+	if err := yaml.Unmarshal(b, &legacy); err != nil {
+		return nil, err
+	}
+	if inputs := legacy.Spec.Inputs; inputs != nil {
+		task.Spec.Params = append(task.Spec.Params, inputs.Params...)
+		if len(inputs.Resources) > 0 {
+			if task.Spec.Resources == nil {
+				task.Spec.Resources = &v1beta1.TaskResources{}
+			}
+			task.Spec.Resources.Inputs = append(task.Spec.Resources.Inputs, inputs.Resources...)
+		}
+	}
+	if outputs := legacy.Spec.Outputs; outputs != nil && len(outputs.Resources) > 0 {
+		if task.Spec.Resources == nil {
+			task.Spec.Resources = &v1beta1.TaskResources{}
+		}
+		task.Spec.Resources.Outputs = append(task.Spec.Resources.Outputs, outputs.Resources...)
+	}
+	task.APIVersion = v1beta1APIVersion
+	return &task, nil
+}
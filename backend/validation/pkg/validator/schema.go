@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"context"
+
+	"github.com/ghodss/yaml"
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// getAPIVersion returns the apiVersion of the resource
+func getAPIVersion(b []byte) (string, error) {
+	var meta metav1.TypeMeta
+	if err := yaml.Unmarshal(b, &meta); err != nil {
+		return "", err
+	}
+	return meta.APIVersion, nil
+}
+
+func checkPipelineSchema(b []byte) error {
+	apiVersion, err := getAPIVersion(b)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	switch apiVersion {
+	case v1alpha1APIVersion, v1beta1APIVersion:
+		// v1alpha1 Pipelines have the same fields as v1beta1 Pipelines
+		var pipeline v1beta1.Pipeline
+		if err := yaml.Unmarshal(b, &pipeline); err != nil {
+			return err
+		}
+		pipeline.SetDefaults(ctx)
+		if err := pipeline.Validate(ctx); err != nil {
+			return err
+		}
+	case v1APIVersion:
+		var pipeline v1.Pipeline
+		if err := yaml.Unmarshal(b, &pipeline); err != nil {
+			return err
+		}
+		pipeline.SetDefaults(ctx)
+		if err := pipeline.Validate(ctx); err != nil {
+			return err
+		}
+	default:
+		return apis.ErrInvalidValue(apiVersion, "apiVersion", "supported apiVersions are "+supportedAPIVersions)
+	}
+	return nil
+}
+
+func checkTaskSchema(b []byte) error {
+	apiVersion, err := getAPIVersion(b)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	switch apiVersion {
+	case v1alpha1APIVersion, v1beta1APIVersion:
+		var task *v1beta1.Task
+		if apiVersion == v1alpha1APIVersion {
+			task, err = convertV1alpha1Task(b)
+		} else {
+			task = &v1beta1.Task{}
+			err = yaml.Unmarshal(b, task)
+		}
+		if err != nil {
+			return err
+		}
+		task.SetDefaults(ctx)
+		if err := task.Validate(ctx); err != nil {
+			return err
+		}
+	case v1APIVersion:
+		var task v1.Task
+		if err := yaml.Unmarshal(b, &task); err != nil {
+			return err
+		}
+		task.SetDefaults(ctx)
+		if err := task.Validate(ctx); err != nil {
+			return err
+		}
+	default:
+		return apis.ErrInvalidValue(apiVersion, "apiVersion", "supported apiVersions are "+supportedAPIVersions)
+	}
+	return nil
+}
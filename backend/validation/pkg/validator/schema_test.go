@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func readFile(t *testing.T, file string) []byte {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCheckTaskSchema(t *testing.T) {
+	for _, file := range []string{"../../test/valid_task.yaml", "../../test/valid_task_v1beta1.yaml", "../../test/valid_task_v1.yaml"} {
+		if err := checkTaskSchema(readFile(t, file)); err != nil {
+			t.Errorf("%s: unexpected error: %v", file, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"no steps":            "apiVersion: tekton.dev/v1\nkind: Task\nmetadata:\n  name: empty\nspec:\n  steps: []\n",
+		"v1alpha1 inputs":     "apiVersion: tekton.dev/v1alpha1\nkind: Task\nmetadata:\n  name: dup\nspec:\n  inputs:\n    params:\n    - name: a\n  params:\n  - name: a\n  steps:\n  - name: s\n    image: alpine\n",
+		"unsupported version": "apiVersion: tekton.dev/v2\nkind: Task\nmetadata:\n  name: future\n",
+	}
+	for name, content := range invalid {
+		if err := checkTaskSchema([]byte(content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCheckPipelineSchema(t *testing.T) {
+	if err := checkPipelineSchema(readFile(t, "../../resources/pipeline.yaml")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/diagnostic"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/lint"
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/policy"
+)
+
+// Types of resources which can be validated
+const (
+	Task     = "task"
+	Pipeline = "pipeline"
+)
+
+// Result is the outcome of validating a resource
+type Result struct {
+	Diagnostics diagnostic.List
+	// Score is the quality of the resource, see policy.Score
+	Score int
+}
+
+// Valid checks if the resource can be published
+func (r Result) Valid() bool {
+	return !r.Diagnostics.HasErrors()
+}
+
+// Validator runs the lint, schema and policy checks on resources. It only
+// works on the content in memory and is safe for concurrent use.
+type Validator struct {
+	lint   *lint.Config
+	policy *policy.Config
+}
+
+// New returns a Validator with the given configurations, the default
+// configuration is used for a nil one
+func New(lintConfig *lint.Config, policyConfig *policy.Config) *Validator {
+	if lintConfig == nil {
+		lintConfig = lint.DefaultConfig()
+	}
+	if policyConfig == nil {
+		policyConfig = policy.DefaultConfig()
+	}
+	return &Validator{lint: lintConfig, policy: policyConfig}
+}
+
+// FromFiles returns a Validator configured from a yamllint file and a
+// policy file, the default configuration is used for a missing file
+func FromFiles(lintFile, policyFile string) (*Validator, error) {
+	var lintConfig *lint.Config
+	if exists(lintFile) {
+		config, err := lint.LoadConfig(lintFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lint config: %s", err)
+		}
+		lintConfig = config
+	}
+	var policyConfig *policy.Config
+	if exists(policyFile) {
+		config, err := policy.LoadConfig(policyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy config: %s", err)
+		}
+		policyConfig = config
+	}
+	return New(lintConfig, policyConfig), nil
+}
+
+func exists(file string) bool {
+	if file == "" {
+		return false
+	}
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+// Validate checks the content of a resource of the given type. All the
+// problems are reported at once so that they can be fixed together.
+func (v *Validator) Validate(resourceType string, content []byte) Result {
+	index := diagnostic.NewIndex(content)
+	result := lint.Lint(content, v.lint)
+	diagnostics := diagnostic.FromLint(result, index)
+	score := 0
+	// the schema can not be checked if the content can not be parsed
+	if !hasSyntaxError(result) {
+		switch resourceType {
+		case Task:
+			diagnostics = append(diagnostics, diagnostic.FromSchema(checkTaskSchema(content), index)...)
+		case Pipeline:
+			diagnostics = append(diagnostics, diagnostic.FromSchema(checkPipelineSchema(content), index)...)
+		default:
+			diagnostics = append(diagnostics, diagnostic.Diagnostic{
+				Rule:     diagnostic.Schema,
+				Severity: diagnostic.Error,
+				Message:  fmt.Sprintf("unsupported resource type %q", resourceType),
+			})
+		}
+		// a resource which does not match the schema is already reported
+		if violations, err := policy.Check(content, index, v.policy); err == nil {
+			diagnostics = append(diagnostics, violations...)
+			score = policy.Score(violations, v.policy)
+		}
+	}
+	diagnostics.Sort()
+	return Result{Diagnostics: diagnostics, Score: score}
+}
+
+func hasSyntaxError(result lint.Result) bool {
+	for _, p := range result {
+		if p.Rule == lint.Syntax {
+			return true
+		}
+	}
+	return false
+}
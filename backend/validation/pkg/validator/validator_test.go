@@ -0,0 +1,38 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/tekton-hub/backend/validation/pkg/diagnostic"
+)
+
+func TestValidate(t *testing.T) {
+	v := New(nil, nil)
+
+	result := v.Validate(Task, readFile(t, "../../test/valid_task_v1.yaml"))
+	if !result.Valid() {
+		t.Errorf("unexpected errors:\n%s", result.Diagnostics)
+	}
+
+	invalid := "apiVersion: tekton.dev/v1\nkind: Task\nmetadata:\n  name: bad \nspec:\n  steps:\n    - name: s\n      image: alpine\n      securityContext:\n        privileged: true\n    - name: s\n      image: alpine:3.11\n"
+	result = v.Validate(Task, []byte(invalid))
+	if result.Valid() {
+		t.Fatal("expected errors")
+	}
+	rules := map[string]bool{}
+	for _, d := range result.Diagnostics {
+		if d.Severity == diagnostic.Error {
+			rules[d.Rule] = true
+		}
+	}
+	for _, rule := range []string{"trailing-spaces", diagnostic.Schema, "privileged"} {
+		if !rules[rule] {
+			t.Errorf("expected an error of rule %q, got:\n%s", rule, result.Diagnostics)
+		}
+	}
+
+	result = v.Validate(Task, []byte("a: [\n"))
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Rule != "syntax" {
+		t.Errorf("expected a syntax error only, got:\n%s", result.Diagnostics)
+	}
+}
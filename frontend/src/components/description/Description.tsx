@@ -31,7 +31,7 @@ const Description: React.FC<DescriptionProp> = (props: any) => {
 
   let markDown : string = '';
   if (props.Description != null) {
-    if (props.Description.match('noreadme')) {
+    if (props.Description === '') {
       markDown = props.userTaskDescription;
     } else {
       markDown = props.Description;
@@ -40,7 +40,7 @@ const Description: React.FC<DescriptionProp> = (props: any) => {
 
   let markDownYaml : string = '';
   if (props.Yaml != null) {
-    if (props.Yaml.match('noyaml')) {
+    if (props.Yaml === '') {
       markDownYaml = 'YAML file not found';
     } else {
       markDownYaml = props.Yaml;
@@ -82,6 +82,14 @@ const Rating: React.FC = (props:any) => {
     if (totalstar ===0 ) totalstar=totalstar+1;
   }
   let login: any = '';
+  // an error leaves the average as it was
+  const showAverage=(data:any)=>{
+    if (data['error']) {
+      console.log(data['error']['message']);
+      return;
+    }
+    setAvgRating(data['average'].toFixed(1));
+  };
   // sending rating information to backend
   const postData=(ratingData:any)=>{
     fetch(`${API_URL}/rating`, {
@@ -89,11 +97,11 @@ const Rating: React.FC = (props:any) => {
       headers: {
         'Accept': 'application/json',
         'Content-Type': 'application/json',
+        'Authorization': `Bearer ${localStorage.getItem('token')}`,
       },
       body: JSON.stringify(ratingData),
     }).then((res) => res.json())
-        .then((data) =>
-          setAvgRating(data['average'].toFixed(1)));
+        .then(showAverage);
   };
   const putData=(ratingData:any) =>{
     fetch(`${API_URL}/rating`, {
@@ -101,11 +109,11 @@ const Rating: React.FC = (props:any) => {
       headers: {
         'Accept': 'application/json',
         'Content-Type': 'application/json',
+        'Authorization': `Bearer ${localStorage.getItem('token')}`,
       },
       body: JSON.stringify(ratingData),
     }).then((res) => res.json())
-        .then((data) =>
-          setAvgRating(data['average'].toFixed(1)));
+        .then(showAverage);
   };
 
 
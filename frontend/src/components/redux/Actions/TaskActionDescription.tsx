@@ -9,7 +9,8 @@ export function fetchTaskDescription(id: any) {
         .then((response) => response.json())
         .then(() => {
           fetch(`${API_URL}/resource/readme/${id}`)
-              .then((response) => response.text())
+              // a missing file is dispatched as an empty string
+              .then((response) => response.ok ? response.text() : '')
               .then((TaskDescription) => dispatch({
                 type: FETCH_TASK_DESCRIPTION,
                 payload: TaskDescription,
@@ -20,7 +21,8 @@ export function fetchTaskDescription(id: any) {
         .then((response) => response.json())
         .then(() => {
           fetch(`${API_URL}/resource/yaml/${id}`)
-              .then((response) => response.text())
+              // a missing file is dispatched as an empty string
+              .then((response) => response.ok ? response.text() : '')
               .then((TaskYaml) => dispatch({
                 type: FETCH_TASK_YAML,
                 payload: TaskYaml,
@@ -30,9 +30,9 @@ const UploadTask: React.FC = () => {
   // alert message for task upload
   let sendStatus:any='';
   const alertMessage=(status :any) =>{
-    if (status['status'] === false) {
+    if (status['error']) {
       sendStatus = <Alert variant="danger"
-        isInline title={status['message']} />;
+        isInline title={status['error']['message']} />;
       setLoad('');
     } else {
       sendStatus = <Alert variant="success"
@@ -62,6 +62,7 @@ const UploadTask: React.FC = () => {
       headers: {
         'Accept': 'application/json',
         'Content-Type': 'application/json',
+        'Authorization': `Bearer ${localStorage.getItem('token')}`,
       },
     }).then((resp) => resp.json())
         .then((data)=>
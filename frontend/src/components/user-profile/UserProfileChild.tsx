@@ -30,6 +30,7 @@ const UserProfileChild: React.FC<TaskProp> = (props: any) => {
 
     return fetch(`${API_URL}/resource/${taskId}`, {
       method: 'DELETE',
+      headers: {Authorization: `Bearer ${localStorage.getItem('token')}`},
     })
         .then((response) => response.json())
         .then((data: any) => window.location.reload());